@@ -5,26 +5,84 @@ import (
 	"os"
 	"weather-cli/internal/cli"
 	"weather-cli/internal/config"
+	"weather-cli/internal/weather"
 )
 
 // run is a helper function to run the CLI application
 func run() error {
+	opts, args, err := cli.ParseGlobalOptions(os.Args)
+	if err != nil {
+		return &cli.UsageError{Err: err}
+	}
+	if opts.ConfigPath != "" {
+		config.SetConfigPath(opts.ConfigPath)
+	}
+
+	// Move a config left by older versions to the XDG config directory
+	from, to, err := config.MigrateLegacyConfig()
+	if err != nil {
+		return err
+	}
+	if from != "" {
+		fmt.Fprintf(os.Stderr, "Moved configuration from %s to %s\n", from, to)
+	}
+
+	// Walk a new user through the setup instead of writing a config without an API key
+	if cli.NeedsSetup(args) {
+		fmt.Fprintln(os.Stderr, "No configuration found, starting setup. To set up without prompts, run: weather init --non-interactive --api-key <key>")
+		if err := cli.NewCLI(nil).Run([]string{args[0], "init"}); err != nil {
+			return err
+		}
+	}
+
+	if !cli.NeedsConfig(args) {
+		return cli.NewCLI(nil).Run(args)
+	}
+
+	// Upgrade a config written by an older version to the current schema
+	upgrade, err := config.MigrateConfig()
+	if err != nil {
+		return fmt.Errorf("error upgrading configuration: %w", err)
+	}
+	if upgrade != nil {
+		fmt.Fprintf(os.Stderr, "Upgraded configuration from schema version %d to %d; the previous file is kept in %s\n", upgrade.From, upgrade.To, upgrade.Backup)
+		for _, change := range upgrade.Changes {
+			fmt.Fprintf(os.Stderr, "  %s\n", change)
+		}
+	}
+
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		return fmt.Errorf("error loading configuration: %w", err)
 	}
+	for _, problem := range cfg.Warnings() {
+		fmt.Fprintf(os.Stderr, "Warning: config %s\n", problem)
+	}
+	if err := config.UseProfile(cfg, opts.Profile); err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+	if err := config.ApplyOverrides(cfg, opts.Overrides); err != nil {
+		return fmt.Errorf("error loading configuration: %w", err)
+	}
+
+	// Share fetched forecasts between invocations through the on-disk cache
+	if cacheDir, err := config.GetCacheDir(); err == nil {
+		weather.DefaultWeatherService = weather.NewCachedWeatherService(&weather.RealWeatherService{}, weather.DefaultCacheTTL, cacheDir)
+	}
 
 	// Create and run CLI
 	weatherCLI := cli.NewCLI(cfg)
-	return weatherCLI.Run(os.Args)
+	return weatherCLI.Run(args)
 }
 
 // main is the entry point for the CLI application
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		fmt.Fprintln(os.Stderr, "Please check your config.json file and ensure all required fields are properly set.")
-		os.Exit(1)
+		if hint := cli.Hint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+		os.Exit(cli.ExitCode(err))
 	}
 }
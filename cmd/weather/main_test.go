@@ -2,11 +2,16 @@ package main
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"weather-cli/internal/config"
 )
 
 func TestRun(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+
 	testConfig := &config.Config{
 		Locations: []config.Location{
 			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
@@ -19,7 +24,8 @@ func TestRun(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to save test config: %v", err)
 	}
-	defer os.Remove("config.json")
+
+	otherFile := filepath.Join(t.TempDir(), "other.json")
 
 	tests := []struct {
 		name    string
@@ -29,6 +35,8 @@ func TestRun(t *testing.T) {
 		{"Help command", []string{"weather", "--help"}, false},
 		{"Invalid command", []string{"weather", "--invalid"}, true},
 		{"List locations", []string{"weather", "--list"}, false},
+		{"Config flag", []string{"weather", "--config", otherFile, "--unit", "F"}, false},
+		{"Config flag without path", []string{"weather", "--list", "--config"}, true},
 	}
 
 	for _, tt := range tests {
@@ -40,4 +48,33 @@ func TestRun(t *testing.T) {
 			}
 		})
 	}
+
+	// --config writes to the given file and leaves the default one untouched
+	config.SetConfigPath("")
+	if _, err := os.Stat(otherFile); err != nil {
+		t.Errorf("--config did not create %s: %v", otherFile, err)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil || cfg.TemperatureUnit != "C" {
+		t.Errorf("Config from %s was changed: %+v, %v", config.ConfigEnvVar, cfg, err)
+	}
+}
+
+func TestRunInvalidConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+	if err := os.WriteFile(configFile, []byte(`{"schema_version": 1, "forecast_interval": "daily"}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	os.Args = []string{"weather", "--list"}
+	if err := run(); err == nil || !strings.Contains(err.Error(), "$.forecast_interval") {
+		t.Errorf("run() error = %v, want the invalid forecast_interval", err)
+	}
+
+	// Help and the doctor work without loading the config
+	os.Args = []string{"weather", "--help"}
+	if err := run(); err != nil {
+		t.Errorf("run() with --help error = %v", err)
+	}
 }
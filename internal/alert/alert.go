@@ -0,0 +1,265 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/notify"
+	"weather-cli/internal/weather"
+)
+
+// DefaultWindow is how far ahead a rule without within or period looks
+const DefaultWindow = 24 * time.Hour
+
+// forecastStep is the length of the period each forecast entry covers
+const forecastStep = 3 * time.Hour
+
+// metric reads one value of a forecast entry and formats values of it
+type metric struct {
+	label  string
+	value  func(data *weather.WeatherData, i int, tempUnit string) float64
+	format func(v float64, tempUnit string) string
+}
+
+func formatTemp(v float64, unit string) string { return fmt.Sprintf("%.1f°%s", v, unit) }
+func formatPercent(v float64, _ string) string { return fmt.Sprintf("%.0f%%", v) }
+func formatMM(v float64, _ string) string      { return fmt.Sprintf("%.1f mm", v) }
+func formatSpeed(v float64, _ string) string   { return fmt.Sprintf("%.1f m/s", v) }
+
+// metrics holds the metrics in config.AlertMetrics. pop is a fraction like the
+// API reports it, but shown as a percentage.
+var metrics = map[string]metric{
+	"temp": {"temperature", func(d *weather.WeatherData, i int, unit string) float64 {
+		return weather.ConvertTemperature(d.List[i].Main.Temp, "C", unit)
+	}, formatTemp},
+	"feels_like": {"feels-like temperature", func(d *weather.WeatherData, i int, unit string) float64 {
+		return weather.ConvertTemperature(d.List[i].Main.FeelsLike, "C", unit)
+	}, formatTemp},
+	"pop": {"chance of precipitation", func(d *weather.WeatherData, i int, _ string) float64 {
+		return d.List[i].Pop
+	}, func(v float64, _ string) string { return fmt.Sprintf("%.0f%%", v*100) }},
+	"rain": {"rain in 3 hours", func(d *weather.WeatherData, i int, _ string) float64 {
+		return d.List[i].Rain.ThreeH
+	}, formatMM},
+	"snow": {"snow in 3 hours", func(d *weather.WeatherData, i int, _ string) float64 {
+		return d.List[i].Snow.ThreeH
+	}, formatMM},
+	"wind": {"wind speed", func(d *weather.WeatherData, i int, _ string) float64 {
+		return d.List[i].Wind.Speed
+	}, formatSpeed},
+	"gust": {"wind gusts", func(d *weather.WeatherData, i int, _ string) float64 {
+		return d.List[i].Wind.Gust
+	}, formatSpeed},
+	"humidity": {"humidity", func(d *weather.WeatherData, i int, _ string) float64 {
+		return float64(d.List[i].Main.Humidity)
+	}, formatPercent},
+	"clouds": {"cloud cover", func(d *weather.WeatherData, i int, _ string) float64 {
+		return float64(d.List[i].Clouds.All)
+	}, formatPercent},
+}
+
+// Match is a rule that the forecast meets
+type Match struct {
+	Rule     config.AlertRule
+	Location string
+	// Time is the start of the first forecast period that meets the rule, in the
+	// local time of the location
+	Time time.Time
+	// Value is the most extreme value in the checked window, the highest for a
+	// rule with above and the lowest for one with below
+	Value    float64
+	TempUnit string
+}
+
+// String describes the match, e.g. "wind gusts 17.2 m/s above 15.0 m/s at office from Mon 15:00"
+func (m *Match) String() string {
+	metric := metrics[m.Rule.Metric]
+	direction, threshold := "above", m.Rule.Above
+	if m.Rule.Below != nil {
+		direction, threshold = "below", m.Rule.Below
+	}
+	return fmt.Sprintf("%s %s %s %s at %s from %s", metric.label, metric.format(m.Value, m.TempUnit), direction,
+		metric.format(*threshold, m.TempUnit), m.Location, m.Time.Format("Mon 15:04"))
+}
+
+// Message is the notification sent for the match
+func (m *Match) Message() notify.Message {
+	return notify.Message{Title: "Weather alert: " + m.Rule.Name, Body: m.String()}
+}
+
+// Window returns the time span a rule checks, starting at now, in the local time
+// of the forecast's city
+func Window(rule config.AlertRule, data *weather.WeatherData, now time.Time) (time.Time, time.Time, error) {
+	local := now.In(time.FixedZone("", data.City.Timezone))
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	at := func(days, hour int) time.Time {
+		return midnight.AddDate(0, 0, days).Add(time.Duration(hour) * time.Hour)
+	}
+
+	switch rule.Period {
+	case "today":
+		return local, at(1, 0), nil
+	case "tonight":
+		// Before dawn, tonight is the rest of the current night
+		if local.Hour() < 6 {
+			return local, at(0, 6), nil
+		}
+		return later(local, at(0, 18)), at(1, 6), nil
+	case "tomorrow":
+		return at(1, 0), at(2, 0), nil
+	case "":
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown period '%s'", rule.Period)
+	}
+
+	window := DefaultWindow
+	if rule.Within != "" {
+		d, err := time.ParseDuration(rule.Within)
+		if err != nil || d <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid within '%s'", rule.Within)
+		}
+		window = d
+	}
+	return local, local.Add(window), nil
+}
+
+// later returns the later of two times
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// Evaluate checks a rule against the forecast at now, returning the match or nil
+// when the rule is not met. Temperatures are compared in tempUnit.
+func Evaluate(rule config.AlertRule, data *weather.WeatherData, tempUnit string, now time.Time) (*Match, error) {
+	metric, ok := metrics[rule.Metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric '%s'", rule.Metric)
+	}
+	if (rule.Above == nil) == (rule.Below == nil) {
+		return nil, fmt.Errorf("rule '%s' must set exactly one of above and below", rule.Name)
+	}
+	start, end, err := Window(rule, data, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var match *Match
+	for i, item := range data.List {
+		from := weather.LocalTime(data, item.Dt)
+		if !from.Before(end) || !from.Add(forecastStep).After(start) {
+			continue
+		}
+		v := metric.value(data, i, tempUnit)
+		met := rule.Above != nil && v > *rule.Above || rule.Below != nil && v < *rule.Below
+		if !met {
+			continue
+		}
+		if match == nil {
+			match = &Match{Rule: rule, Location: data.City.Name, Time: from, Value: v, TempUnit: tempUnit}
+		} else if rule.Above != nil {
+			match.Value = max(match.Value, v)
+		} else {
+			match.Value = min(match.Value, v)
+		}
+	}
+	return match, nil
+}
+
+// Result is the outcome of checking one rule
+type Result struct {
+	Rule  config.AlertRule
+	Match *Match
+	Err   error
+}
+
+// Check evaluates every alert rule in cfg, fetching the forecast for each
+// location once. Results are in the order of the rules.
+func Check(cfg *config.Config, now time.Time) []Result {
+	results := make([]Result, len(cfg.Alerts))
+	manager := location.NewManager(cfg)
+
+	// Rules are grouped by the location they resolve to
+	var locations []config.Location
+	index := make(map[string]int)
+	ruleLocation := make([]int, len(cfg.Alerts))
+	for i, rule := range cfg.Alerts {
+		results[i].Rule = rule
+		var loc *config.Location
+		var err error
+		if rule.Location == "" {
+			loc, err = manager.GetDefaultLocation()
+		} else {
+			loc, err = manager.GetLocation(rule.Location)
+		}
+		if err != nil {
+			results[i].Err = fmt.Errorf("failed to get location: %w", err)
+			ruleLocation[i] = -1
+			continue
+		}
+		k, ok := index[loc.Name]
+		if !ok {
+			k = len(locations)
+			index[loc.Name] = k
+			locations = append(locations, *loc)
+		}
+		ruleLocation[i] = k
+	}
+
+	forecasts := weather.FetchForecasts(cfg, locations, weather.DefaultFetchWorkers)
+	for i, rule := range cfg.Alerts {
+		k := ruleLocation[i]
+		if k < 0 {
+			continue
+		}
+		if err := forecasts[k].Err; err != nil {
+			results[i].Err = fmt.Errorf("failed to fetch weather data for %s: %w", locations[k].Name, err)
+			continue
+		}
+		results[i].Match, results[i].Err = Evaluate(rule, forecasts[k].Data, cfg.TemperatureUnit, now)
+		if results[i].Match != nil {
+			// The saved name reads better than the city the provider reports
+			results[i].Match.Location = locations[k].Name
+		}
+	}
+	return results
+}
+
+// Targets returns where matches of a rule are sent: the rule's own targets, else
+// the alert_notify targets, else standard output
+func Targets(cfg *config.Config, rule config.AlertRule) []string {
+	switch {
+	case len(rule.Notify) > 0:
+		return rule.Notify
+	case len(cfg.AlertNotify) > 0:
+		return cfg.AlertNotify
+	}
+	return []string{config.NotifyStdout}
+}
+
+// Notify sends a notification for every matched rule in results to the rule's
+// targets. It returns how many rules matched, and the errors of checking and
+// notifying joined together.
+func Notify(ctx context.Context, cfg *config.Config, results []Result) (int, error) {
+	var errs []error
+	matched := 0
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Rule.Name, result.Err))
+			continue
+		}
+		if result.Match == nil {
+			continue
+		}
+		matched++
+		if err := notify.Send(ctx, cfg, Targets(cfg, result.Rule), result.Match.Message()); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Rule.Name, err))
+		}
+	}
+	return matched, errors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package alert
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather"
+)
+
+// fixture is a forecast for Tokyo (UTC+9) in 3-hour steps from Mon 2024-07-01 09:00 to Tue 09:00
+const fixture = `{
+	"city": {"name": "Tokyo", "country": "JP", "timezone": 32400},
+	"list": [
+		{"dt": 1719792000, "main": {"temp": 20.0, "humidity": 60}, "pop": 0.1, "wind": {"speed": 3.0, "gust": 5.0}},
+		{"dt": 1719802800, "main": {"temp": 24.0, "humidity": 70}, "pop": 0.7, "wind": {"speed": 9.0, "gust": 16.5}},
+		{"dt": 1719813600, "main": {"temp": 25.0, "humidity": 90}, "pop": 0.9, "rain": {"3h": 2.0}, "wind": {"speed": 7.0, "gust": 12.0}},
+		{"dt": 1719824400, "main": {"temp": 18.0, "humidity": 80}, "pop": 0.3},
+		{"dt": 1719835200, "main": {"temp": 5.0}},
+		{"dt": 1719846000, "main": {"temp": -6.0}},
+		{"dt": 1719856800, "main": {"temp": -7.5}},
+		{"dt": 1719867600, "main": {"temp": -2.0}},
+		{"dt": 1719878400, "main": {"temp": 3.0}}
+	]
+}`
+
+// fixtureNow is Mon 2024-07-01 09:00 in Tokyo
+var fixtureNow = time.Unix(1719792000, 0)
+
+func parseFixture(t *testing.T) *weather.WeatherData {
+	t.Helper()
+	var data weather.WeatherData
+	if err := json.Unmarshal([]byte(fixture), &data); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	return &data
+}
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestEvaluate(t *testing.T) {
+	data := parseFixture(t)
+
+	tests := []struct {
+		name      string
+		rule      config.AlertRule
+		unit      string
+		wantMatch bool
+		wantTime  string
+		wantValue float64
+	}{
+		{"Rain in the next 6h", config.AlertRule{Metric: "pop", Above: floatPtr(0.6), Within: "6h"}, "C", true, "Mon 12:00", 0.7},
+		{"No rain in the next 3h", config.AlertRule{Metric: "pop", Above: floatPtr(0.6), Within: "3h"}, "C", false, "", 0},
+		{"Gusts in the next 24h", config.AlertRule{Metric: "gust", Above: floatPtr(15)}, "C", true, "Mon 12:00", 16.5},
+		{"Frost tonight", config.AlertRule{Metric: "temp", Below: floatPtr(-5), Period: "tonight"}, "C", true, "Tue 00:00", -7.5},
+		{"Heat today in Fahrenheit", config.AlertRule{Metric: "temp", Above: floatPtr(75), Period: "today"}, "F", true, "Mon 12:00", 77},
+		{"Above freezing tomorrow", config.AlertRule{Metric: "temp", Above: floatPtr(0), Period: "tomorrow"}, "C", true, "Tue 09:00", 3},
+		{"Humidity below threshold", config.AlertRule{Metric: "humidity", Below: floatPtr(50), Within: "12h"}, "C", false, "", 0},
+		{"Rain amount", config.AlertRule{Metric: "rain", Above: floatPtr(1)}, "C", true, "Mon 15:00", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := Evaluate(tt.rule, data, tt.unit, fixtureNow)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if (match != nil) != tt.wantMatch {
+				t.Fatalf("Evaluate() = %+v, want match %v", match, tt.wantMatch)
+			}
+			if match == nil {
+				return
+			}
+			if got := match.Time.Format("Mon 15:04"); got != tt.wantTime || match.Value != tt.wantValue {
+				t.Errorf("Evaluate() = %s, %v, want %s, %v", got, match.Value, tt.wantTime, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestEvaluateInvalidRule(t *testing.T) {
+	data := parseFixture(t)
+	for _, rule := range []config.AlertRule{
+		{Metric: "visibility", Above: floatPtr(1)},
+		{Metric: "temp"},
+		{Metric: "temp", Above: floatPtr(1), Period: "weekend"},
+		{Metric: "temp", Above: floatPtr(1), Within: "soon"},
+	} {
+		if _, err := Evaluate(rule, data, "C", fixtureNow); err == nil {
+			t.Errorf("Evaluate(%+v) should fail", rule)
+		}
+	}
+}
+
+func TestWindowTonightBeforeDawn(t *testing.T) {
+	data := parseFixture(t)
+	// Tue 03:30 in Tokyo
+	now := time.Unix(1719858600, 0)
+	start, end, err := Window(config.AlertRule{Period: "tonight"}, data, now)
+	if err != nil {
+		t.Fatalf("Window() error = %v", err)
+	}
+	if got := start.Format("Mon 15:04") + " - " + end.Format("Mon 15:04"); got != "Tue 03:30 - Tue 06:00" {
+		t.Errorf("Window() = %s", got)
+	}
+}
+
+func TestMatchString(t *testing.T) {
+	match, err := Evaluate(config.AlertRule{Name: "Gusts", Metric: "gust", Above: floatPtr(15)}, parseFixture(t), "C", fixtureNow)
+	if err != nil || match == nil {
+		t.Fatalf("Evaluate() = %v, %v", match, err)
+	}
+	match.Location = "office"
+	msg := match.Message()
+	if msg.Title != "Weather alert: Gusts" || msg.Body != "wind gusts 16.5 m/s above 15.0 m/s at office from Mon 12:00" {
+		t.Errorf("Message() = %+v", msg)
+	}
+
+	match, _ = Evaluate(config.AlertRule{Metric: "pop", Above: floatPtr(0.6), Within: "6h"}, parseFixture(t), "C", fixtureNow)
+	if got := match.String(); got != "chance of precipitation 70% above 60% at Tokyo from Mon 12:00" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestMetricsCoverConfig(t *testing.T) {
+	for _, name := range config.AlertMetrics {
+		if _, ok := metrics[name]; !ok {
+			t.Errorf("Metric %q accepted by the config is not evaluated", name)
+		}
+	}
+	if len(metrics) != len(config.AlertMetrics) {
+		t.Errorf("metrics has %d entries, config.AlertMetrics %d", len(metrics), len(config.AlertMetrics))
+	}
+}
+
+func TestTargets(t *testing.T) {
+	cfg := &config.Config{}
+	rule := config.AlertRule{Name: "Gusts"}
+	if got := Targets(cfg, rule); len(got) != 1 || got[0] != config.NotifyStdout {
+		t.Errorf("Targets() = %v, want stdout", got)
+	}
+	cfg.AlertNotify = []string{config.NotifyDesktop}
+	if got := Targets(cfg, rule); len(got) != 1 || got[0] != config.NotifyDesktop {
+		t.Errorf("Targets() = %v, want desktop", got)
+	}
+	rule.Notify = []string{"https://example.com/hook"}
+	if got := Targets(cfg, rule); len(got) != 1 || got[0] != "https://example.com/hook" {
+		t.Errorf("Targets() = %v, want the rule's webhook", got)
+	}
+}
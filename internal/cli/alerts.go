@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"weather-cli/internal/alert"
+	"weather-cli/internal/config"
+)
+
+// errNoAlerts is returned by the alerts commands when the config has no rules
+var errNoAlerts = errors.New("no alert rules are configured. Add them under \"alerts\" in the config file")
+
+// executeCheckAlerts evaluates the alert rules against the forecast and sends a
+// notification for each rule that is met
+func executeCheckAlerts(cfg *config.Config) error {
+	if len(cfg.Alerts) == 0 {
+		return errNoAlerts
+	}
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
+
+	matched, err := alert.Notify(context.Background(), cfg, alert.Check(cfg, time.Now()))
+	fmt.Printf("%d of %d alert rules matched.\n", matched, len(cfg.Alerts))
+	if err != nil {
+		return fmt.Errorf("some alert rules could not be checked or notified:\n%w", err)
+	}
+	return nil
+}
+
+// executeListAlerts prints the configured alert rules
+func executeListAlerts(cfg *config.Config) error {
+	if len(cfg.Alerts) == 0 {
+		return errNoAlerts
+	}
+	fmt.Println("Alert rules:")
+	for _, rule := range cfg.Alerts {
+		fmt.Printf("- %s: %s\n", rule.Name, describeRule(rule))
+		fmt.Printf("  notify: %s\n", strings.Join(redactTargets(alert.Targets(cfg, rule)), ", "))
+	}
+	return nil
+}
+
+// describeRule sums up a rule, e.g. "pop above 0.6 at office within 6h"
+func describeRule(rule config.AlertRule) string {
+	var sb strings.Builder
+	sb.WriteString(rule.Metric)
+	if rule.Above != nil {
+		fmt.Fprintf(&sb, " above %g", *rule.Above)
+	}
+	if rule.Below != nil {
+		fmt.Fprintf(&sb, " below %g", *rule.Below)
+	}
+	if rule.Location != "" {
+		fmt.Fprintf(&sb, " at %s", rule.Location)
+	} else {
+		sb.WriteString(" at the default location")
+	}
+	switch {
+	case rule.Period != "":
+		sb.WriteString(" " + rule.Period)
+	case rule.Within != "":
+		sb.WriteString(" within " + rule.Within)
+	default:
+		fmt.Fprintf(&sb, " within %s", strings.TrimSuffix(alert.DefaultWindow.String(), "0m0s"))
+	}
+	return sb.String()
+}
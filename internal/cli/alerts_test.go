@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather/weathertest"
+)
+
+func TestExecuteCheckAlerts(t *testing.T) {
+	weathertest.Serve(t)
+	var hook map[string]string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&hook)
+	}))
+	defer receiver.Close()
+
+	t.Setenv(config.ConfigEnvVar, filepath.Join(t.TempDir(), "config.json"))
+	gust, frost := 15.0, -5.0
+	cfg := &config.Config{
+		APIKey:          "test_api_key",
+		TemperatureUnit: "C",
+		Locations:       []config.Location{{Name: "office", Latitude: 35.6895, Longitude: 139.6917}},
+		DefaultLocation: "office",
+		Alerts: []config.AlertRule{
+			{Name: "Gusts", Metric: "gust", Above: &gust},
+			{Name: "Frost", Location: "office", Metric: "temp", Below: &frost, Within: "6h"},
+			{Name: "Gusts to chat", Location: "office", Metric: "gust", Above: &gust, Notify: []string{receiver.URL + "/hooks/secret"}},
+		},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := ExecuteCommand(&ParsedArgs{Command: CommandCheckAlerts}, cfg)
+	listErr := ExecuteCommand(&ParsedArgs{Command: CommandListAlerts}, cfg)
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil || listErr != nil {
+		t.Fatalf("ExecuteCommand returned an error: %v, %v", err, listErr)
+	}
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+	for _, expected := range []string{
+		"Weather alert: Gusts\nwind gusts 18.0 m/s above 15.0 m/s at office from ",
+		"2 of 3 alert rules matched.",
+		"- Frost: temp below -5 at office within 6h\n  notify: stdout\n",
+		"- Gusts: gust above 15 at the default location within 24h\n",
+		"  notify: " + receiver.URL + "\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "secret") {
+		t.Errorf("The webhook path was printed:\n%s", output)
+	}
+	if strings.Contains(output, "Weather alert: Gusts to chat") {
+		t.Errorf("A rule with its own targets was printed to stdout:\n%s", output)
+	}
+	if hook["title"] != "Weather alert: Gusts to chat" {
+		t.Errorf("Webhook payload = %v", hook)
+	}
+}
+
+func TestExecuteCheckAlertsWithoutRules(t *testing.T) {
+	if err := ExecuteCommand(&ParsedArgs{Command: CommandCheckAlerts}, &config.Config{}); err != errNoAlerts {
+		t.Errorf("ExecuteCommand() error = %v, want %v", err, errNoAlerts)
+	}
+}
@@ -2,23 +2,18 @@ package cli
 
 import (
 	"fmt"
-	"os"
 	"weather-cli/internal/config"
 	"weather-cli/internal/weather"
 )
 
 // CLI represents the command-line interface for the weather application
 type CLI struct {
-	cfg        *config.Config
-	loadConfig func() (*config.Config, error)
+	cfg *config.Config
 }
 
 // NewCLI creates a new CLI instance
 func NewCLI(cfg *config.Config) *CLI {
-	return &CLI{
-		cfg:        cfg,
-		loadConfig: config.LoadConfig,
-	}
+	return &CLI{cfg: cfg}
 }
 
 // Run executes the CLI application
@@ -26,7 +21,7 @@ func (c *CLI) Run(args []string) error {
 	// Parse command-line arguments
 	parsedArgs, err := ParseArgs(args)
 	if err != nil {
-		return fmt.Errorf("error parsing arguments: %w", err)
+		return &UsageError{Err: err}
 	}
 
 	// If help is requested, display help and exit
@@ -44,22 +39,14 @@ func (c *CLI) Run(args []string) error {
 	return nil
 }
 
-// RunWithErrorHandling runs the CLI application and handles errors
-func RunWithErrorHandling() {
-	// Create a new CLI instance
-	cli := NewCLI(nil)
-
-	// Load configuration
-	cfg, err := cli.loadConfig()
+// NeedsConfig reports whether the command in args works on the loaded configuration.
+// Help and commands that inspect the config file itself run without loading it, so
+// they still work when the file is invalid. Init loads the file itself, so that it
+// does not write a default config first.
+func NeedsConfig(args []string) bool {
+	parsed, err := ParseArgs(args)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
-		os.Exit(1)
-	}
-	cli.cfg = cfg
-
-	// Run CLI
-	if err := cli.Run(os.Args); err != nil {
-		weather.DisplayError(err)
-		os.Exit(1)
+		return true
 	}
+	return !parsed.ShowHelp && parsed.Command != CommandConfigDoctor && parsed.Command != CommandInit
 }
@@ -1,15 +1,11 @@
 package cli
 
 import (
-	"os"
 	"testing"
 	"weather-cli/internal/config"
 	"weather-cli/internal/weather"
 )
 
-// testLoadConfig is wrapped in a variable so it can be replaced in tests
-var testLoadConfig func() (*config.Config, error)
-
 // TestCLI_Run tests the Run method of the CLI
 func TestCLI_Run(t *testing.T) {
 	tests := []struct {
@@ -130,51 +126,29 @@ func TestCLI_Run(t *testing.T) {
 	}
 }
 
-func TestRunWithErrorHandling(t *testing.T) {
-	defer func() {
-		if r := recover(); r != nil {
-			t.Errorf("RunWithErrorHandling() panicked: %v", r)
-		}
-	}()
-
-	oldArgs := os.Args
-	defer func() { os.Args = oldArgs }()
-	os.Args = []string{"weather", "--help"}
-
-	testLoadConfig = func() (*config.Config, error) {
-		return &config.Config{}, nil
-	}
-
-	oldWeatherService := weather.DefaultWeatherService
-	weather.DefaultWeatherService = &MockWeatherService{
-		GetWeatherForecastFunc: func(cfg *config.Config, location config.Location) (*weather.WeatherData, error) {
-			return &weather.WeatherData{}, nil
-		},
+// TestNeedsConfig checks which commands run without loading the configuration
+func TestNeedsConfig(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"weather", "tokyo"}, true},
+		{[]string{"weather", "--list"}, true},
+		{[]string{"weather", "config", "show"}, true},
+		{[]string{"weather", "--help"}, false},
+		{[]string{"weather", "config", "doctor"}, false},
+		{[]string{"weather", "config", "unknown"}, true},
 	}
-	defer func() { weather.DefaultWeatherService = oldWeatherService }()
-
-	// Create a testRunWithErrorHandling function
-	testRunWithErrorHandling := func() {
-		cli := newTestCLI(nil)
-		cfg, err := cli.loadConfig()
-		if err != nil {
-			return // Use return instead of os.Exit(1) in tests 
-		}
-		cli.cfg = cfg
 
-		if err := cli.Run(os.Args); err != nil {
-			return // Use return instead of os.Exit(1) in tests
+	for _, tt := range tests {
+		if got := NeedsConfig(tt.args); got != tt.want {
+			t.Errorf("NeedsConfig(%v) = %v, want %v", tt.args, got, tt.want)
 		}
 	}
-
-	testRunWithErrorHandling()
 }
 
-// newTestCLI creates a new CLI instance with a mock loadConfig function
+// newTestCLI creates a new CLI instance for the given configuration
 func newTestCLI(cfg *config.Config) *CLI {
-	return &CLI{
-		cfg:        cfg,
-		loadConfig: testLoadConfig,
-	}
+	return &CLI{cfg: cfg}
 }
 
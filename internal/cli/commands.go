@@ -1,12 +1,29 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
 	"weather-cli/internal/config"
+	"weather-cli/internal/geo"
 	"weather-cli/internal/location"
+	"weather-cli/internal/notify"
+	"weather-cli/internal/secret"
+	"weather-cli/internal/tui"
 	"weather-cli/internal/weather"
 )
 
+// stdin is where commands read interactive input from
+var stdin io.Reader = os.Stdin
+
 // ExecuteCommand executes the appropriate command based on the parsed arguments
 func ExecuteCommand(args *ParsedArgs, cfg *config.Config) error {
 	switch args.Command {
@@ -21,12 +38,62 @@ func ExecuteCommand(args *ParsedArgs, cfg *config.Config) error {
 	case CommandSetInterval:
 		return executeSetInterval(args, cfg)
 	case CommandListLocations:
-		return executeListLocations(cfg)
+		return executeListLocations(args, cfg)
 	case CommandHelp:
+		// Plain "weather" shows the default location when one is set
+		if !args.ShowHelp && cfg.DefaultLocation != "" {
+			return executeGetWeather(&ParsedArgs{Command: CommandGetWeather}, cfg)
+		}
 		weather.DisplayHelp()
 		return nil
 	case CommandSetAPIKey:
 		return executeSetAPIKey(args, cfg)
+	case CommandTUI:
+		return executeTUI(cfg)
+	case CommandCompare:
+		return executeCompare(args, cfg)
+	case CommandSetDefaultLocation:
+		return executeSetDefaultLocation(args, cfg)
+	case CommandAddAliases, CommandRemoveAliases, CommandAddTags, CommandRemoveTags:
+		return executeEditLabels(args, cfg)
+	case CommandRenameLocation, CommandEditLocation:
+		return executeEditLocation(args, cfg)
+	case CommandExportLocations:
+		return executeExportLocations(args, cfg)
+	case CommandImportLocations:
+		return executeImportLocations(args, cfg)
+	case CommandNearestLocations:
+		return executeNearestLocations(args, cfg)
+	case CommandLocationDistance:
+		return executeLocationDistance(args, cfg)
+	case CommandShowConfig:
+		return executeShowConfig(args, cfg)
+	case CommandSetAPIKeySource:
+		return executeSetAPIKeySource(args, cfg)
+	case CommandConfigDoctor:
+		return executeConfigDoctor()
+	case CommandConvertConfig:
+		return executeConvertConfig(args)
+	case CommandListProfiles:
+		return executeListProfiles(cfg)
+	case CommandUseProfile:
+		return executeUseProfile(args, cfg)
+	case CommandCreateProfile:
+		return executeCreateProfile(args, cfg)
+	case CommandDeleteProfile:
+		return executeDeleteProfile(args, cfg)
+	case CommandInit:
+		return executeInit(args)
+	case CommandCheckAlerts:
+		return executeCheckAlerts(cfg)
+	case CommandListAlerts:
+		return executeListAlerts(cfg)
+	case CommandNotify:
+		return executeNotify(args, cfg)
+	case CommandDaemon:
+		return executeDaemon(args, cfg)
+	case CommandServe:
+		return executeServe(args, cfg)
 	default:
 		return fmt.Errorf("unknown command")
 	}
@@ -34,28 +101,150 @@ func ExecuteCommand(args *ParsedArgs, cfg *config.Config) error {
 
 // executeGetWeather fetches and displays weather data for a given location
 func executeGetWeather(args *ParsedArgs, cfg *config.Config) error {
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
 	locationManager := location.NewManager(cfg)
-	loc, err := locationManager.GetLocation(args.Location)
+
+	if args.All || args.Group != "" {
+		locations := locationManager.ListLocations()
+		if args.Group != "" {
+			locations = locationManager.LocationsByTag(args.Group)
+			if len(locations) == 0 {
+				return fmt.Errorf("no saved locations in group '%s'", args.Group)
+			}
+		}
+		names := make([]string, 0, len(locations))
+		for _, loc := range locations {
+			names = append(names, loc.Name)
+		}
+		return executeGetWeatherMulti(names, cfg)
+	}
+	if len(args.Locations) > 1 {
+		if _, err := locationManager.GetLocation(args.Location); err != nil {
+			if args.Watch > 0 {
+				return &UsageError{Err: errors.New("--watch supports a single location")}
+			}
+			return executeGetWeatherMulti(args.Locations, cfg)
+		}
+	}
+
+	loc, err := resolveLocation(args, locationManager)
 	if err != nil {
 		return fmt.Errorf("failed to get location: %w", err)
 	}
 
+	if args.Watch > 0 {
+		return executeWatchWeather(args, cfg, *loc)
+	}
+
 	weatherData, err := weather.GetWeatherForecast(cfg, *loc)
 	if err != nil {
 		return fmt.Errorf("failed to fetch weather data: %w", err)
 	}
 
 	weather.DisplayWeather(weatherData, cfg)
+	if args.Coordinates {
+		if nearest := locationManager.NearestLocation(loc.Latitude, loc.Longitude); nearest != nil {
+			fmt.Printf("Nearest saved location: %s (%s)\n", nearest.Location.Name, describeNearby(*nearest))
+		}
+	}
+	return nil
+}
+
+// resolveLocation finds the single location a get weather command refers to:
+// the current position with --here, the default location when none is named,
+// raw coordinates, or a saved location
+func resolveLocation(args *ParsedArgs, locationManager *location.Manager) (*config.Location, error) {
+	switch {
+	case args.Coordinates:
+		return &config.Location{
+			Name:      fmt.Sprintf("%.4f, %.4f", args.Latitude, args.Longitude),
+			Latitude:  args.Latitude,
+			Longitude: args.Longitude,
+		}, nil
+	case args.Here:
+		return location.Here()
+	case args.Location == "":
+		loc, err := locationManager.GetDefaultLocation()
+		if err != nil {
+			return nil, fmt.Errorf("location is required for getting weather (or set one with: weather loc default <name>): %w", err)
+		}
+		return loc, nil
+	default:
+		return locationManager.GetLocation(args.Location)
+	}
+}
+
+// executeGetWeatherMulti fetches several locations concurrently and displays them in input order.
+// A location that cannot be found or fetched is reported without stopping the others.
+func executeGetWeatherMulti(names []string, cfg *config.Config) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no saved locations")
+	}
+
+	locationManager := location.NewManager(cfg)
+	lookupErrs := make([]error, len(names))
+	var locations []config.Location
+	for i, name := range names {
+		loc, err := locationManager.GetLocation(name)
+		if err != nil {
+			lookupErrs[i] = fmt.Errorf("failed to get location: %w", err)
+			continue
+		}
+		locations = append(locations, *loc)
+	}
+
+	results := weather.FetchForecasts(cfg, locations, weather.DefaultFetchWorkers)
+
+	failed := 0
+	for i, name := range names {
+		if i > 0 {
+			fmt.Println(strings.Repeat("=", 40))
+		}
+
+		err := lookupErrs[i]
+		if err == nil {
+			result := results[0]
+			results = results[1:]
+			if result.Err != nil {
+				err = fmt.Errorf("failed to fetch weather data: %w", result.Err)
+			} else {
+				weather.DisplayWeather(result.Data, cfg)
+			}
+		}
+		if err != nil {
+			failed++
+			fmt.Printf("%s: Error: %v\n", name, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to get weather for %d of %d locations", failed, len(names))
+	}
 	return nil
 }
 
+// executeWatchWeather keeps redrawing the forecast until interrupted with Ctrl-C
+func executeWatchWeather(args *ParsedArgs, cfg *config.Config, loc config.Location) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return weather.WatchWeather(ctx, cfg, loc, args.Watch)
+}
+
 // executeAddLocation adds a new location to the configuration
 func executeAddLocation(args *ParsedArgs, cfg *config.Config) error {
 	locationManager := location.NewManager(cfg)
+	// Look for neighbours before the new location is among them
+	nearby := locationManager.NearbyLocations(args.Latitude, args.Longitude, location.NearbyRadiusKm)
 	if err := locationManager.AddLocation(args.Name, args.Latitude, args.Longitude); err != nil {
 		return fmt.Errorf("failed to add location: %w", err)
 	}
 	fmt.Printf("Location '%s' added successfully.\n", args.Name)
+	for _, n := range nearby {
+		fmt.Printf("Warning: '%s' is only %.0f m away from the new location.\n", n.Location.Name, n.DistanceKm*1000)
+	}
 	return nil
 }
 
@@ -71,8 +260,11 @@ func executeRemoveLocation(args *ParsedArgs, cfg *config.Config) error {
 
 // executeSetUnit sets the temperature unit in the configuration
 func executeSetUnit(args *ParsedArgs, cfg *config.Config) error {
-	cfg.SetTemperatureUnit(args.Unit)
-	if err := config.SaveConfig(cfg); err != nil {
+	err := config.Update(cfg, func() error {
+		cfg.SetTemperatureUnit(args.Unit)
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 	fmt.Printf("Temperature unit set to %s.\n", args.Unit)
@@ -81,28 +273,563 @@ func executeSetUnit(args *ParsedArgs, cfg *config.Config) error {
 
 // executeSetInterval sets the forecast interval in the configuration
 func executeSetInterval(args *ParsedArgs, cfg *config.Config) error {
-	cfg.SetForecastInterval(args.Interval)
-	if err := config.SaveConfig(cfg); err != nil {
+	err := config.Update(cfg, func() error {
+		cfg.SetForecastInterval(args.Interval)
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 	fmt.Printf("Forecast interval set to %d hours.\n", args.Interval)
 	return nil
 }
 
-// executeListLocations displays the list of saved locations
-func executeListLocations(cfg *config.Config) error {
+// executeListLocations displays the list of saved locations, optionally only those with a tag
+func executeListLocations(args *ParsedArgs, cfg *config.Config) error {
 	locationManager := location.NewManager(cfg)
 	locations := locationManager.ListLocations()
+	if args.Tag != "" {
+		locations = locationManager.LocationsByTag(args.Tag)
+	}
 	weather.DisplayLocationList(locations)
 	return nil
 }
 
 // executeSetAPIKey sets the API key in the configuration
 func executeSetAPIKey(args *ParsedArgs, cfg *config.Config) error {
-	cfg.SetAPIKey(args.APIKey)
-	if err := config.SaveConfig(cfg); err != nil {
+	err := config.Update(cfg, func() error {
+		cfg.SetAPIKey(args.APIKey)
+		cfg.SetAPIKeySource("", "", false)
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 	fmt.Println("API key has been set successfully.")
+	printPlaintextWarning()
+	return nil
+}
+
+// executeSetAPIKeySource stores the API key in the keyring, or points the config at a
+// command or file that provides it, removing any plaintext key from the config file
+func executeSetAPIKeySource(args *ParsedArgs, cfg *config.Config) error {
+	var message, command, file string
+	var keyring bool
+	switch args.SecretSource {
+	case "keyring":
+		key := args.APIKey
+		if key == "" {
+			fmt.Print("API key: ")
+			line, err := bufio.NewReader(stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("failed to read API key: %w", err)
+			}
+			key = strings.TrimSpace(line)
+		}
+		if key == "" {
+			return fmt.Errorf("API key must not be empty")
+		}
+		if err := secret.DefaultKeyring.Set(config.KeyringAccount(cfg), key); err != nil {
+			return fmt.Errorf("failed to store API key in keyring: %w", err)
+		}
+		keyring = true
+		message = "API key stored in the system keyring."
+	case "cmd":
+		// Run the command once so a typo is reported now rather than on the next forecast
+		if _, err := secret.RunCommand(args.SecretValue); err != nil {
+			return fmt.Errorf("failed to read API key from command: %w", err)
+		}
+		command = args.SecretValue
+		message = fmt.Sprintf("API key will be read from the command: %s", args.SecretValue)
+	case "file":
+		if _, err := secret.ReadFile(args.SecretValue); err != nil {
+			return fmt.Errorf("failed to read API key from file: %w", err)
+		}
+		file = args.SecretValue
+		message = fmt.Sprintf("API key will be read from the file: %s", args.SecretValue)
+	default:
+		return fmt.Errorf("unknown API key source '%s'", args.SecretSource)
+	}
+
+	err := config.Update(cfg, func() error {
+		cfg.SetAPIKeySource(command, file, keyring)
+		cfg.SetAPIKey("")
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+	fmt.Println(message)
+	return nil
+}
+
+// executeConfigDoctor checks the config file and reports every problem with its JSON path.
+// It reads the file itself, so it also works when the config cannot be loaded.
+func executeConfigDoctor() error {
+	diagnosis, err := config.Diagnose()
+	if err != nil {
+		return fmt.Errorf("failed to check configuration: %w", err)
+	}
+	fmt.Printf("Config file: %s\n", diagnosis.Path)
+	if !diagnosis.Exists {
+		fmt.Println("The config file does not exist yet; defaults are used until a setting is saved.")
+		return nil
+	}
+
+	// A file that could not be decoded has no known schema version
+	if len(diagnosis.Migrations) > 0 || diagnosis.SchemaVersion == config.SchemaVersion {
+		fmt.Printf("Schema version: %d", diagnosis.SchemaVersion)
+		if len(diagnosis.Migrations) == 0 {
+			fmt.Println(" (current)")
+		} else {
+			fmt.Printf(" (upgraded to %d on the next run, keeping a backup)\n", config.SchemaVersion)
+			for _, m := range diagnosis.Migrations {
+				fmt.Printf("  %s\n", m)
+			}
+			for _, change := range diagnosis.Changes {
+				fmt.Printf("    %s\n", change)
+			}
+		}
+	}
+
+	var problems, warnings []config.Problem
+	for _, p := range diagnosis.Problems {
+		if p.Warning {
+			warnings = append(warnings, p)
+		} else {
+			problems = append(problems, p)
+		}
+	}
+	if len(warnings) > 0 {
+		fmt.Println("Warnings:")
+		for _, p := range warnings {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+	if len(problems) > 0 {
+		fmt.Println("Problems:")
+		for _, p := range problems {
+			fmt.Printf("  %s\n", p)
+		}
+		return fmt.Errorf("found %d problem(s) in %s: %w", len(problems), diagnosis.Path, config.ErrInvalidConfig)
+	}
+
+	// The file is valid; check that the settings it points at can be resolved
+	cfg, err := config.LoadConfig()
+	if err == nil {
+		err = config.UseProfile(cfg, "")
+	}
+	if err == nil {
+		err = config.ApplyOverrides(cfg, nil)
+	}
+	if err == nil {
+		err = config.ResolveAPIKey(cfg)
+	}
+	switch {
+	case err != nil:
+		fmt.Printf("Warning: %v\n", err)
+	case cfg.APIKey == "":
+		fmt.Println("Warning: no API key is configured. Set one with: weather config api-key --keyring")
+	}
+	if len(warnings) > 0 {
+		fmt.Println("No problems found that stop the configuration from loading.")
+	} else {
+		fmt.Println("No problems found.")
+	}
+	return nil
+}
+
+// executeConvertConfig rewrites the config file in another format
+func executeConvertConfig(args *ParsedArgs) error {
+	from, to, err := config.ConvertConfig(args.Format)
+	if err != nil {
+		return fmt.Errorf("failed to convert configuration: %w", err)
+	}
+	fmt.Printf("Converted %s to %s. The previous file is kept as %s%s.\n", from, to, from, config.BackupSuffix)
+	if config.ConfigPathOverridden() {
+		fmt.Printf("Point --config or %s at %s to keep using it.\n", config.ConfigEnvVar, to)
+	}
+	return nil
+}
+
+// executeListProfiles lists the profiles, marking the one in use
+func executeListProfiles(cfg *config.Config) error {
+	active := config.ActiveProfile(cfg)
+	if active == "" {
+		active = config.DefaultProfile
+	}
+	for _, name := range append([]string{config.DefaultProfile}, config.ProfileNames(cfg)...) {
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		line := fmt.Sprintf("%s %s", marker, name)
+		if base := cfg.Profiles[name].Base; base != "" {
+			line += fmt.Sprintf(" (inherits from %s)", base)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// executeUseProfile sets the profile used when none is given with --profile
+func executeUseProfile(args *ParsedArgs, cfg *config.Config) error {
+	err := config.Update(cfg, func() error {
+		return cfg.SetCurrentProfile(args.Name)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to switch profile: %w", err)
+	}
+	fmt.Printf("Now using profile '%s'.\n", args.Name)
+	return nil
+}
+
+// executeCreateProfile adds a profile that inherits every setting until it is changed
+func executeCreateProfile(args *ParsedArgs, cfg *config.Config) error {
+	err := config.Update(cfg, func() error {
+		return cfg.CreateProfile(args.Name, args.Base)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create profile: %w", err)
+	}
+	base := args.Base
+	if base == "" {
+		base = config.DefaultProfile
+	}
+	fmt.Printf("Profile '%s' created, inheriting from '%s'.\n", args.Name, base)
+	fmt.Printf("Use it with --profile %s or make it the default with: weather config profile use %s\n", args.Name, args.Name)
+	return nil
+}
+
+// executeDeleteProfile removes a profile and its settings
+func executeDeleteProfile(args *ParsedArgs, cfg *config.Config) error {
+	err := config.Update(cfg, func() error {
+		return cfg.DeleteProfile(args.Name)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+	fmt.Printf("Profile '%s' deleted.\n", args.Name)
+	return nil
+}
+
+// printPlaintextWarning warns that the API key is readable by anyone who can read the config file
+func printPlaintextWarning() {
+	path, _ := config.ConfigPath()
+	fmt.Printf("Warning: the API key is stored in plaintext in %s.\n", path)
+	fmt.Println("Use 'weather config api-key --keyring', --cmd or --file to keep it out of the config file.")
+}
+
+// executeTUI runs the full-screen dashboard until the user quits
+func executeTUI(cfg *config.Config) error {
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
+	term, err := tui.NewTerminal()
+	if err != nil {
+		return fmt.Errorf("failed to start dashboard: %w", err)
+	}
+	defer term.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return tui.NewDashboard(cfg, term, tui.DefaultRefreshInterval).Run(ctx)
+}
+
+// executeCompare displays the forecasts of several locations side by side
+func executeCompare(args *ParsedArgs, cfg *config.Config) error {
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
+	locationManager := location.NewManager(cfg)
+	locations := make([]config.Location, 0, len(args.Locations))
+	failed := 0
+	for _, name := range args.Locations {
+		loc, err := locationManager.GetLocation(name)
+		if err != nil {
+			failed++
+			fmt.Printf("%s: Error: failed to get location: %v\n", name, err)
+			continue
+		}
+		locations = append(locations, *loc)
+	}
+
+	var names []string
+	var forecasts []*weather.WeatherData
+	for _, result := range weather.FetchForecasts(cfg, locations, weather.DefaultFetchWorkers) {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("%s: Error: failed to fetch weather data: %v\n", result.Location.Name, result.Err)
+			continue
+		}
+		names = append(names, result.Location.Name)
+		forecasts = append(forecasts, result.Data)
+	}
+	if len(forecasts) == 0 {
+		return fmt.Errorf("failed to get weather for every location")
+	}
+
+	weather.DisplayComparison(names, forecasts, cfg)
+	if failed > 0 {
+		return fmt.Errorf("failed to get weather for %d of %d locations", failed, len(args.Locations))
+	}
+	return nil
+}
+
+// executeSetDefaultLocation sets the location shown by plain "weather"
+func executeSetDefaultLocation(args *ParsedArgs, cfg *config.Config) error {
+	locationManager := location.NewManager(cfg)
+	if err := locationManager.SetDefaultLocation(args.Name); err != nil {
+		return fmt.Errorf("failed to set default location: %w", err)
+	}
+	fmt.Printf("Default location set to '%s'.\n", cfg.DefaultLocation)
+	return nil
+}
+
+// executeEditLabels adds or removes aliases or tags of a saved location
+func executeEditLabels(args *ParsedArgs, cfg *config.Config) error {
+	locationManager := location.NewManager(cfg)
+
+	var err error
+	var message string
+	switch args.Command {
+	case CommandAddAliases:
+		err = locationManager.AddAliases(args.Name, args.Values...)
+		message = "Aliases added to"
+	case CommandRemoveAliases:
+		err = locationManager.RemoveAliases(args.Name, args.Values...)
+		message = "Aliases removed from"
+	case CommandAddTags:
+		err = locationManager.AddTags(args.Name, args.Values...)
+		message = "Tags added to"
+	case CommandRemoveTags:
+		err = locationManager.RemoveTags(args.Name, args.Values...)
+		message = "Tags removed from"
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update location: %w", err)
+	}
+
+	fmt.Printf("%s location '%s': %s\n", message, args.Name, strings.Join(args.Values, ", "))
+	return nil
+}
+
+// executeEditLocation renames or edits a saved location and reports what changed
+func executeEditLocation(args *ParsedArgs, cfg *config.Config) error {
+	locationManager := location.NewManager(cfg)
+
+	var changes []location.Change
+	var err error
+	if args.Command == CommandRenameLocation {
+		changes, err = locationManager.RenameLocation(args.Name, args.NewName)
+	} else {
+		changes, err = locationManager.EditLocation(args.Name, args.Edit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update location: %w", err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("Location '%s' is unchanged.\n", args.Name)
+		return nil
+	}
+	fmt.Printf("Location '%s' updated:\n", args.Name)
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+	return nil
+}
+
+// executeExportLocations writes the saved locations to a file or standard output
+func executeExportLocations(args *ParsedArgs, cfg *config.Config) error {
+	locations := location.NewManager(cfg).ListLocations()
+	if args.Path == "" {
+		return location.ExportLocations(os.Stdout, locations, args.Format)
+	}
+
+	file, err := os.Create(args.Path)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := location.ExportLocations(file, locations, args.Format); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to export locations: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	fmt.Printf("Exported %d locations to %s.\n", len(locations), args.Path)
+	return nil
+}
+
+// executeImportLocations reads locations from a file and merges them into the saved ones
+func executeImportLocations(args *ParsedArgs, cfg *config.Config) error {
+	file, err := os.Open(args.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	locations, invalid, err := location.ParseLocations(file, args.Format)
+	if err != nil {
+		return fmt.Errorf("failed to import locations: %w", err)
+	}
+
+	report, err := location.NewManager(cfg).ImportLocations(locations, args.Import)
+	if err != nil {
+		return fmt.Errorf("failed to import locations: %w", err)
+	}
+	report.Invalid = append(invalid, report.Invalid...)
+
+	if args.Import.DryRun {
+		fmt.Println("Dry run: no changes were saved.")
+	}
+	printReportSection("Added", report.Added)
+	printReportSection("Overwritten", report.Overwritten)
+	renamed := make([]string, len(report.Renamed))
+	for i, change := range report.Renamed {
+		renamed[i] = fmt.Sprintf("%s -> %s", change.Old, change.New)
+	}
+	printReportSection("Renamed", renamed)
+	printReportSection("Skipped", report.Conflicts)
+	printReportSection("Invalid", report.Invalid)
+	fmt.Printf("Imported %d of %d locations.\n", len(report.Added)+len(report.Overwritten), len(locations)+len(invalid))
+	return nil
+}
+
+// printReportSection prints a titled list, or nothing when the list is empty
+func printReportSection(title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d):\n", title, len(items))
+	for _, item := range items {
+		fmt.Printf("  %s\n", item)
+	}
+}
+
+// executeNearestLocations lists the saved locations closest to a point
+func executeNearestLocations(args *ParsedArgs, cfg *config.Config) error {
+	ranked := location.NewManager(cfg).NearestLocations(args.Latitude, args.Longitude, args.Limit)
+	if len(ranked) == 0 {
+		return fmt.Errorf("no saved locations")
+	}
+
+	width := 0
+	for _, n := range ranked {
+		width = max(width, len([]rune(n.Location.Name)))
+	}
+
+	fmt.Printf("Saved locations nearest to %.4f, %.4f:\n", args.Latitude, args.Longitude)
+	for i, n := range ranked {
+		padding := strings.Repeat(" ", width-len([]rune(n.Location.Name)))
+		fmt.Printf("%2d. %s%s  %s\n", i+1, n.Location.Name, padding, describeNearby(n))
+	}
 	return nil
 }
+
+// executeLocationDistance prints the great-circle distance and heading between two saved locations
+func executeLocationDistance(args *ParsedArgs, cfg *config.Config) error {
+	locationManager := location.NewManager(cfg)
+	from, err := locationManager.GetLocation(args.Locations[0])
+	if err != nil {
+		return fmt.Errorf("failed to get location '%s': %w", args.Locations[0], err)
+	}
+	to, err := locationManager.GetLocation(args.Locations[1])
+	if err != nil {
+		return fmt.Errorf("failed to get location '%s': %w", args.Locations[1], err)
+	}
+
+	distance := geo.Distance(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	bearing := geo.Bearing(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+	fmt.Printf("%s -> %s: %s, heading %s (%.0f°)\n", from.Name, to.Name, geo.FormatDistance(distance), geo.Compass(bearing), bearing)
+	return nil
+}
+
+// describeNearby formats the distance and direction of a saved location from a point
+func describeNearby(n location.NearbyLocation) string {
+	return fmt.Sprintf("%s %s", geo.FormatDistance(n.DistanceKm), geo.Compass(n.Bearing))
+}
+
+// executeShowConfig prints the config file, or with --effective every resolved setting
+// and the layer it came from
+func executeShowConfig(args *ParsedArgs, cfg *config.Config) error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return fmt.Errorf("failed to resolve config file: %w", err)
+	}
+	fmt.Printf("Config file: %s\n", path)
+
+	if !args.Effective {
+		stored := *config.FileView(cfg)
+		stored.APIKey = maskSecret(stored.APIKey)
+		stored.Profiles = maps.Clone(stored.Profiles)
+		for name, profile := range stored.Profiles {
+			profile.APIKey = maskSecret(profile.APIKey)
+			stored.Profiles[name] = profile
+		}
+		stored.Notifiers = maps.Clone(stored.Notifiers)
+		for name, notifier := range stored.Notifiers {
+			notifier.Password = maskSecret(notifier.Password)
+			notifier.URL = notify.RedactTarget(notifier.URL)
+			stored.Notifiers[name] = notifier
+		}
+		// Webhook URLs carry their secret in the path
+		stored.AlertNotify = redactTargets(stored.AlertNotify)
+		stored.Alerts = slices.Clone(stored.Alerts)
+		for i := range stored.Alerts {
+			stored.Alerts[i].Notify = redactTargets(stored.Alerts[i].Notify)
+		}
+		stored.Jobs = slices.Clone(stored.Jobs)
+		for i := range stored.Jobs {
+			stored.Jobs[i].Notify = redactTargets(stored.Jobs[i].Notify)
+		}
+		data, err := config.Marshal(&stored, config.FormatFromPath(path))
+		if err != nil {
+			return fmt.Errorf("failed to format configuration: %w", err)
+		}
+		fmt.Println(strings.TrimSuffix(string(data), "\n"))
+		return nil
+	}
+
+	if profile := config.ActiveProfile(cfg); profile != "" {
+		fmt.Printf("Profile: %s\n", profile)
+	}
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	settings := config.EffectiveSettings(cfg)
+	width := 0
+	for _, s := range settings {
+		width = max(width, len(s.Key))
+	}
+	for _, s := range settings {
+		value := s.Value
+		if s.Key == "api_key" {
+			value = maskSecret(value)
+		}
+		if value == "" {
+			value = "(none)"
+		}
+		layer := s.Layer
+		if s.Source != "" {
+			layer += ": " + s.Source
+		}
+		fmt.Printf("%-*s  %-20s  [%s]\n", width, s.Key, value, layer)
+	}
+	fmt.Printf("%-*s  %d saved\n", width, "locations", len(cfg.Locations))
+	if config.PlaintextAPIKey(cfg) {
+		printPlaintextWarning()
+	}
+	return nil
+}
+
+// maskSecret hides all but the last four characters of a secret
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
@@ -2,10 +2,17 @@ package cli
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/secret"
+	"weather-cli/internal/secret/secrettest"
 	"weather-cli/internal/weather"
 )
 
@@ -260,7 +267,7 @@ func TestExecuteListLocations(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	err := executeListLocations(cfg)
+	err := executeListLocations(&ParsedArgs{Command: CommandListLocations}, cfg)
 
 	// Restore standard output
 	w.Close()
@@ -543,3 +550,747 @@ func TestExecuteCommand(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteGetWeatherMulti(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
+			{Name: "New York", Latitude: 40.7128, Longitude: -74.0060},
+			{Name: "London", Latitude: 51.5074, Longitude: -0.1278},
+		},
+		TemperatureUnit:  "C",
+		ForecastInterval: 1,
+	}
+
+	originalService := weather.DefaultWeatherService
+	weather.DefaultWeatherService = &MockWeatherService{
+		GetWeatherForecastFunc: func(cfg *config.Config, location config.Location) (*weather.WeatherData, error) {
+			if location.Name == "London" {
+				return nil, errors.New("service unavailable")
+			}
+			data := &weather.WeatherData{}
+			data.City.Name = location.Name
+			return data, nil
+		},
+	}
+	defer func() { weather.DefaultWeatherService = originalService }()
+
+	tests := []struct {
+		name     string
+		args     *ParsedArgs
+		wantErr  bool
+		expected []string
+	}{
+		{
+			name:     "Name with spaces",
+			args:     &ParsedArgs{Command: CommandGetWeather, Location: "New York", Locations: []string{"New", "York"}},
+			wantErr:  false,
+			expected: []string{"Weather forecast for New York"},
+		},
+		{
+			name:     "Several locations with failures",
+			args:     &ParsedArgs{Command: CommandGetWeather, Location: "Tokyo Paris London", Locations: []string{"Tokyo", "Paris", "London"}},
+			wantErr:  true,
+			expected: []string{"Weather forecast for Tokyo", "Paris: Error: failed to get location", "London: Error: failed to fetch weather data: service unavailable"},
+		},
+		{
+			name:    "Watch several locations",
+			args:    &ParsedArgs{Command: CommandGetWeather, Location: "Tokyo London", Locations: []string{"Tokyo", "London"}, Watch: time.Minute},
+			wantErr: true,
+		},
+		{
+			name:     "All locations",
+			args:     &ParsedArgs{Command: CommandGetWeather, All: true},
+			wantErr:  true,
+			expected: []string{"Weather forecast for Tokyo", "Weather forecast for New York", "London: Error"},
+		},
+		{
+			name:     "Raw coordinates",
+			args:     &ParsedArgs{Command: CommandGetWeather, Coordinates: true, Latitude: 35.7, Longitude: 139.7},
+			wantErr:  false,
+			expected: []string{"Weather forecast for 35.7000, 139.7000", "Nearest saved location: Tokyo (1.4 km SSW)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := executeGetWeather(tt.args, cfg)
+
+			w.Close()
+			os.Stdout = oldStdout
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("executeGetWeather() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var buf bytes.Buffer
+			io.Copy(&buf, r)
+			output := buf.String()
+
+			last := -1
+			for _, expected := range tt.expected {
+				idx := strings.Index(output, expected)
+				if idx < 0 {
+					t.Errorf("Output didn't contain expected string: %s\nActual output:\n%s", expected, output)
+					continue
+				}
+				if idx < last {
+					t.Errorf("Output for %s is out of input order", expected)
+				}
+				last = idx
+			}
+		})
+	}
+}
+
+func TestExecuteCompare(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
+			{Name: "Berlin", Latitude: 52.52, Longitude: 13.405},
+		},
+		TemperatureUnit:  "C",
+		ForecastInterval: 8,
+	}
+
+	originalService := weather.DefaultWeatherService
+	weather.DefaultWeatherService = &MockWeatherService{
+		GetWeatherForecastFunc: func(cfg *config.Config, location config.Location) (*weather.WeatherData, error) {
+			data := &weather.WeatherData{}
+			data.City.Name = location.Name
+			return data, nil
+		},
+	}
+	defer func() { weather.DefaultWeatherService = originalService }()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := executeCompare(&ParsedArgs{Command: CommandCompare, Locations: []string{"Tokyo", "Berlin"}}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Errorf("executeCompare returned an error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	if !strings.Contains(buf.String(), "| Tokyo") || !strings.Contains(buf.String(), "| Berlin") {
+		t.Errorf("executeCompare output didn't contain location columns:\n%s", buf.String())
+	}
+
+	// An unknown location is reported and the others are still compared
+	r, w, _ = os.Pipe()
+	os.Stdout = w
+
+	err = executeCompare(&ParsedArgs{Command: CommandCompare, Locations: []string{"Tokyo", "Paris", "Berlin"}}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err == nil || err.Error() != "failed to get weather for 1 of 3 locations" {
+		t.Errorf("executeCompare error = %v, want one failed location", err)
+	}
+	buf.Reset()
+	io.Copy(&buf, r)
+	for _, want := range []string{"Paris: Error: failed to get location", "| Tokyo", "| Berlin"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("executeCompare output didn't contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestExecuteDefaultLocation(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
+		},
+		TemperatureUnit:  "C",
+		ForecastInterval: 1,
+	}
+
+	originalService := weather.DefaultWeatherService
+	weather.DefaultWeatherService = &MockWeatherService{
+		GetWeatherForecastFunc: func(cfg *config.Config, location config.Location) (*weather.WeatherData, error) {
+			data := &weather.WeatherData{}
+			data.City.Name = location.Name
+			return data, nil
+		},
+	}
+	defer func() { weather.DefaultWeatherService = originalService }()
+
+	if err := executeGetWeather(&ParsedArgs{Command: CommandGetWeather}, cfg); err == nil {
+		t.Errorf("executeGetWeather without a location or default should fail")
+	}
+
+	if err := executeSetDefaultLocation(&ParsedArgs{Command: CommandSetDefaultLocation, Name: "Tokyo"}, cfg); err != nil {
+		t.Fatalf("executeSetDefaultLocation returned an error: %v", err)
+	}
+	if cfg.DefaultLocation != "Tokyo" {
+		t.Errorf("Default location was not set correctly")
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := ExecuteCommand(&ParsedArgs{Command: CommandHelp}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Errorf("ExecuteCommand returned an error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	if !strings.Contains(buf.String(), "Weather forecast for Tokyo") {
+		t.Errorf("Plain weather should show the default location, got:\n%s", buf.String())
+	}
+}
+
+func TestExecuteGroup(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Tags: []string{"offices"}},
+			{Name: "Home"},
+			{Name: "Berlin", Tags: []string{"offices"}},
+		},
+		TemperatureUnit:  "C",
+		ForecastInterval: 1,
+	}
+
+	originalService := weather.DefaultWeatherService
+	weather.DefaultWeatherService = &MockWeatherService{
+		GetWeatherForecastFunc: func(cfg *config.Config, location config.Location) (*weather.WeatherData, error) {
+			data := &weather.WeatherData{}
+			data.City.Name = location.Name
+			return data, nil
+		},
+	}
+	defer func() { weather.DefaultWeatherService = originalService }()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := executeGetWeather(&ParsedArgs{Command: CommandGetWeather, Group: "Offices"}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Errorf("executeGetWeather returned an error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+	if !strings.Contains(output, "Weather forecast for Tokyo") || !strings.Contains(output, "Weather forecast for Berlin") {
+		t.Errorf("Group output didn't contain every office:\n%s", output)
+	}
+	if strings.Contains(output, "Home") {
+		t.Errorf("Group output contained a location outside the group:\n%s", output)
+	}
+
+	if err := executeGetWeather(&ParsedArgs{Command: CommandGetWeather, Group: "family"}, cfg); err == nil {
+		t.Errorf("executeGetWeather should fail for an empty group")
+	}
+}
+
+func TestExecuteEditLocation(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
+		},
+		DefaultLocation: "Tokyo",
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := executeEditLocation(&ParsedArgs{Command: CommandRenameLocation, Name: "Tokyo", NewName: "Tokyo HQ"}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("executeEditLocation returned an error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	for _, expected := range []string{"name: Tokyo -> Tokyo HQ", "default_location: Tokyo -> Tokyo HQ"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("executeEditLocation output didn't contain expected string: %s\n%s", expected, buf.String())
+		}
+	}
+
+	err = executeEditLocation(&ParsedArgs{Command: CommandRenameLocation, Name: "Paris", NewName: "Lyon"}, cfg)
+	if err == nil {
+		t.Errorf("executeEditLocation should fail for an unknown location")
+	}
+}
+
+func TestExecuteAddLocationNearbyWarning(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Shinjuku", Latitude: 35.6938, Longitude: 139.7034},
+		},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := executeAddLocation(&ParsedArgs{Command: CommandAddLocation, Name: "Office", Latitude: 35.6925, Longitude: 139.7020}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("executeAddLocation returned an error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	if !strings.Contains(buf.String(), "Warning: 'Shinjuku' is only") {
+		t.Errorf("executeAddLocation should warn about a nearby location, got:\n%s", buf.String())
+	}
+	if len(cfg.Locations) != 2 {
+		t.Errorf("A nearby location should still be added")
+	}
+
+	// No warning when the location isn't added
+	r, w, _ = os.Pipe()
+	os.Stdout = w
+
+	err = executeAddLocation(&ParsedArgs{Command: CommandAddLocation, Name: "office", Latitude: 35.6925, Longitude: 139.7020}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err == nil {
+		t.Fatal("executeAddLocation should fail for a duplicate name")
+	}
+	buf.Reset()
+	io.Copy(&buf, r)
+	if buf.Len() != 0 {
+		t.Errorf("executeAddLocation printed output for a failed add:\n%s", buf.String())
+	}
+}
+
+func TestExecuteExportImportLocations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "places.csv")
+	source := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917, Tags: []string{"japan"}},
+			{Name: "Paris", Latitude: 48.8566, Longitude: 2.3522},
+		},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	exportErr := executeExportLocations(&ParsedArgs{Command: CommandExportLocations, Format: location.FormatCSV, Path: path}, source)
+
+	target := &config.Config{
+		Locations: []config.Location{
+			{Name: "tokyo", Latitude: 35.7, Longitude: 139.7},
+		},
+	}
+	importErr := executeImportLocations(&ParsedArgs{Command: CommandImportLocations, Format: location.FormatCSV, Path: path}, target)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if exportErr != nil {
+		t.Fatalf("executeExportLocations returned an error: %v", exportErr)
+	}
+	if importErr != nil {
+		t.Fatalf("executeImportLocations returned an error: %v", importErr)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	for _, expected := range []string{"Exported 2 locations", "Added (1):\n  Paris", "Skipped (1):", "Imported 1 of 2 locations."} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, buf.String())
+		}
+	}
+	if len(target.Locations) != 2 || target.Locations[1].Name != "Paris" {
+		t.Errorf("executeImportLocations locations = %+v", target.Locations)
+	}
+
+	if err := executeImportLocations(&ParsedArgs{Command: CommandImportLocations, Format: location.FormatCSV, Path: path + ".missing"}, target); err == nil {
+		t.Errorf("executeImportLocations should fail for a missing file")
+	}
+}
+
+func TestExecuteNearestAndDistance(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "London", Latitude: 51.5074, Longitude: -0.1278},
+			{Name: "Paris", Latitude: 48.8566, Longitude: 2.3522},
+			{Name: "New York", Latitude: 40.7128, Longitude: -74.0060},
+		},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	nearestErr := executeNearestLocations(&ParsedArgs{Command: CommandNearestLocations, Latitude: 50.8503, Longitude: 4.3517, Limit: 2}, cfg)
+	distanceErr := executeLocationDistance(&ParsedArgs{Command: CommandLocationDistance, Locations: []string{"london", "paris"}}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if nearestErr != nil {
+		t.Fatalf("executeNearestLocations returned an error: %v", nearestErr)
+	}
+	if distanceErr != nil {
+		t.Fatalf("executeLocationDistance returned an error: %v", distanceErr)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+	for _, expected := range []string{" 1. Paris ", " 2. London ", "London -> Paris: 343.6 km, heading SSE (148°)"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "New York") {
+		t.Errorf("executeNearestLocations should respect the limit:\n%s", output)
+	}
+
+	if err := executeLocationDistance(&ParsedArgs{Command: CommandLocationDistance, Locations: []string{"London", "Rome"}}, cfg); err == nil {
+		t.Errorf("executeLocationDistance should fail for an unknown location")
+	}
+	if err := executeNearestLocations(&ParsedArgs{Command: CommandNearestLocations, Limit: 5}, &config.Config{}); err == nil {
+		t.Errorf("executeNearestLocations should fail without saved locations")
+	}
+}
+
+func TestExecuteShowConfig(t *testing.T) {
+	t.Setenv(config.ConfigEnvVar, filepath.Join(t.TempDir(), "config.json"))
+	for _, key := range config.ConfigKeys() {
+		t.Setenv(config.EnvVar(key), "")
+	}
+	t.Setenv("WEATHER_CLI_UNITS", "F")
+
+	hook := "https://hooks.slack.com/services/T000/B000/secret"
+	cfg := &config.Config{
+		APIKey:           "abcdef123456",
+		TemperatureUnit:  "C",
+		ForecastInterval: 24,
+		Notifiers:        map[string]config.Notifier{"team": {Type: "slack", URL: hook}},
+		AlertNotify:      []string{hook},
+		Alerts:           []config.AlertRule{{Name: "Gusts", Metric: "gust", Notify: []string{hook}}},
+		Jobs:             []config.Job{{Name: "Morning", Schedule: "0 7 * * *", Task: config.TaskSummary, Notify: []string{"team", hook}}},
+	}
+	if err := config.ApplyOverrides(cfg, map[string]string{"forecast_interval": "6"}); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     *ParsedArgs
+		expected []string
+	}{
+		{
+			name:     "File values",
+			args:     &ParsedArgs{Command: CommandShowConfig},
+			expected: []string{`"temperature_unit": "C"`, `"forecast_interval": 24`, `"api_key": "********3456"`, `"url": "https://hooks.slack.com"`},
+		},
+		{
+			name: "Effective values",
+			args: &ParsedArgs{Command: CommandShowConfig, Effective: true},
+			expected: []string{
+				"********3456",
+				"[env: WEATHER_CLI_UNITS]",
+				"[flag: --override forecast_interval]",
+				"openweather",
+				"[default]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := executeShowConfig(tt.args, cfg)
+
+			w.Close()
+			os.Stdout = oldStdout
+
+			if err != nil {
+				t.Fatalf("executeShowConfig returned an error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			io.Copy(&buf, r)
+			for _, expected := range tt.expected {
+				if !strings.Contains(buf.String(), expected) {
+					t.Errorf("Output didn't contain expected string: %q\n%s", expected, buf.String())
+				}
+			}
+			if strings.Contains(buf.String(), "abcdef") {
+				t.Errorf("executeShowConfig should mask the API key:\n%s", buf.String())
+			}
+			if strings.Contains(buf.String(), "secret") {
+				t.Errorf("executeShowConfig should hide webhook paths:\n%s", buf.String())
+			}
+		})
+	}
+	if cfg.Notifiers["team"].URL != hook || cfg.Jobs[0].Notify[1] != hook {
+		t.Errorf("executeShowConfig changed the config: %+v", cfg)
+	}
+}
+
+func TestExecuteSetAPIKeySource(t *testing.T) {
+	t.Setenv(config.ConfigEnvVar, filepath.Join(t.TempDir(), "config.json"))
+	keyring := &secrettest.Keyring{}
+	oldKeyring := secret.DefaultKeyring
+	secret.DefaultKeyring = keyring
+	defer func() { secret.DefaultKeyring = oldKeyring }()
+
+	oldStdin := stdin
+	stdin = strings.NewReader("typed-key\n")
+	defer func() { stdin = oldStdin }()
+
+	keyFile := filepath.Join(t.TempDir(), "owm-key")
+	os.WriteFile(keyFile, []byte("file-key\n"), 0600)
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	cfg := &config.Config{APIKey: "plain-key"}
+	keyringErr := executeSetAPIKeySource(&ParsedArgs{Command: CommandSetAPIKeySource, SecretSource: "keyring"}, cfg)
+	keyringCfg := *cfg
+	fileErr := executeSetAPIKeySource(&ParsedArgs{Command: CommandSetAPIKeySource, SecretSource: "file", SecretValue: keyFile}, cfg)
+	missingErr := executeSetAPIKeySource(&ParsedArgs{Command: CommandSetAPIKeySource, SecretSource: "file", SecretValue: keyFile + ".missing"}, cfg)
+	plainErr := executeSetAPIKey(&ParsedArgs{Command: CommandSetAPIKey, APIKey: "new-plain-key"}, cfg)
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if keyringErr != nil || fileErr != nil || plainErr != nil {
+		t.Fatalf("Unexpected errors: keyring %v, file %v, plaintext %v", keyringErr, fileErr, plainErr)
+	}
+	if missingErr == nil {
+		t.Errorf("executeSetAPIKeySource should fail for a missing key file")
+	}
+
+	if got, _ := keyring.Get(config.APIKeyAccount); got != "typed-key" {
+		t.Errorf("Keyring holds %q, want the key read from stdin", got)
+	}
+	if keyringCfg.APIKey != "" || !keyringCfg.APIKeyKeyring {
+		t.Errorf("Storing in the keyring should remove the plaintext key: %+v", keyringCfg)
+	}
+	if cfg.APIKey != "new-plain-key" || cfg.APIKeyFile != "" || cfg.APIKeyKeyring {
+		t.Errorf("--set-api-key should replace the other sources: %+v", cfg)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	for _, expected := range []string{"API key stored in the system keyring.", "API key will be read from the file", "Warning: the API key is stored in plaintext"} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, buf.String())
+		}
+	}
+}
+
+func TestExecuteConfigDoctor(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+	for _, key := range config.ConfigKeys() {
+		t.Setenv(config.EnvVar(key), "")
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		wantErr  bool
+		expected []string
+	}{
+		{
+			name:     "Valid file",
+			content:  `{"schema_version": 1, "api_key": "abc", "temperature_unit": "C", "forecast_interval": 24}`,
+			expected: []string{"Schema version: 1 (current)", "No problems found."},
+		},
+		{
+			name:     "Old schema without an API key",
+			content:  `{"temperature_unit": "metric"}`,
+			expected: []string{"Schema version: 0 (upgraded to 1", "0 -> 1:", "no API key is configured", "No problems found."},
+		},
+		{
+			name:     "Invalid values",
+			content:  `{"schema_version": 1, "forecast_interval": -2, "locations": [{"name": "Tokyo", "latitude": 135}]}`,
+			wantErr:  true,
+			expected: []string{"Warnings:\n  $.locations[0].latitude: must be between -90 and 90, got 135", "Problems:\n  $.forecast_interval: must be a positive number of hours, got -2"},
+		},
+		{
+			name:     "Old schema with clashing names",
+			content:  `{"api_key": "abc", "default_location": "Paris", "locations": [{"name": "Tokyo", "latitude": 95}, {"name": "tokyo"}]}`,
+			expected: []string{`    renamed location "tokyo" to "tokyo (2)"`, `    cleared default_location "Paris"`, "Warnings:\n  $.locations[0].latitude", "No problems found that stop"},
+		},
+		{
+			name:     "Syntax error",
+			content:  "{\n  \"api_key\": \"abc\",\n}",
+			wantErr:  true,
+			expected: []string{"$: line 3, column 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configFile, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+
+			err := executeConfigDoctor()
+
+			w.Close()
+			os.Stdout = oldStdout
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("executeConfigDoctor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && ExitCode(err) != ExitInvalidConfig {
+				t.Errorf("ExitCode(%v) = %d, want %d", err, ExitCode(err), ExitInvalidConfig)
+			}
+
+			var buf bytes.Buffer
+			io.Copy(&buf, r)
+			for _, expected := range tt.expected {
+				if !strings.Contains(buf.String(), expected) {
+					t.Errorf("Output didn't contain expected string: %q\n%s", expected, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteConvertConfig(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+	if err := config.SaveConfig(&config.Config{TemperatureUnit: "C", ForecastInterval: 24}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := executeConvertConfig(&ParsedArgs{Command: CommandConvertConfig, Format: "toml"})
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("executeConvertConfig returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	tomlFile := strings.TrimSuffix(configFile, ".json") + ".toml"
+	for _, expected := range []string{"Converted " + configFile + " to " + tomlFile, "Point --config or WEATHER_CLI_CONFIG at " + tomlFile} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, buf.String())
+		}
+	}
+
+	data, err := os.ReadFile(tomlFile)
+	if err != nil || !strings.Contains(string(data), `temperature_unit = "C"`) {
+		t.Errorf("Converted file = %s, %v", data, err)
+	}
+}
+
+func TestExecuteProfileCommands(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+	t.Setenv(config.ProfileEnvVar, "")
+	if err := config.SaveConfig(&config.Config{TemperatureUnit: "C", ForecastInterval: 24}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	commands := []*ParsedArgs{
+		{Command: CommandCreateProfile, Name: "home"},
+		{Command: CommandCreateProfile, Name: "travel", Base: "home"},
+		{Command: CommandUseProfile, Name: "travel"},
+		{Command: CommandListProfiles},
+	}
+	for _, args := range commands {
+		if err = ExecuteCommand(args, cfg); err != nil {
+			break
+		}
+	}
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("ExecuteCommand returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	for _, expected := range []string{
+		"Profile 'travel' created, inheriting from 'home'.",
+		"Now using profile 'travel'.",
+		"* default\n  home\n  travel (inherits from home)\n",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, buf.String())
+		}
+	}
+
+	// The new current profile applies on the next run, and changes go into it
+	saved, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if err := config.UseProfile(saved, ""); err != nil || config.ActiveProfile(saved) != "travel" {
+		t.Fatalf("UseProfile() error = %v, active = %q", err, config.ActiveProfile(saved))
+	}
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	err = executeSetUnit(&ParsedArgs{Unit: "F"}, saved)
+	os.Stdout = oldStdout
+	if err != nil {
+		t.Fatalf("executeSetUnit returned an error: %v", err)
+	}
+	reloaded, _ := config.LoadConfig()
+	if reloaded.TemperatureUnit != "C" || reloaded.Profiles["travel"].TemperatureUnit != "F" {
+		t.Errorf("Unit = %s, travel unit = %s, want C and F", reloaded.TemperatureUnit, reloaded.Profiles["travel"].TemperatureUnit)
+	}
+
+	if err := ExecuteCommand(&ParsedArgs{Command: CommandDeleteProfile, Name: "home"}, reloaded); err == nil {
+		t.Error("Deleting a base profile should fail")
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"weather-cli/internal/config"
+	"weather-cli/internal/daemon"
+)
+
+// executeDaemon runs the scheduled jobs of the config until SIGINT or SIGTERM,
+// logging to standard error
+func executeDaemon(args *ParsedArgs, cfg *config.Config) error {
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
+	d, err := daemon.New(cfg, newLogger(args))
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := shutdownContext()
+	defer stop()
+	return d.Run(ctx)
+}
+
+// newLogger creates the logger of a long-running command, writing to standard
+// error in the format chosen with --log-format
+func newLogger(args *ParsedArgs) *slog.Logger {
+	options := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if args.Verbose {
+		options.Level = slog.LevelDebug
+	}
+	if args.Format == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, options))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, options))
+}
+
+// shutdownContext returns a context that is cancelled by SIGINT or SIGTERM.
+// After that, a second signal ends the process at once.
+func shutdownContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
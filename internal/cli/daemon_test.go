@@ -0,0 +1,16 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"weather-cli/internal/config"
+	"weather-cli/internal/daemon"
+)
+
+func TestExecuteDaemonWithoutJobs(t *testing.T) {
+	cfg := &config.Config{APIKey: "test_api_key"}
+	err := ExecuteCommand(&ParsedArgs{Command: CommandDaemon, Format: "text"}, cfg)
+	if !errors.Is(err, daemon.ErrNoJobs) {
+		t.Errorf("ExecuteCommand() error = %v, want ErrNoJobs", err)
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/weather"
+)
+
+// Exit codes returned by the weather command, documented in the README
+const (
+	ExitOK               = 0
+	ExitError            = 1
+	ExitUsage            = 2
+	ExitInvalidConfig    = 3
+	ExitLocationNotFound = 4
+	ExitUnauthorized     = 5
+	ExitRateLimited      = 6
+	ExitNetwork          = 7
+)
+
+// UsageError reports command-line arguments that could not be parsed
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("error parsing arguments: %v", e.Err)
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// failure ties an exit code to the errors it is returned for and a hint on fixing them
+type failure struct {
+	match func(err error) bool
+	code  int
+	hint  string
+}
+
+// failures are checked in order, so that the most specific cause wins
+var failures = []failure{
+	{
+		match: func(err error) bool { var u *UsageError; return errors.As(err, &u) },
+		code:  ExitUsage,
+		hint:  "Run 'weather --help' to see the available commands and options.",
+	},
+	{
+		match: func(err error) bool { return errors.Is(err, config.ErrInvalidConfig) },
+		code:  ExitInvalidConfig,
+		hint:  "Run 'weather config doctor' to find and fix the problems in your configuration.",
+	},
+	{
+		match: func(err error) bool {
+			return errors.Is(err, location.ErrLocationNotFound) || errors.Is(err, weather.ErrNotFound)
+		},
+		code: ExitLocationNotFound,
+		hint: "Run 'weather --list' to see your saved locations, or add one with 'weather -i <latitude> <longitude> <name>'.",
+	},
+	{
+		match: func(err error) bool { return errors.Is(err, weather.ErrUnauthorized) },
+		code:  ExitUnauthorized,
+		hint:  "Check the API key with 'weather config show --effective', or set a new one with 'weather init'. New OpenWeather keys can take a couple of hours to become active.",
+	},
+	{
+		match: func(err error) bool { return errors.Is(err, weather.ErrRateLimited) },
+		code:  ExitRateLimited,
+		hint:  "The API key has made too many requests. Wait a minute and try again.",
+	},
+	{
+		match: func(err error) bool { return errors.Is(err, weather.ErrNetwork) },
+		code:  ExitNetwork,
+		hint:  "Check your internet connection. Behind a proxy, set HTTPS_PROXY.",
+	},
+}
+
+// ExitCode returns the exit code for an error returned by a command
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, f := range failures {
+		if f.match(err) {
+			return f.code
+		}
+	}
+	return ExitError
+}
+
+// Hint suggests how to fix the cause of err, or returns "" when there is nothing to suggest
+func Hint(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, f := range failures {
+		if f.match(err) {
+			return f.hint
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/notify"
+	"weather-cli/internal/weather"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantHint string
+	}{
+		{"No error", nil, ExitOK, ""},
+		{"Other error", errors.New("boom"), ExitError, ""},
+		{"Usage", &UsageError{Err: errors.New("unknown flag")}, ExitUsage, "weather --help"},
+		{"Invalid config", &config.ValidationError{File: "config.json"}, ExitInvalidConfig, "weather config doctor"},
+		{"Location not found", fmt.Errorf("error executing command: %w", location.ErrLocationNotFound), ExitLocationNotFound, "weather --list"},
+		{"Place unknown to the provider", &weather.APIError{Service: "OpenWeather API", StatusCode: 404, Message: "city not found"}, ExitLocationNotFound, "weather --list"},
+		{"Unauthorized", fmt.Errorf("error fetching weather data: %w", weather.ErrUnauthorized), ExitUnauthorized, "weather init"},
+		{"Rate limited", weather.ErrRateLimited, ExitRateLimited, "try again"},
+		{"Network", weather.RequestError("OpenWeather API", errors.New("no route to host")), ExitNetwork, "HTTPS_PROXY"},
+		{"Webhook rejected the message", &notify.DeliveryError{Target: "webhook", StatusCode: 401}, ExitError, ""},
+		{"Webhook not found", &notify.DeliveryError{Target: "webhook", StatusCode: 404}, ExitError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.wantCode {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.wantCode)
+			}
+			hint := Hint(tt.err)
+			if (tt.wantHint == "") != (hint == "") || !strings.Contains(hint, tt.wantHint) {
+				t.Errorf("Hint(%v) = %q, want it to contain %q", tt.err, hint, tt.wantHint)
+			}
+		})
+	}
+}
+
+func TestRunUsageError(t *testing.T) {
+	err := NewCLI(&config.Config{}).Run([]string{"weather", "--no-such-flag"})
+	if ExitCode(err) != ExitUsage {
+		t.Errorf("Run() error = %v, exit code %d, want %d", err, ExitCode(err), ExitUsage)
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GlobalOptions holds flags that apply to every command and must be known
+// before the configuration is loaded
+type GlobalOptions struct {
+	ConfigPath string
+	// Profile selects the config profile to use instead of current_profile
+	Profile string
+	// Overrides holds config values given with --override key=value for this run only
+	Overrides map[string]string
+}
+
+// ParseGlobalOptions extracts the global flags from anywhere in args and returns
+// the remaining arguments for ParseArgs
+func ParseGlobalOptions(args []string) (*GlobalOptions, []string, error) {
+	opts := &GlobalOptions{}
+	if len(args) == 0 {
+		return opts, args, nil
+	}
+
+	rest := []string{args[0]}
+	for i := 1; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || (name != "config" && name != "profile" && name != "override") {
+			rest = append(rest, args[i])
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag needs an argument: -%s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "config":
+			if value == "" {
+				return nil, nil, fmt.Errorf("flag -%s needs a non-empty path", name)
+			}
+			opts.ConfigPath = value
+		case "profile":
+			if value == "" {
+				return nil, nil, fmt.Errorf("flag -%s needs a non-empty name", name)
+			}
+			opts.Profile = value
+		case "override":
+			key, v, ok := strings.Cut(value, "=")
+			if !ok || key == "" {
+				return nil, nil, fmt.Errorf("invalid override '%s'. Use: --override <key>=<value>", value)
+			}
+			if opts.Overrides == nil {
+				opts.Overrides = make(map[string]string)
+			}
+			opts.Overrides[key] = v
+		}
+	}
+
+	return opts, rest, nil
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseGlobalOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantOpts *GlobalOptions
+		wantRest []string
+		wantErr  bool
+	}{
+		{
+			name:     "No global flags",
+			args:     []string{"weather", "Tokyo", "--watch", "10m"},
+			wantOpts: &GlobalOptions{},
+			wantRest: []string{"weather", "Tokyo", "--watch", "10m"},
+		},
+		{
+			name:     "Config before the command",
+			args:     []string{"weather", "--config", "/tmp/weather.json", "--list"},
+			wantOpts: &GlobalOptions{ConfigPath: "/tmp/weather.json"},
+			wantRest: []string{"weather", "--list"},
+		},
+		{
+			name:     "Config with equals sign after a subcommand",
+			args:     []string{"weather", "loc", "default", "Tokyo", "-config=work.json"},
+			wantOpts: &GlobalOptions{ConfigPath: "work.json"},
+			wantRest: []string{"weather", "loc", "default", "Tokyo"},
+		},
+		{
+			name:     "Overrides",
+			args:     []string{"weather", "--override", "temperature_unit=F", "Tokyo", "--override=api_key=a=b"},
+			wantOpts: &GlobalOptions{Overrides: map[string]string{"temperature_unit": "F", "api_key": "a=b"}},
+			wantRest: []string{"weather", "Tokyo"},
+		},
+		{
+			name:     "Profile after the command",
+			args:     []string{"weather", "--list", "--profile", "work"},
+			wantOpts: &GlobalOptions{Profile: "work"},
+			wantRest: []string{"weather", "--list"},
+		},
+		{
+			name:    "Profile with an empty name",
+			args:    []string{"weather", "--profile="},
+			wantErr: true,
+		},
+		{
+			name:    "Override without a value",
+			args:    []string{"weather", "--override", "temperature_unit"},
+			wantErr: true,
+		},
+		{
+			name:    "Config without a path",
+			args:    []string{"weather", "--list", "--config"},
+			wantErr: true,
+		},
+		{
+			name:    "Config with an empty path",
+			args:    []string{"weather", "--config="},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, rest, err := ParseGlobalOptions(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGlobalOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(opts, tt.wantOpts) {
+				t.Errorf("ParseGlobalOptions() opts = %+v, want %+v", opts, tt.wantOpts)
+			}
+			if !reflect.DeepEqual(rest, tt.wantRest) {
+				t.Errorf("ParseGlobalOptions() rest = %v, want %v", rest, tt.wantRest)
+			}
+		})
+	}
+}
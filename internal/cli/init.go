@@ -0,0 +1,380 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/weather"
+)
+
+// InitOptions holds the answers to the init wizard given as flags
+type InitOptions struct {
+	// NonInteractive takes every answer from the flags instead of prompting
+	NonInteractive bool
+	Provider       string
+	APIKey         string
+	Unit           string
+	Language       string
+	// Location is searched for and the first match saved as the default location
+	Location string
+	// NoVerify skips the test request that checks the API key
+	NoVerify bool
+}
+
+// searchLimit is the number of places offered when searching for a location
+const searchLimit = 5
+
+// NeedsSetup reports whether the init wizard should run before the command in args,
+// which is when there is no config file yet and stdin is a terminal to answer on
+func NeedsSetup(args []string) bool {
+	if config.ConfigExists() || !isTerminal(os.Stdin) || os.Getenv(config.EnvVar("api_key")) != "" {
+		return false
+	}
+	_, err := ParseArgs(args)
+	return err == nil && NeedsConfig(args)
+}
+
+// isTerminal reports whether f is a character device such as a terminal. The null
+// device, which stdin is redirected from in scripts and CI, is a character device too.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
+
+// initAnswers are the settings chosen in the init wizard
+type initAnswers struct {
+	provider string
+	// apiKey is empty when the configured API key is kept
+	apiKey   string
+	unit     string
+	language string
+	place    *location.Place
+}
+
+// executeInit creates the config file, or updates an existing one, from answers
+// given at the prompt or as flags with --non-interactive
+func executeInit(args *ParsedArgs) error {
+	cfg := config.NewDefaultConfig()
+	if config.ConfigExists() {
+		loaded, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("error loading configuration: %w", err)
+		}
+		cfg = loaded
+	}
+
+	var answers *initAnswers
+	var err error
+	if args.Init.NonInteractive {
+		answers, err = initFromFlags(args.Init, cfg)
+	} else {
+		fmt.Println("Welcome to weather-cli! Answer a few questions to create your configuration.")
+		fmt.Println("Press Enter to accept the value in brackets.")
+		answers, err = promptInit(&prompter{in: bufio.NewReader(stdin)}, args.Init, cfg)
+	}
+	if err != nil {
+		return err
+	}
+	return saveInit(answers, cfg)
+}
+
+// hasAPIKey reports whether cfg already names an API key or a source for one
+func hasAPIKey(cfg *config.Config) bool {
+	return cfg.APIKey != "" || cfg.APIKeyCmd != "" || cfg.APIKeyFile != "" || cfg.APIKeyKeyring
+}
+
+// initFromFlags checks the answers given as flags, keeping the current settings
+// for those that were left out
+func initFromFlags(opts InitOptions, cfg *config.Config) (*initAnswers, error) {
+	answers := &initAnswers{provider: opts.Provider, apiKey: opts.APIKey, unit: cfg.TemperatureUnit, language: cfg.Language}
+	if err := checkProvider(answers.provider); err != nil {
+		return nil, err
+	}
+	if answers.apiKey == "" && !hasAPIKey(cfg) {
+		return nil, errors.New("--api-key is required with --non-interactive")
+	}
+	if answers.apiKey != "" && !opts.NoVerify {
+		if err := weather.ValidateAPIKey(answers.apiKey); err != nil {
+			return nil, fmt.Errorf("failed to check the API key (use --no-verify to save it anyway): %w", err)
+		}
+	}
+	if opts.Unit != "" {
+		answers.unit = opts.Unit
+	}
+	if opts.Language != "" {
+		lang, err := config.NormalizeLanguage(opts.Language)
+		if err != nil {
+			return nil, err
+		}
+		answers.language = lang
+	}
+
+	if opts.Location != "" {
+		key, err := searchKey(answers.apiKey, cfg)
+		if err != nil {
+			return nil, err
+		}
+		places, err := location.Search(opts.Location, key, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for '%s': %w", opts.Location, err)
+		}
+		if len(places) == 0 {
+			return nil, fmt.Errorf("no places found for '%s'", opts.Location)
+		}
+		answers.place = &places[0]
+	}
+	return answers, nil
+}
+
+// promptInit asks for each setting, offering the current value or a flag given on
+// the command line as the default
+func promptInit(p *prompter, opts InitOptions, cfg *config.Config) (*initAnswers, error) {
+	answers := &initAnswers{}
+
+	for {
+		provider, err := p.ask("Weather provider", opts.Provider)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkProvider(provider); err != nil {
+			fmt.Printf("%v\n", err)
+			continue
+		}
+		answers.provider = provider
+		break
+	}
+
+	fmt.Println("Get an OpenWeather API key at https://home.openweathermap.org/api_keys")
+	for {
+		hint := opts.APIKey
+		if hint == "" && hasAPIKey(cfg) {
+			hint = "keep current"
+		}
+		key, err := p.ask("API key", hint)
+		if err != nil {
+			return nil, err
+		}
+		if key == "keep current" && opts.APIKey == "" {
+			break
+		}
+		if key == "" {
+			fmt.Println("An API key is required to fetch forecasts.")
+			continue
+		}
+		if !opts.NoVerify {
+			fmt.Print("Checking the API key... ")
+			if err := weather.ValidateAPIKey(key); err != nil {
+				fmt.Printf("failed: %v\n", err)
+				save, err := p.confirm("Save it anyway?")
+				if err != nil {
+					return nil, err
+				}
+				if !save {
+					continue
+				}
+			} else {
+				fmt.Println("OK")
+			}
+		}
+		answers.apiKey = key
+		break
+	}
+
+	for {
+		unit := opts.Unit
+		if unit == "" {
+			unit = cfg.TemperatureUnit
+		}
+		unit, err := p.ask("Temperature unit, C or F", unit)
+		if err != nil {
+			return nil, err
+		}
+		if unit = strings.ToUpper(unit); unit != "C" && unit != "F" {
+			fmt.Println("Please answer C or F.")
+			continue
+		}
+		answers.unit = unit
+		break
+	}
+
+	for {
+		lang := opts.Language
+		if lang == "" {
+			lang = cfg.Language
+		}
+		if lang == "" {
+			lang = "en"
+		}
+		lang, err := p.ask("Language for weather descriptions, e.g. en, de or ja", lang)
+		if err != nil {
+			return nil, err
+		}
+		if answers.language, err = config.NormalizeLanguage(lang); err != nil {
+			fmt.Printf("%v\n", err)
+			continue
+		}
+		break
+	}
+
+	key, err := searchKey(answers.apiKey, cfg)
+	if err != nil {
+		fmt.Printf("Skipping the location search: %v\n", err)
+		return answers, nil
+	}
+	suggested := opts.Location
+	for {
+		query, err := p.ask("Search for a location to add, e.g. Tokyo (leave empty to skip)", suggested)
+		suggested = ""
+		if err != nil {
+			return nil, err
+		}
+		if query == "" {
+			return answers, nil
+		}
+		places, err := location.Search(query, key, searchLimit)
+		if err != nil {
+			fmt.Printf("Search failed: %v\n", err)
+			continue
+		}
+		if len(places) == 0 {
+			fmt.Printf("No places found for '%s'.\n", query)
+			continue
+		}
+		if answers.place, err = choosePlace(p, places); err != nil {
+			return nil, err
+		}
+		if answers.place != nil {
+			return answers, nil
+		}
+	}
+}
+
+// choosePlace lets the user pick one of the search results, or none to search again
+func choosePlace(p *prompter, places []location.Place) (*location.Place, error) {
+	for i, place := range places {
+		fmt.Printf("  %d. %s (%.4f, %.4f)\n", i+1, place, place.Latitude, place.Longitude)
+	}
+	fmt.Println("  0. Search again")
+	for {
+		answer, err := p.ask("Choose a location", "1")
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 0 || n > len(places) {
+			fmt.Printf("Please answer a number from 0 to %d.\n", len(places))
+			continue
+		}
+		if n == 0 {
+			return nil, nil
+		}
+		return &places[n-1], nil
+	}
+}
+
+// searchKey returns the API key to search for locations with: the new one, or
+// else the one already configured
+func searchKey(apiKey string, cfg *config.Config) (string, error) {
+	if apiKey != "" {
+		return apiKey, nil
+	}
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return "", err
+	}
+	return cfg.APIKey, nil
+}
+
+// checkProvider accepts the weather providers that can be configured
+func checkProvider(provider string) error {
+	if provider != config.DefaultProvider {
+		return fmt.Errorf("unsupported provider '%s'. Only %s is available", provider, config.DefaultProvider)
+	}
+	return nil
+}
+
+// saveInit writes the answers to the config file
+func saveInit(answers *initAnswers, cfg *config.Config) error {
+	err := config.Update(cfg, func() error {
+		cfg.Provider = answers.provider
+		if answers.apiKey != "" {
+			// A new key replaces the keyring, command or file the old one came from
+			cfg.SetAPIKey(answers.apiKey)
+			cfg.SetAPIKeySource("", "", false)
+		}
+		cfg.SetTemperatureUnit(answers.unit)
+		cfg.SetLanguage(answers.language)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save configuration: %w", err)
+	}
+
+	if place := answers.place; place != nil {
+		locationManager := location.NewManager(cfg)
+		if loc, err := locationManager.GetLocation(place.Name); err != nil || !strings.EqualFold(loc.Name, place.Name) {
+			if err := locationManager.AddLocation(place.Name, place.Latitude, place.Longitude); err != nil {
+				return fmt.Errorf("failed to add location: %w", err)
+			}
+			fmt.Printf("Location '%s' added.\n", place.Name)
+		}
+		if cfg.DefaultLocation == "" {
+			if err := locationManager.SetDefaultLocation(place.Name); err != nil {
+				return fmt.Errorf("failed to set default location: %w", err)
+			}
+			fmt.Printf("'%s' is now the default location.\n", place.Name)
+		}
+	}
+
+	path, _ := config.ConfigPath()
+	fmt.Printf("Configuration saved to %s.\n", path)
+	if answers.place != nil {
+		fmt.Printf("Try it: weather %s\n", answers.place.Name)
+	} else {
+		fmt.Println("Add a location with: weather -i <latitude> <longitude> <name>")
+	}
+	return nil
+}
+
+// prompter reads answers to the init wizard's questions
+type prompter struct {
+	in *bufio.Reader
+}
+
+// ask prints a question with the default answer in brackets and returns the
+// answer, or the default when the answer is empty
+func (p *prompter) ask(question, defaultAnswer string) (string, error) {
+	if defaultAnswer != "" {
+		fmt.Printf("%s [%s]: ", question, defaultAnswer)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (line == "" || err != io.EOF) {
+		fmt.Println()
+		return "", errors.New("setup cancelled before it was finished; nothing was saved")
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return defaultAnswer, nil
+}
+
+// confirm asks a yes/no question that defaults to no
+func (p *prompter) confirm(question string) (bool, error) {
+	answer, err := p.ask(question+" (y/N)", "")
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
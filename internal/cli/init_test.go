@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/weather"
+)
+
+// fakeOpenWeather serves the forecast and geocoding endpoints, accepting only good-key
+func fakeOpenWeather(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != "good-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"cod": 401, "message": "Invalid API key"}`)
+			return
+		}
+		if r.URL.Path == "/geo" {
+			if r.URL.Query().Get("q") == "Nowhere" {
+				fmt.Fprint(w, `[]`)
+				return
+			}
+			fmt.Fprint(w, `[
+				{"name": "Portland", "state": "Oregon", "country": "US", "lat": 45.5152, "lon": -122.6784},
+				{"name": "Portland", "state": "Maine", "country": "US", "lat": 43.6591, "lon": -70.2568}
+			]`)
+			return
+		}
+		fmt.Fprint(w, `{"cod": "200", "list": []}`)
+	}))
+	t.Cleanup(server.Close)
+
+	oldBase, oldGeocode := weather.BaseURL, location.GeocodeURL
+	weather.BaseURL, location.GeocodeURL = server.URL+"/forecast", server.URL+"/geo"
+	t.Cleanup(func() { weather.BaseURL, location.GeocodeURL = oldBase, oldGeocode })
+}
+
+// runInit runs the init command with input as stdin and returns what it printed
+func runInit(t *testing.T, args []string, input string) (string, error) {
+	parsed, err := ParseArgs(append([]string{"weather", "init"}, args...))
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+
+	oldStdin := stdin
+	stdin = strings.NewReader(input)
+	defer func() { stdin = oldStdin }()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err = ExecuteCommand(parsed, nil)
+
+	w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String(), err
+}
+
+func TestExecuteInitInteractive(t *testing.T) {
+	fakeOpenWeather(t)
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+
+	// A rejected key is asked again, then the second Portland is chosen after a failed search
+	input := strings.Join([]string{"", "bad-key", "n", "good-key", "f", "de", "Nowhere", "Portland", "3", "2"}, "\n") + "\n"
+	output, err := runInit(t, nil, input)
+	if err != nil {
+		t.Fatalf("executeInit returned an error: %v\n%s", err, output)
+	}
+	for _, expected := range []string{
+		"Weather provider [openweather]:",
+		"failed: OpenWeather API rejected the API key (Invalid API key)",
+		"Checking the API key... OK",
+		"No places found for 'Nowhere'.",
+		"  2. Portland, Maine, US (43.6591, -70.2568)",
+		"Please answer a number from 0 to 2.",
+		"'Portland' is now the default location.",
+		"Configuration saved to " + configFile,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, output)
+		}
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.APIKey != "good-key" || cfg.TemperatureUnit != "F" || cfg.Language != "de" || cfg.Provider != "openweather" {
+		t.Errorf("Saved config = %+v", cfg)
+	}
+	if len(cfg.Locations) != 1 || cfg.Locations[0].Latitude != 43.6591 || cfg.DefaultLocation != "Portland" {
+		t.Errorf("Saved locations = %+v, default = %q", cfg.Locations, cfg.DefaultLocation)
+	}
+}
+
+func TestExecuteInitCancelled(t *testing.T) {
+	fakeOpenWeather(t)
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+
+	if _, err := runInit(t, nil, "openweather\n"); err == nil || !strings.Contains(err.Error(), "nothing was saved") {
+		t.Errorf("executeInit error = %v, want a cancelled setup", err)
+	}
+	if config.ConfigExists() {
+		t.Error("A cancelled setup should not create the config file")
+	}
+}
+
+func TestExecuteInitNonInteractive(t *testing.T) {
+	fakeOpenWeather(t)
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, configFile)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"Missing API key", []string{"--non-interactive"}, "--api-key is required"},
+		{"Rejected API key", []string{"--non-interactive", "--api-key", "bad-key"}, "use --no-verify"},
+		{"Unknown place", []string{"--non-interactive", "--api-key", "good-key", "--location", "Nowhere"}, "no places found"},
+		{"Invalid language", []string{"--non-interactive", "--api-key", "good-key", "--lang", "german"}, "invalid language"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := runInit(t, tt.args, ""); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("executeInit error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+	if config.ConfigExists() {
+		t.Fatal("A failed setup should not create the config file")
+	}
+
+	args := []string{"--non-interactive", "--api-key", "good-key", "--unit", "F", "--lang", "pt-BR", "--location", "Portland"}
+	if output, err := runInit(t, args, ""); err != nil {
+		t.Fatalf("executeInit returned an error: %v\n%s", err, output)
+	}
+	// Running it again keeps the saved settings that are not given
+	if output, err := runInit(t, []string{"--non-interactive", "--no-verify", "--api-key", "new-key"}, ""); err != nil {
+		t.Fatalf("executeInit returned an error: %v\n%s", err, output)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.APIKey != "new-key" || cfg.TemperatureUnit != "F" || cfg.Language != "pt_br" {
+		t.Errorf("Saved config = %+v", cfg)
+	}
+	if len(cfg.Locations) != 1 || cfg.Locations[0].Latitude != 45.5152 || cfg.DefaultLocation != "Portland" {
+		t.Errorf("Saved locations = %+v, default = %q", cfg.Locations, cfg.DefaultLocation)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	null, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", os.DevNull, err)
+	}
+	defer null.Close()
+	r, w, _ := os.Pipe()
+	defer r.Close()
+	defer w.Close()
+
+	for _, f := range []*os.File{null, r} {
+		if isTerminal(f) {
+			t.Errorf("isTerminal(%s) = true, want false", f.Name())
+		}
+	}
+}
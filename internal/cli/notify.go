@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/notify"
+	"weather-cli/internal/weather"
+)
+
+// executeNotify sends the daily summary for a location to the chosen targets
+func executeNotify(args *ParsedArgs, cfg *config.Config) error {
+	for _, target := range args.Targets {
+		if err := config.CheckNotifyTarget(cfg, target); err != nil {
+			return err
+		}
+	}
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
+	loc, err := resolveLocation(args, location.NewManager(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to get location: %w", err)
+	}
+
+	data, err := weather.GetWeatherForecast(cfg, *loc)
+	if err != nil {
+		return fmt.Errorf("failed to fetch weather data: %w", err)
+	}
+	msg, err := notify.DailySummary(loc.Name, data, cfg.TemperatureUnit)
+	if err != nil {
+		return err
+	}
+	if err := notify.Send(context.Background(), cfg, args.Targets, msg); err != nil {
+		return err
+	}
+
+	var sent []string
+	for _, target := range args.Targets {
+		if target != config.NotifyStdout {
+			sent = append(sent, notify.RedactTarget(target))
+		}
+	}
+	if len(sent) > 0 {
+		fmt.Printf("Sent the daily summary for %s to %s.\n", loc.Name, strings.Join(sent, ", "))
+	}
+	return nil
+}
+
+// redactTargets returns a copy of targets with their webhook URLs redacted
+func redactTargets(targets []string) []string {
+	if targets == nil {
+		return nil
+	}
+	redacted := make([]string, len(targets))
+	for i, target := range targets {
+		redacted[i] = notify.RedactTarget(target)
+	}
+	return redacted
+}
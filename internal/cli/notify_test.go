@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather/weathertest"
+)
+
+func TestExecuteNotify(t *testing.T) {
+	weathertest.Serve(t)
+	var payload map[string]string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer receiver.Close()
+	// A webhook given by URL, whose path is its secret
+	direct := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer direct.Close()
+
+	cfg := &config.Config{
+		APIKey:          "test_api_key",
+		TemperatureUnit: "C",
+		Locations:       []config.Location{{Name: "office", Latitude: 35.6895, Longitude: 139.6917}},
+		Notifiers:       map[string]config.Notifier{"team": {Type: "slack", URL: receiver.URL}},
+	}
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	err := ExecuteCommand(&ParsedArgs{Command: CommandNotify, Location: "office", Targets: []string{"stdout", "team", direct.URL + "/hooks/secret"}}, cfg)
+	w.Close()
+	os.Stdout = oldStdout
+
+	if err != nil {
+		t.Fatalf("ExecuteCommand returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+	for _, expected := range []string{
+		"Weather for office on ",
+		"Clear sky, 12.0°C to 12.0°C\nChance of precipitation: 0%\n",
+		"Sent the daily summary for office to team, " + direct.URL + ".",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Output didn't contain expected string: %q\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "secret") {
+		t.Errorf("The webhook path was printed:\n%s", output)
+	}
+	if !strings.HasPrefix(payload["text"], "*Weather for office on ") || !strings.Contains(payload["text"], "*\nClear sky") {
+		t.Errorf("Slack payload = %v", payload)
+	}
+
+	// An unknown target fails before the forecast is fetched
+	err = ExecuteCommand(&ParsedArgs{Command: CommandNotify, Location: "office", Targets: []string{"pager"}}, cfg)
+	if err == nil || !strings.Contains(err.Error(), `unknown notification target "pager". Use stdout, desktop, team or a webhook URL`) {
+		t.Errorf("ExecuteCommand() error = %v", err)
+	}
+}
@@ -3,8 +3,12 @@ package cli
 import (
 	"errors"
 	"flag"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
+	"weather-cli/internal/location"
+	"weather-cli/internal/server"
 )
 
 // Command represents the different commands available in the CLI
@@ -19,6 +23,33 @@ const (
 	CommandListLocations
 	CommandHelp
 	CommandSetAPIKey
+	CommandTUI
+	CommandCompare
+	CommandSetDefaultLocation
+	CommandAddAliases
+	CommandRemoveAliases
+	CommandAddTags
+	CommandRemoveTags
+	CommandRenameLocation
+	CommandEditLocation
+	CommandExportLocations
+	CommandImportLocations
+	CommandNearestLocations
+	CommandLocationDistance
+	CommandShowConfig
+	CommandSetAPIKeySource
+	CommandConfigDoctor
+	CommandConvertConfig
+	CommandListProfiles
+	CommandUseProfile
+	CommandCreateProfile
+	CommandDeleteProfile
+	CommandInit
+	CommandCheckAlerts
+	CommandListAlerts
+	CommandNotify
+	CommandDaemon
+	CommandServe
 )
 
 // ParsedArgs holds the parsed command-line arguments
@@ -32,6 +63,36 @@ type ParsedArgs struct {
 	Interval  int
 	ShowHelp  bool
 	APIKey    string // New field for API key
+	Watch     time.Duration
+	Locations []string
+	All       bool
+	Here      bool
+	Group     string
+	Tag       string
+	Values    []string
+	NewName   string
+	Edit      location.LocationEdit
+	Format    string
+	Path      string
+	Import    location.ImportOptions
+	// Coordinates is set when a forecast is requested for raw coordinates instead of a name
+	Coordinates bool
+	Limit       int
+	Effective   bool
+	// SecretSource is where config api-key stores the API key: keyring, cmd or file
+	SecretSource string
+	SecretValue  string
+	// Base is the profile a new profile inherits from
+	Base string
+	Init InitOptions
+	// Targets are where notify sends the summary: stdout, desktop, a webhook URL or a notifier name
+	Targets []string
+	// Verbose makes the daemon log when each job is next due
+	Verbose bool
+	// Addr, Token and Timeout configure the HTTP API server
+	Addr    string
+	Token   string
+	Timeout time.Duration
 }
 
 // ParseArgs parses the command-line arguments and returns a ParsedArgs struct
@@ -41,6 +102,30 @@ func ParseArgs(args []string) (*ParsedArgs, error) {
 	}
 
 	parsed := &ParsedArgs{}
+
+	// Subcommands are selected by the first argument
+	switch args[1] {
+	case "tui":
+		parsed.Command = CommandTUI
+		return parsed, nil
+	case "compare":
+		return handleCompare(parsed, args[2:])
+	case "loc":
+		return handleLoc(parsed, args[2:])
+	case "config":
+		return handleConfig(parsed, args[2:])
+	case "init":
+		return handleInit(parsed, args[2:])
+	case "alerts":
+		return handleAlerts(parsed, args[2:])
+	case "notify":
+		return handleNotify(parsed, args[2:])
+	case "daemon":
+		return handleDaemon(parsed, args[2:])
+	case "serve":
+		return handleServe(parsed, args[2:])
+	}
+
 	flagSet := flag.NewFlagSet("weather", flag.ContinueOnError)
 
 	// Define flags
@@ -51,9 +136,14 @@ func ParseArgs(args []string) (*ParsedArgs, error) {
 	setInterval := flagSet.Int("interval", 0, "Set forecast interval in hours")
 	listLocations := flagSet.Bool("list", false, "List saved locations")
 	setAPIKey := flagSet.String("set-api-key", "", "Set the OpenWeather API key")
+	flagSet.DurationVar(&parsed.Watch, "watch", 0, "Refresh the forecast on an interval (e.g. 10m)")
+	flagSet.BoolVar(&parsed.All, "all", false, "Get weather for every saved location")
+	flagSet.BoolVar(&parsed.Here, "here", false, "Get weather for the current position")
+	flagSet.StringVar(&parsed.Group, "group", "", "Get weather for every location with a tag")
+	flagSet.StringVar(&parsed.Tag, "tag", "", "Only list locations with a tag")
 
 	// Parse flags
-	err := flagSet.Parse(args[1:])
+	positional, err := parseInterspersed(flagSet, args[1:])
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +151,7 @@ func ParseArgs(args []string) (*ParsedArgs, error) {
 	// Handle different commands
 	switch {
 	case *addLocation:
-		return handleAddLocation(parsed, flagSet.Args())
+		return handleAddLocation(parsed, positional)
 	case *removeLocation != "":
 		parsed.Command = CommandRemoveLocation
 		parsed.Name = *removeLocation
@@ -79,31 +169,72 @@ func ParseArgs(args []string) (*ParsedArgs, error) {
 		parsed.APIKey = *setAPIKey
 	default:
 		// If no flags are set, assume it's a get weather command
-		return handleGetWeather(parsed, flagSet.Args())
+		return handleGetWeather(parsed, positional)
 	}
 
 	return parsed, nil
 }
 
-func handleAddLocation(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
-	if len(args) != 3 {
-		return nil, errors.New("invalid arguments for adding location. Use: -i <latitude> <longitude> <name>")
+// parseInterspersed parses flags that appear before or after positional arguments,
+// so that "weather tokyo --watch 10m" works as well as "weather --watch 10m tokyo"
+func parseInterspersed(flagSet *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		if err := flagSet.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flagSet.Args()
+
+		// Collect positional arguments up to the next flag
+		for len(args) > 0 && !isFlag(args[0]) {
+			positional = append(positional, args[0])
+			args = args[1:]
+		}
 	}
+	return positional, nil
+}
 
-	lat, err := strconv.ParseFloat(args[0], 64)
-	if err != nil {
-		return nil, errors.New("invalid latitude")
+// isFlag reports whether an argument is a flag rather than a value such as a negative coordinate
+func isFlag(arg string) bool {
+	if len(arg) < 2 || arg[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(arg, 64)
+	return err != nil
+}
+
+func handleAddLocation(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	var lat, lon float64
+	var err error
+
+	switch {
+	case len(args) == 2 && strings.HasPrefix(args[0], "geo:"):
+		lat, lon, err = location.ParseGeoURI(args[0])
+		if err != nil {
+			return nil, err
+		}
+	case len(args) == 3:
+		lat, err = location.ParseLatitude(args[0])
+		if err != nil {
+			return nil, err
+		}
+		lon, err = location.ParseLongitude(args[1])
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("invalid arguments for adding location. Use: -i <latitude> <longitude> <name> or -i geo:<latitude>,<longitude> <name>")
 	}
 
-	lon, err := strconv.ParseFloat(args[1], 64)
+	lat, lon, err = location.NormalizeCoordinates(lat, lon)
 	if err != nil {
-		return nil, errors.New("invalid longitude")
+		return nil, err
 	}
 
 	parsed.Command = CommandAddLocation
 	parsed.Latitude = lat
 	parsed.Longitude = lon
-	parsed.Name = args[2]
+	parsed.Name = args[len(args)-1]
 
 	return parsed, nil
 }
@@ -121,12 +252,549 @@ func handleSetUnit(parsed *ParsedArgs, unit string) (*ParsedArgs, error) {
 }
 
 func handleGetWeather(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
-	if len(args) == 0 {
-		return nil, errors.New("location is required for getting weather")
+	// Raw coordinates are looked up directly rather than as a saved location
+	lat, lon, coordinates, err := parseCoordinateArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a location, the default location is used when the command runs
+	if parsed.Here && (parsed.All || len(args) > 0) {
+		return nil, errors.New("--here cannot be combined with other locations")
+	}
+
+	if parsed.Group != "" && (parsed.All || parsed.Here || len(args) > 0) {
+		return nil, errors.New("--group cannot be combined with other locations")
+	}
+
+	if parsed.Watch < 0 {
+		return nil, errors.New("invalid watch interval. Must be a positive duration")
+	}
+
+	// Several arguments may still be one name, which is checked when the command runs
+	if parsed.Watch > 0 && (parsed.All || parsed.Group != "") {
+		return nil, errors.New("--watch supports a single location")
 	}
 
 	parsed.Command = CommandGetWeather
+
+	if coordinates {
+		if parsed.All {
+			return nil, errors.New("coordinates cannot be combined with other locations")
+		}
+		parsed.Coordinates = true
+		parsed.Latitude = lat
+		parsed.Longitude = lon
+		return parsed, nil
+	}
+
 	parsed.Location = strings.Join(args, " ")
 
+	// Several arguments may be one name containing spaces or several locations;
+	// which one is decided against the saved locations when the command runs
+	if len(args) > 1 {
+		parsed.Locations = args
+	}
+
+	return parsed, nil
+}
+
+func handleCompare(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	if len(args) < 2 {
+		return nil, errors.New("at least two locations are required. Use: compare <location> <location>...")
+	}
+
+	parsed.Command = CommandCompare
+	parsed.Locations = args
+
+	return parsed, nil
+}
+
+func handleLoc(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	if len(args) == 0 {
+		return nil, errors.New("location subcommand is required. Use: loc default <name>")
+	}
+
+	switch args[0] {
+	case "default":
+		if len(args) != 2 {
+			return nil, errors.New("invalid arguments for default location. Use: loc default <name>")
+		}
+		parsed.Command = CommandSetDefaultLocation
+		parsed.Name = args[1]
+	case "alias", "unalias", "tag", "untag":
+		if len(args) < 3 {
+			return nil, fmt.Errorf("invalid arguments for %s. Use: loc %s <name> <%s>...", args[0], args[0], strings.TrimPrefix(args[0], "un"))
+		}
+		parsed.Command = map[string]Command{
+			"alias":   CommandAddAliases,
+			"unalias": CommandRemoveAliases,
+			"tag":     CommandAddTags,
+			"untag":   CommandRemoveTags,
+		}[args[0]]
+		parsed.Name = args[1]
+		parsed.Values = args[2:]
+	case "rename":
+		if len(args) != 3 {
+			return nil, errors.New("invalid arguments for rename. Use: loc rename <old> <new>")
+		}
+		parsed.Command = CommandRenameLocation
+		parsed.Name = args[1]
+		parsed.NewName = args[2]
+	case "edit":
+		return handleEditLocation(parsed, args[1:])
+	case "export":
+		return handleExportLocations(parsed, args[1:])
+	case "import":
+		return handleImportLocations(parsed, args[1:])
+	case "nearest":
+		return handleNearestLocations(parsed, args[1:])
+	case "distance":
+		if len(args) != 3 {
+			return nil, errors.New("invalid arguments for distance. Use: loc distance <location> <location>")
+		}
+		parsed.Command = CommandLocationDistance
+		parsed.Locations = args[1:]
+	default:
+		return nil, fmt.Errorf("unknown location subcommand '%s'", args[0])
+	}
+
+	return parsed, nil
+}
+
+func handleEditLocation(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("loc edit", flag.ContinueOnError)
+	lat := flagSet.String("lat", "", "New latitude")
+	lon := flagSet.String("lon", "", "New longitude")
+	tz := flagSet.String("tz", "", "IANA timezone, e.g. Asia/Tokyo")
+	alias := flagSet.String("alias", "", "Comma-separated aliases, replacing the current ones")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != 1 {
+		return nil, errors.New("invalid arguments for edit. Use: loc edit <name> [--lat <latitude>] [--lon <longitude>] [--tz <timezone>] [--alias <alias,...>]")
+	}
+
+	edit := location.LocationEdit{}
+	var parseErr error
+	flagSet.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "lat":
+			v, err := strconv.ParseFloat(*lat, 64)
+			if err != nil {
+				parseErr = errors.New("invalid latitude")
+			}
+			edit.Latitude = &v
+		case "lon":
+			v, err := strconv.ParseFloat(*lon, 64)
+			if err != nil {
+				parseErr = errors.New("invalid longitude")
+			}
+			edit.Longitude = &v
+		case "tz":
+			edit.Timezone = tz
+		case "alias":
+			aliases := []string{}
+			for _, a := range strings.Split(*alias, ",") {
+				if a = strings.TrimSpace(a); a != "" {
+					aliases = append(aliases, a)
+				}
+			}
+			edit.Aliases = &aliases
+		}
+	})
+	if parseErr != nil {
+		return nil, parseErr
+	}
+	if edit == (location.LocationEdit{}) {
+		return nil, errors.New("nothing to edit. Use --lat, --lon, --tz or --alias")
+	}
+
+	parsed.Command = CommandEditLocation
+	parsed.Name = positional[0]
+	parsed.Edit = edit
+
+	return parsed, nil
+}
+
+func handleExportLocations(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("loc export", flag.ContinueOnError)
+	flagSet.StringVar(&parsed.Format, "format", "", "Export format: geojson, gpx, kml or csv")
+	flagSet.StringVar(&parsed.Path, "output", "", "File to write instead of standard output")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != 0 {
+		return nil, errors.New("invalid arguments for export. Use: loc export [--format geojson|gpx|kml|csv] [--output <file>]")
+	}
+
+	// Without --format the format follows the output file, defaulting to GeoJSON
+	if parsed.Format == "" {
+		parsed.Format = location.FormatGeoJSON
+		if parsed.Path != "" {
+			if parsed.Format, err = location.FormatFromPath(parsed.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := checkFormat(parsed.Format); err != nil {
+		return nil, err
+	}
+
+	parsed.Command = CommandExportLocations
+	return parsed, nil
+}
+
+func handleImportLocations(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("loc import", flag.ContinueOnError)
+	flagSet.StringVar(&parsed.Format, "format", "", "Import format: geojson, gpx, kml or csv")
+	flagSet.BoolVar(&parsed.Import.DryRun, "dry-run", false, "Show what would change without saving")
+	flagSet.BoolVar(&parsed.Import.Replace, "replace", false, "Replace the saved locations instead of merging")
+	flagSet.StringVar(&parsed.Import.OnConflict, "on-conflict", location.ConflictSkip, "What to do with names that are already saved: skip, overwrite or rename")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != 1 {
+		return nil, errors.New("invalid arguments for import. Use: loc import <file> [--format geojson|gpx|kml|csv] [--dry-run] [--replace] [--on-conflict skip|overwrite|rename]")
+	}
+
+	switch parsed.Import.OnConflict {
+	case location.ConflictSkip, location.ConflictOverwrite, location.ConflictRename:
+	default:
+		return nil, errors.New("invalid conflict policy. Use skip, overwrite or rename")
+	}
+
+	parsed.Path = positional[0]
+	if parsed.Format == "" {
+		if parsed.Format, err = location.FormatFromPath(parsed.Path); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkFormat(parsed.Format); err != nil {
+		return nil, err
+	}
+
+	parsed.Command = CommandImportLocations
+	return parsed, nil
+}
+
+// checkFormat verifies that a location exchange format is supported
+func checkFormat(format string) error {
+	switch format {
+	case location.FormatGeoJSON, location.FormatGPX, location.FormatKML, location.FormatCSV:
+		return nil
+	}
+	return fmt.Errorf("unsupported format '%s'. Use geojson, gpx, kml or csv", format)
+}
+
+func handleNearestLocations(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("loc nearest", flag.ContinueOnError)
+	flagSet.IntVar(&parsed.Limit, "limit", 5, "Number of locations to show")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	lat, lon, ok, err := parseCoordinateArgs(positional)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("invalid arguments for nearest. Use: loc nearest <latitude> <longitude> [--limit <n>] or loc nearest geo:<latitude>,<longitude>")
+	}
+	if parsed.Limit <= 0 {
+		return nil, errors.New("invalid limit. Must be a positive number")
+	}
+
+	parsed.Command = CommandNearestLocations
+	parsed.Latitude = lat
+	parsed.Longitude = lon
+
+	return parsed, nil
+}
+
+// parseCoordinateArgs recognizes "<latitude> <longitude>" or a single geo: URI.
+// ok is false when the arguments do not look like coordinates at all, and err is set
+// when they do but are out of range.
+func parseCoordinateArgs(args []string) (lat, lon float64, ok bool, err error) {
+	switch {
+	case len(args) == 1 && strings.HasPrefix(args[0], "geo:"):
+		lat, lon, err = location.ParseGeoURI(args[0])
+		if err != nil {
+			return 0, 0, true, err
+		}
+	case len(args) == 2:
+		var latErr, lonErr error
+		lat, latErr = strconv.ParseFloat(args[0], 64)
+		lon, lonErr = strconv.ParseFloat(args[1], 64)
+		if latErr != nil || lonErr != nil {
+			return 0, 0, false, nil
+		}
+	default:
+		return 0, 0, false, nil
+	}
+
+	lat, lon, err = location.NormalizeCoordinates(lat, lon)
+	return lat, lon, true, err
+}
+
+func handleInit(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("init", flag.ContinueOnError)
+	flagSet.BoolVar(&parsed.Init.NonInteractive, "non-interactive", false, "Take every answer from flags instead of prompting")
+	flagSet.StringVar(&parsed.Init.Provider, "provider", "openweather", "Weather provider")
+	flagSet.StringVar(&parsed.Init.APIKey, "api-key", "", "OpenWeather API key")
+	flagSet.StringVar(&parsed.Init.Unit, "unit", "", "Temperature unit (C or F)")
+	flagSet.StringVar(&parsed.Init.Language, "lang", "", "Language of weather descriptions, e.g. en or de")
+	flagSet.StringVar(&parsed.Init.Location, "location", "", "Place to search for and save as the default location")
+	flagSet.BoolVar(&parsed.Init.NoVerify, "no-verify", false, "Save the API key without checking it")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != 0 {
+		return nil, errors.New("invalid arguments for init. Use: init [--non-interactive] [--provider <name>] [--api-key <key>] [--unit C|F] [--lang <code>] [--location <place>] [--no-verify]")
+	}
+	if parsed.Init.Unit != "" {
+		parsed.Init.Unit = strings.ToUpper(parsed.Init.Unit)
+		if parsed.Init.Unit != "C" && parsed.Init.Unit != "F" {
+			return nil, errors.New("invalid temperature unit. Use C or F")
+		}
+	}
+
+	parsed.Command = CommandInit
+	return parsed, nil
+}
+
+func handleAlerts(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	if len(args) != 1 {
+		return nil, errors.New("invalid arguments for alerts. Use: alerts check or alerts list")
+	}
+	switch args[0] {
+	case "check":
+		parsed.Command = CommandCheckAlerts
+	case "list":
+		parsed.Command = CommandListAlerts
+	default:
+		return nil, fmt.Errorf("unknown alerts subcommand '%s'", args[0])
+	}
+	return parsed, nil
+}
+
+func handleNotify(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("notify", flag.ContinueOnError)
+	to := flagSet.String("to", "stdout", "Comma-separated targets to send the summary to")
+	flagSet.BoolVar(&parsed.Here, "here", false, "Summarize the weather at the current position")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) > 1 || (parsed.Here && len(positional) != 0) {
+		return nil, errors.New("invalid arguments for notify. Use: notify [<location> | --here] [--to <target>[,<target>...]]")
+	}
+	if len(positional) == 1 {
+		parsed.Location = positional[0]
+	}
+	for _, target := range strings.Split(*to, ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			parsed.Targets = append(parsed.Targets, target)
+		}
+	}
+	if len(parsed.Targets) == 0 {
+		return nil, errors.New("--to needs at least one target")
+	}
+
+	parsed.Command = CommandNotify
+	return parsed, nil
+}
+
+func handleDaemon(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	flagSet.StringVar(&parsed.Format, "log-format", "text", "Log format: text or json")
+	flagSet.BoolVar(&parsed.Verbose, "verbose", false, "Also log when each job is next due")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != 0 {
+		return nil, errors.New("invalid arguments for daemon. Use: daemon [--log-format text|json] [--verbose]")
+	}
+	if parsed.Format != "text" && parsed.Format != "json" {
+		return nil, fmt.Errorf("invalid log format %q. Use text or json", parsed.Format)
+	}
+
+	parsed.Command = CommandDaemon
+	return parsed, nil
+}
+
+func handleServe(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flagSet.StringVar(&parsed.Addr, "addr", DefaultServeAddr, "Address to listen on")
+	flagSet.StringVar(&parsed.Token, "token", "", "Bearer token clients must send (or set "+ServeTokenEnvVar+")")
+	flagSet.DurationVar(&parsed.Timeout, "timeout", server.DefaultTimeout, "How long a request may take")
+	flagSet.StringVar(&parsed.Format, "log-format", "text", "Log format: text or json")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(positional) != 0 {
+		return nil, errors.New("invalid arguments for serve. Use: serve [--addr <host:port>] [--token <token>] [--timeout <duration>] [--log-format text|json]")
+	}
+	if parsed.Timeout <= 0 {
+		return nil, errors.New("--timeout must be positive")
+	}
+	if parsed.Format != "text" && parsed.Format != "json" {
+		return nil, fmt.Errorf("invalid log format %q. Use text or json", parsed.Format)
+	}
+
+	parsed.Command = CommandServe
+	return parsed, nil
+}
+
+func handleConfig(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	if len(args) == 0 {
+		return nil, errors.New("config subcommand is required. Use: config show [--effective], config api-key, config doctor, config convert --to <format> or config profile")
+	}
+
+	switch args[0] {
+	case "show":
+		flagSet := flag.NewFlagSet("config show", flag.ContinueOnError)
+		flagSet.BoolVar(&parsed.Effective, "effective", false, "Show resolved values and the layer each came from")
+		positional, err := parseInterspersed(flagSet, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 {
+			return nil, errors.New("invalid arguments for show. Use: config show [--effective]")
+		}
+		parsed.Command = CommandShowConfig
+	case "api-key":
+		return handleAPIKeySource(parsed, args[1:])
+	case "doctor":
+		if len(args) != 1 {
+			return nil, errors.New("invalid arguments for doctor. Use: config doctor")
+		}
+		parsed.Command = CommandConfigDoctor
+	case "convert":
+		flagSet := flag.NewFlagSet("config convert", flag.ContinueOnError)
+		flagSet.StringVar(&parsed.Format, "to", "", "Format to convert the config file to: json, yaml or toml")
+		positional, err := parseInterspersed(flagSet, args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if len(positional) != 0 || parsed.Format == "" {
+			return nil, errors.New("invalid arguments for convert. Use: config convert --to json|yaml|toml")
+		}
+		switch parsed.Format = strings.ToLower(parsed.Format); parsed.Format {
+		case "json", "yaml", "toml":
+		case "yml":
+			parsed.Format = "yaml"
+		default:
+			return nil, fmt.Errorf("unsupported config format '%s'. Use json, yaml or toml", parsed.Format)
+		}
+		parsed.Command = CommandConvertConfig
+	case "profile":
+		return handleProfile(parsed, args[1:])
+	default:
+		return nil, fmt.Errorf("unknown config subcommand '%s'", args[0])
+	}
+
+	return parsed, nil
+}
+
+func handleProfile(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	usage := errors.New("invalid arguments for profile. Use: config profile list, config profile use <name>, config profile create <name> [--base <profile>] or config profile delete <name>")
+	if len(args) == 0 {
+		return nil, usage
+	}
+
+	flagSet := flag.NewFlagSet("config profile "+args[0], flag.ContinueOnError)
+	if args[0] == "create" {
+		flagSet.StringVar(&parsed.Base, "base", "", "Profile to inherit settings from")
+	}
+	positional, err := parseInterspersed(flagSet, args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch args[0] {
+	case "list":
+		if len(positional) != 0 {
+			return nil, usage
+		}
+		parsed.Command = CommandListProfiles
+		return parsed, nil
+	case "use":
+		parsed.Command = CommandUseProfile
+	case "create":
+		parsed.Command = CommandCreateProfile
+	case "delete":
+		parsed.Command = CommandDeleteProfile
+	default:
+		return nil, fmt.Errorf("unknown profile subcommand '%s'", args[0])
+	}
+	if len(positional) != 1 {
+		return nil, usage
+	}
+	parsed.Name = positional[0]
+	return parsed, nil
+}
+
+func handleAPIKeySource(parsed *ParsedArgs, args []string) (*ParsedArgs, error) {
+	flagSet := flag.NewFlagSet("config api-key", flag.ContinueOnError)
+	keyring := flagSet.Bool("keyring", false, "Store the API key in the system keyring")
+	command := flagSet.String("cmd", "", "Read the API key from the output of a command")
+	file := flagSet.String("file", "", "Read the API key from a file")
+
+	positional, err := parseInterspersed(flagSet, args)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := errors.New("invalid arguments for api-key. Use: config api-key --keyring [<api_key>], config api-key --cmd <command> or config api-key --file <path>")
+	sources := 0
+	for _, set := range []bool{*keyring, *command != "", *file != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, usage
+	}
+
+	switch {
+	case *keyring:
+		// Without an argument the key is read from standard input, keeping it out of shell history
+		if len(positional) > 1 {
+			return nil, usage
+		}
+		parsed.SecretSource = "keyring"
+		if len(positional) == 1 {
+			parsed.APIKey = positional[0]
+		}
+	case *command != "":
+		if len(positional) != 0 {
+			return nil, usage
+		}
+		parsed.SecretSource = "cmd"
+		parsed.SecretValue = *command
+	default:
+		if len(positional) != 0 {
+			return nil, usage
+		}
+		parsed.SecretSource = "file"
+		parsed.SecretValue = *file
+	}
+
+	parsed.Command = CommandSetAPIKeySource
 	return parsed, nil
 }
@@ -3,8 +3,14 @@ package cli
 import (
 	"reflect"
 	"testing"
+	"time"
+	"weather-cli/internal/location"
 )
 
+func floatPtr(v float64) *float64 { return &v }
+
+func stringPtr(s string) *string { return &s }
+
 func TestParseArgs(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -109,6 +115,582 @@ func TestParseArgs(t *testing.T) {
 			want:    &ParsedArgs{Command: CommandHelp},
 			wantErr: false,
 		},
+		{
+			name: "Watch location",
+			args: []string{"weather", "Tokyo", "--watch", "10m"},
+			want: &ParsedArgs{
+				Command:  CommandGetWeather,
+				Location: "Tokyo",
+				Watch:    10 * time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Add location with negative longitude",
+			args: []string{"weather", "-i", "40.7128", "-74.0060", "New York"},
+			want: &ParsedArgs{
+				Command:   CommandAddLocation,
+				Latitude:  40.7128,
+				Longitude: -74.0060,
+				Name:      "New York",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Invalid watch interval",
+			args:    []string{"weather", "Tokyo", "--watch", "soon"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Dashboard",
+			args: []string{"weather", "tui"},
+			want: &ParsedArgs{
+				Command: CommandTUI,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Multiple locations",
+			args: []string{"weather", "tokyo", "osaka", "london"},
+			want: &ParsedArgs{
+				Command:   CommandGetWeather,
+				Location:  "tokyo osaka london",
+				Locations: []string{"tokyo", "osaka", "london"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "All locations",
+			args: []string{"weather", "--all"},
+			want: &ParsedArgs{
+				Command: CommandGetWeather,
+				All:     true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Watch name with spaces",
+			args: []string{"weather", "new", "york", "--watch", "1m"},
+			want: &ParsedArgs{
+				Command:   CommandGetWeather,
+				Location:  "new york",
+				Locations: []string{"new", "york"},
+				Watch:     time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Watch all locations",
+			args:    []string{"weather", "--all", "--watch", "1m"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Compare locations",
+			args: []string{"weather", "compare", "tokyo", "berlin", "sf"},
+			want: &ParsedArgs{
+				Command:   CommandCompare,
+				Locations: []string{"tokyo", "berlin", "sf"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Compare single location",
+			args:    []string{"weather", "compare", "tokyo"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Set default location",
+			args: []string{"weather", "loc", "default", "tokyo"},
+			want: &ParsedArgs{
+				Command: CommandSetDefaultLocation,
+				Name:    "tokyo",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Unknown location subcommand",
+			args:    []string{"weather", "loc", "move", "tokyo"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Current position",
+			args: []string{"weather", "--here"},
+			want: &ParsedArgs{
+				Command: CommandGetWeather,
+				Here:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Watch default location",
+			args: []string{"weather", "--watch", "5m"},
+			want: &ParsedArgs{
+				Command: CommandGetWeather,
+				Watch:   5 * time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Current position with a location",
+			args:    []string{"weather", "--here", "tokyo"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Add aliases",
+			args: []string{"weather", "loc", "alias", "tokyo", "hq", "tyo"},
+			want: &ParsedArgs{
+				Command: CommandAddAliases,
+				Name:    "tokyo",
+				Values:  []string{"hq", "tyo"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Add tags without tags",
+			args:    []string{"weather", "loc", "tag", "tokyo"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Group",
+			args: []string{"weather", "--group", "offices"},
+			want: &ParsedArgs{
+				Command: CommandGetWeather,
+				Group:   "offices",
+			},
+			wantErr: false,
+		},
+		{
+			name: "List locations by tag",
+			args: []string{"weather", "--list", "--tag", "offices"},
+			want: &ParsedArgs{
+				Command: CommandListLocations,
+				Tag:     "offices",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Rename location",
+			args: []string{"weather", "loc", "rename", "tokyo", "Tokyo HQ"},
+			want: &ParsedArgs{
+				Command: CommandRenameLocation,
+				Name:    "tokyo",
+				NewName: "Tokyo HQ",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Edit location",
+			args: []string{"weather", "loc", "edit", "tokyo", "--lon", "-139.5", "--tz", "Asia/Tokyo", "--alias", "hq, tyo"},
+			want: &ParsedArgs{
+				Command: CommandEditLocation,
+				Name:    "tokyo",
+				Edit: location.LocationEdit{
+					Longitude: floatPtr(-139.5),
+					Timezone:  stringPtr("Asia/Tokyo"),
+					Aliases:   &[]string{"hq", "tyo"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Edit location without changes",
+			args:    []string{"weather", "loc", "edit", "tokyo"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Edit location with invalid latitude",
+			args:    []string{"weather", "loc", "edit", "tokyo", "--lat", "north"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Add location with DMS coordinates",
+			args: []string{"weather", "-i", `35°30'N`, `139°45'E`, "Tokyo"},
+			want: &ParsedArgs{
+				Command:   CommandAddLocation,
+				Latitude:  35.5,
+				Longitude: 139.75,
+				Name:      "Tokyo",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Add location from geo URI",
+			args: []string{"weather", "-i", "geo:35.6895,139.6917;u=30", "Tokyo"},
+			want: &ParsedArgs{
+				Command:   CommandAddLocation,
+				Latitude:  35.6895,
+				Longitude: 139.6917,
+				Name:      "Tokyo",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Add location with wrapped longitude",
+			args: []string{"weather", "-i", "0", "190", "Pacific"},
+			want: &ParsedArgs{
+				Command:   CommandAddLocation,
+				Latitude:  0,
+				Longitude: -170,
+				Name:      "Pacific",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Add location with out-of-range latitude",
+			args:    []string{"weather", "-i", "200", "0", "Nowhere"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Add location with NaN latitude",
+			args:    []string{"weather", "-i", "NaN", "0", "Nowhere"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Export locations to stdout",
+			args: []string{"weather", "loc", "export"},
+			want: &ParsedArgs{
+				Command: CommandExportLocations,
+				Format:  location.FormatGeoJSON,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Export locations with format from output file",
+			args: []string{"weather", "loc", "export", "--output", "places.kml"},
+			want: &ParsedArgs{
+				Command: CommandExportLocations,
+				Format:  location.FormatKML,
+				Path:    "places.kml",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Export locations with unknown format",
+			args:    []string{"weather", "loc", "export", "--format", "shp"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Import locations",
+			args: []string{"weather", "loc", "import", "places.csv", "--dry-run", "--on-conflict", "rename"},
+			want: &ParsedArgs{
+				Command: CommandImportLocations,
+				Format:  location.FormatCSV,
+				Path:    "places.csv",
+				Import:  location.ImportOptions{DryRun: true, OnConflict: location.ConflictRename},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Import locations with explicit format",
+			args: []string{"weather", "loc", "import", "--format", "gpx", "--replace", "track.xml"},
+			want: &ParsedArgs{
+				Command: CommandImportLocations,
+				Format:  location.FormatGPX,
+				Path:    "track.xml",
+				Import:  location.ImportOptions{Replace: true, OnConflict: location.ConflictSkip},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Import locations with unknown extension",
+			args:    []string{"weather", "loc", "import", "places.txt"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Import locations with invalid conflict policy",
+			args:    []string{"weather", "loc", "import", "places.csv", "--on-conflict", "merge"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Get weather for coordinates",
+			args: []string{"weather", "35.6895", "-139.6917"},
+			want: &ParsedArgs{
+				Command:     CommandGetWeather,
+				Coordinates: true,
+				Latitude:    35.6895,
+				Longitude:   -139.6917,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Watch weather for a geo URI",
+			args: []string{"weather", "geo:35.6895,139.6917", "--watch", "10m"},
+			want: &ParsedArgs{
+				Command:     CommandGetWeather,
+				Coordinates: true,
+				Latitude:    35.6895,
+				Longitude:   139.6917,
+				Watch:       10 * time.Minute,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Get weather for out-of-range coordinates",
+			args:    []string{"weather", "95", "0"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Nearest locations",
+			args: []string{"weather", "loc", "nearest", "50.85", "4.35", "--limit", "3"},
+			want: &ParsedArgs{
+				Command:   CommandNearestLocations,
+				Latitude:  50.85,
+				Longitude: 4.35,
+				Limit:     3,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Nearest locations without coordinates",
+			args:    []string{"weather", "loc", "nearest", "Brussels"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Distance between locations",
+			args: []string{"weather", "loc", "distance", "London", "New York"},
+			want: &ParsedArgs{
+				Command:   CommandLocationDistance,
+				Locations: []string{"London", "New York"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Distance with one location",
+			args:    []string{"weather", "loc", "distance", "London"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Show config",
+			args: []string{"weather", "config", "show"},
+			want: &ParsedArgs{
+				Command: CommandShowConfig,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Show effective config",
+			args: []string{"weather", "config", "show", "--effective"},
+			want: &ParsedArgs{
+				Command:   CommandShowConfig,
+				Effective: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Config doctor",
+			args: []string{"weather", "config", "doctor"},
+			want: &ParsedArgs{
+				Command: CommandConfigDoctor,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Config doctor with arguments",
+			args:    []string{"weather", "config", "doctor", "now"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Convert config to YAML",
+			args: []string{"weather", "config", "convert", "--to", "YML"},
+			want: &ParsedArgs{
+				Command: CommandConvertConfig,
+				Format:  "yaml",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Convert config without a format",
+			args:    []string{"weather", "config", "convert"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Convert config to an unknown format",
+			args:    []string{"weather", "config", "convert", "--to", "ini"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Init with answers as flags",
+			args: []string{"weather", "init", "--non-interactive", "--api-key", "abc123", "--unit", "f", "--location", "Tokyo"},
+			want: &ParsedArgs{
+				Command: CommandInit,
+				Init:    InitOptions{NonInteractive: true, Provider: "openweather", APIKey: "abc123", Unit: "F", Location: "Tokyo"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Init with an invalid unit",
+			args:    []string{"weather", "init", "--unit", "K"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Check alerts",
+			args:    []string{"weather", "alerts", "check"},
+			want:    &ParsedArgs{Command: CommandCheckAlerts},
+			wantErr: false,
+		},
+		{
+			name:    "List alerts",
+			args:    []string{"weather", "alerts", "list"},
+			want:    &ParsedArgs{Command: CommandListAlerts},
+			wantErr: false,
+		},
+		{
+			name:    "Unknown alerts subcommand",
+			args:    []string{"weather", "alerts", "mute"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Notify the default location",
+			args:    []string{"weather", "notify"},
+			want:    &ParsedArgs{Command: CommandNotify, Targets: []string{"stdout"}},
+			wantErr: false,
+		},
+		{
+			name:    "Notify several targets",
+			args:    []string{"weather", "notify", "office", "--to", "team, mail"},
+			want:    &ParsedArgs{Command: CommandNotify, Location: "office", Targets: []string{"team", "mail"}},
+			wantErr: false,
+		},
+		{
+			name:    "Notify without targets",
+			args:    []string{"weather", "notify", "office", "--to", ","},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Daemon",
+			args:    []string{"weather", "daemon"},
+			want:    &ParsedArgs{Command: CommandDaemon, Format: "text"},
+			wantErr: false,
+		},
+		{
+			name:    "Daemon with JSON logs",
+			args:    []string{"weather", "daemon", "--log-format", "json", "--verbose"},
+			want:    &ParsedArgs{Command: CommandDaemon, Format: "json", Verbose: true},
+			wantErr: false,
+		},
+		{
+			name:    "Daemon with an unknown log format",
+			args:    []string{"weather", "daemon", "--log-format", "xml"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Serve",
+			args:    []string{"weather", "serve"},
+			want:    &ParsedArgs{Command: CommandServe, Addr: "localhost:8080", Timeout: 30 * time.Second, Format: "text"},
+			wantErr: false,
+		},
+		{
+			name:    "Serve on all interfaces with a token",
+			args:    []string{"weather", "serve", "--addr", ":8080", "--token", "s3cret", "--timeout", "10s", "--log-format", "json"},
+			want:    &ParsedArgs{Command: CommandServe, Addr: ":8080", Token: "s3cret", Timeout: 10 * time.Second, Format: "json"},
+			wantErr: false,
+		},
+		{
+			name:    "Serve with a zero timeout",
+			args:    []string{"weather", "serve", "--timeout", "0s"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "List profiles",
+			args: []string{"weather", "config", "profile", "list"},
+			want: &ParsedArgs{
+				Command: CommandListProfiles,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Create profile with a base",
+			args: []string{"weather", "config", "profile", "create", "--base", "home", "travel"},
+			want: &ParsedArgs{
+				Command: CommandCreateProfile,
+				Name:    "travel",
+				Base:    "home",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Use profile",
+			args: []string{"weather", "config", "profile", "use", "work"},
+			want: &ParsedArgs{
+				Command: CommandUseProfile,
+				Name:    "work",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Delete profile without a name",
+			args:    []string{"weather", "config", "profile", "delete"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Base is only accepted by create",
+			args:    []string{"weather", "config", "profile", "use", "work", "--base", "home"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "Unknown config subcommand",
+			args:    []string{"weather", "config", "edit"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "Store API key in keyring",
+			args: []string{"weather", "config", "api-key", "--keyring", "abc123"},
+			want: &ParsedArgs{
+				Command:      CommandSetAPIKeySource,
+				SecretSource: "keyring",
+				APIKey:       "abc123",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Read API key from a command",
+			args: []string{"weather", "config", "api-key", "--cmd", "pass show owm"},
+			want: &ParsedArgs{
+				Command:      CommandSetAPIKeySource,
+				SecretSource: "cmd",
+				SecretValue:  "pass show owm",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "API key with two sources",
+			args:    []string{"weather", "config", "api-key", "--keyring", "--file", "key.txt"},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "API key without a source",
+			args:    []string{"weather", "config", "api-key"},
+			want:    nil,
+			wantErr: true,
+		},
 		// New test case for setting API key
 		{
 			name: "Set API key",
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"weather-cli/internal/config"
+	"weather-cli/internal/server"
+)
+
+// DefaultServeAddr is where weather serve listens unless --addr is given
+const DefaultServeAddr = "localhost:8080"
+
+// ServeTokenEnvVar holds the bearer token of weather serve when --token is not given,
+// keeping it out of the process list
+const ServeTokenEnvVar = "WEATHER_CLI_SERVE_TOKEN"
+
+// executeServe answers HTTP API requests until SIGINT or SIGTERM, logging to
+// standard error
+func executeServe(args *ParsedArgs, cfg *config.Config) error {
+	if err := config.ResolveAPIKey(cfg); err != nil {
+		return err
+	}
+	token := args.Token
+	if token == "" {
+		token = os.Getenv(ServeTokenEnvVar)
+	}
+	logger := newLogger(args)
+
+	listener, err := net.Listen("tcp", args.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", args.Addr, err)
+	}
+	if token == "" && !isLoopback(listener.Addr()) {
+		logger.Warn("serving without a bearer token; anyone who can reach the server can use the API key and change the saved locations",
+			"addr", listener.Addr().String())
+	}
+
+	ctx, stop := shutdownContext()
+	defer stop()
+	return server.New(cfg, server.Options{Token: token, Timeout: args.Timeout, Logger: logger}).Serve(ctx, listener)
+}
+
+// isLoopback reports whether addr only accepts connections from this machine
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
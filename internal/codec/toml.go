@@ -0,0 +1,667 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TOMLToJSON converts a TOML document to JSON. Dates and times are not supported.
+func TOMLToJSON(data []byte) ([]byte, error) {
+	value, err := parseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	return writeJSON(value)
+}
+
+// JSONToTOML converts a JSON object to TOML, keeping the key order where TOML allows
+// it: plain values of a table come before its sub-tables. Null values are left out,
+// as TOML has no null.
+func JSONToTOML(data []byte) ([]byte, error) {
+	value, err := parseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	root, ok := value.(object)
+	if !ok {
+		return nil, fmt.Errorf("a TOML document must be an object")
+	}
+
+	var b bytes.Buffer
+	if err := writeTOMLTable(&b, nil, root); err != nil {
+		return nil, err
+	}
+	return bytes.TrimPrefix(b.Bytes(), []byte("\n")), nil
+}
+
+// tomlTable is a table under construction. Tables defined by a header or by dotted
+// keys cannot be defined again, but implicit parents of a header can.
+type tomlTable struct {
+	keys     []string
+	values   map[string]any
+	defined  bool
+	implicit bool
+}
+
+// tomlArray is an array of tables, built up by [[header]] sections
+type tomlArray struct {
+	tables []*tomlTable
+}
+
+func newTOMLTable() *tomlTable {
+	return &tomlTable{values: make(map[string]any)}
+}
+
+func (t *tomlTable) set(key string, value any) {
+	t.keys = append(t.keys, key)
+	t.values[key] = value
+}
+
+// tree converts the table into an ordered object
+func (t *tomlTable) tree() object {
+	obj := make(object, 0, len(t.keys))
+	for _, key := range t.keys {
+		obj = append(obj, member{key: key, value: tomlTree(t.values[key])})
+	}
+	return obj
+}
+
+func tomlTree(value any) any {
+	switch v := value.(type) {
+	case *tomlTable:
+		return v.tree()
+	case *tomlArray:
+		items := make([]any, len(v.tables))
+		for i, t := range v.tables {
+			items[i] = t.tree()
+		}
+		return items
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = tomlTree(item)
+		}
+		return items
+	}
+	return value
+}
+
+type tomlParser struct {
+	s    string
+	i    int
+	line int
+}
+
+// parseTOML decodes a TOML document into a tree
+func parseTOML(data []byte) (any, error) {
+	p := &tomlParser{s: strings.TrimPrefix(string(data), "\ufeff"), line: 1}
+	root := newTOMLTable()
+	current := root
+
+	for {
+		p.skipBlank()
+		if p.i >= len(p.s) {
+			break
+		}
+
+		switch {
+		case strings.HasPrefix(p.s[p.i:], "[["):
+			p.i += 2
+			path, err := p.keyPath()
+			if err != nil {
+				return nil, err
+			}
+			if !strings.HasPrefix(p.s[p.i:], "]]") {
+				return nil, p.errorf("expected ']]' after table name")
+			}
+			p.i += 2
+			if current, err = p.appendTable(root, path); err != nil {
+				return nil, err
+			}
+		case p.s[p.i] == '[':
+			p.i++
+			path, err := p.keyPath()
+			if err != nil {
+				return nil, err
+			}
+			if p.i >= len(p.s) || p.s[p.i] != ']' {
+				return nil, p.errorf("expected ']' after table name")
+			}
+			p.i++
+			if current, err = p.defineTable(root, path); err != nil {
+				return nil, err
+			}
+		default:
+			if err := p.keyValue(current); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+	}
+	return root.tree(), nil
+}
+
+func (p *tomlParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// skipSpace skips spaces and tabs
+func (p *tomlParser) skipSpace() {
+	for p.i < len(p.s) && (p.s[p.i] == ' ' || p.s[p.i] == '\t') {
+		p.i++
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments
+func (p *tomlParser) skipBlank() {
+	for p.i < len(p.s) {
+		switch p.s[p.i] {
+		case ' ', '\t', '\r':
+			p.i++
+		case '\n':
+			p.i++
+			p.line++
+		case '#':
+			for p.i < len(p.s) && p.s[p.i] != '\n' {
+				p.i++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine accepts an optional comment followed by a newline or the end of input
+func (p *tomlParser) endOfLine() error {
+	p.skipSpace()
+	if p.i < len(p.s) && p.s[p.i] == '#' {
+		for p.i < len(p.s) && p.s[p.i] != '\n' {
+			p.i++
+		}
+	}
+	if p.i < len(p.s) && p.s[p.i] == '\r' {
+		p.i++
+	}
+	if p.i < len(p.s) && p.s[p.i] != '\n' {
+		return p.errorf("expected the end of the line, found %q", p.rest())
+	}
+	return nil
+}
+
+// rest returns the remainder of the current line for messages
+func (p *tomlParser) rest() string {
+	line, _, _ := strings.Cut(p.s[p.i:], "\n")
+	return line
+}
+
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+
+// keyPath parses a dotted key such as a."b c".d
+func (p *tomlParser) keyPath() ([]string, error) {
+	var path []string
+	for {
+		p.skipSpace()
+		if p.i >= len(p.s) {
+			return nil, p.errorf("expected a key")
+		}
+		switch p.s[p.i] {
+		case '"', '\'':
+			key, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, key)
+		default:
+			key := bareKey.FindString(p.s[p.i:])
+			if key == "" {
+				return nil, p.errorf("expected a key, found %q", p.rest())
+			}
+			p.i += len(key)
+			path = append(path, key)
+		}
+		p.skipSpace()
+		if p.i >= len(p.s) || p.s[p.i] != '.' {
+			return path, nil
+		}
+		p.i++
+	}
+}
+
+// keyValue parses key = value into table
+func (p *tomlParser) keyValue(table *tomlTable) error {
+	path, err := p.keyPath()
+	if err != nil {
+		return err
+	}
+	if p.i >= len(p.s) || p.s[p.i] != '=' {
+		return p.errorf("expected '=' after key %q", strings.Join(path, "."))
+	}
+	p.i++
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+
+	// Dotted keys define intermediate tables
+	for _, key := range path[:len(path)-1] {
+		switch next := table.values[key].(type) {
+		case nil:
+			child := newTOMLTable()
+			child.defined = true
+			table.set(key, child)
+			table = child
+		case *tomlTable:
+			table = next
+		default:
+			return p.errorf("key %q is already defined as a value", key)
+		}
+	}
+	key := path[len(path)-1]
+	if _, exists := table.values[key]; exists {
+		return p.errorf("duplicate key %q", strings.Join(path, "."))
+	}
+	table.set(key, value)
+	return nil
+}
+
+// descend walks a header path from root, creating implicit tables, and returns the
+// parent of the last key. The last table of an array of tables is the one extended.
+func (p *tomlParser) descend(root *tomlTable, path []string) (*tomlTable, error) {
+	table := root
+	for _, key := range path[:len(path)-1] {
+		switch next := table.values[key].(type) {
+		case nil:
+			child := newTOMLTable()
+			child.implicit = true
+			table.set(key, child)
+			table = child
+		case *tomlTable:
+			table = next
+		case *tomlArray:
+			table = next.tables[len(next.tables)-1]
+		default:
+			return nil, p.errorf("key %q is already defined as a value", key)
+		}
+	}
+	return table, nil
+}
+
+// defineTable handles a [table] header
+func (p *tomlParser) defineTable(root *tomlTable, path []string) (*tomlTable, error) {
+	parent, err := p.descend(root, path)
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	switch existing := parent.values[key].(type) {
+	case nil:
+		table := newTOMLTable()
+		table.defined = true
+		parent.set(key, table)
+		return table, nil
+	case *tomlTable:
+		if existing.defined || !existing.implicit {
+			return nil, p.errorf("table %q is defined more than once", strings.Join(path, "."))
+		}
+		existing.defined = true
+		return existing, nil
+	}
+	return nil, p.errorf("key %q is already defined as a value", strings.Join(path, "."))
+}
+
+// appendTable handles an [[array]] header
+func (p *tomlParser) appendTable(root *tomlTable, path []string) (*tomlTable, error) {
+	parent, err := p.descend(root, path)
+	if err != nil {
+		return nil, err
+	}
+	key := path[len(path)-1]
+	table := newTOMLTable()
+	table.defined = true
+	switch existing := parent.values[key].(type) {
+	case nil:
+		parent.set(key, &tomlArray{tables: []*tomlTable{table}})
+	case *tomlArray:
+		existing.tables = append(existing.tables, table)
+	default:
+		return nil, p.errorf("key %q is already defined and is not an array of tables", strings.Join(path, "."))
+	}
+	return table, nil
+}
+
+var (
+	tomlDecimal = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)`)
+	tomlFloat   = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][-+]?[0-9](_?[0-9])*)?`)
+	tomlPrefix  = regexp.MustCompile(`^0(x[0-9A-Fa-f](_?[0-9A-Fa-f])*|o[0-7](_?[0-7])*|b[01](_?[01])*)`)
+	tomlDate    = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}|^[0-9]{2}:[0-9]{2}`)
+)
+
+// value parses a TOML value
+func (p *tomlParser) value() (any, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return nil, p.errorf("expected a value")
+	}
+
+	switch p.s[p.i] {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		return p.array()
+	case '{':
+		return p.inlineTable()
+	}
+
+	rest := p.s[p.i:]
+	switch {
+	case strings.HasPrefix(rest, "true"):
+		p.i += 4
+		return true, nil
+	case strings.HasPrefix(rest, "false"):
+		p.i += 5
+		return false, nil
+	case tomlDate.MatchString(rest):
+		return nil, p.errorf("dates and times are not supported; use a string")
+	case strings.Contains("+-", rest[:1]) && (strings.HasPrefix(rest[1:], "inf") || strings.HasPrefix(rest[1:], "nan")),
+		strings.HasPrefix(rest, "inf"), strings.HasPrefix(rest, "nan"):
+		return nil, p.errorf("inf and nan are not supported")
+	}
+
+	if m := tomlPrefix.FindString(rest); m != "" {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[m[1]]
+		n, err := strconv.ParseInt(strings.ReplaceAll(m[2:], "_", ""), base, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer %s", m)
+		}
+		p.i += len(m)
+		return json.Number(strconv.FormatInt(n, 10)), nil
+	}
+	if m := tomlFloat.FindString(rest); m != "" {
+		p.i += len(m)
+		text := strings.TrimPrefix(strings.ReplaceAll(m, "_", ""), "+")
+		if m == tomlDecimal.FindString(rest) {
+			n, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				return nil, p.errorf("invalid integer %s", m)
+			}
+			return json.Number(strconv.FormatInt(n, 10)), nil
+		}
+		if !jsonNumber.MatchString(text) {
+			f, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, p.errorf("invalid number %s", m)
+			}
+			text = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		return json.Number(text), nil
+	}
+	return nil, p.errorf("invalid value %q", p.rest())
+}
+
+// array parses [v, v, ...], which may span lines and hold comments
+func (p *tomlParser) array() (any, error) {
+	p.i++ // [
+	items := []any{}
+	for {
+		p.skipBlank()
+		if p.i >= len(p.s) {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.s[p.i] == ']' {
+			p.i++
+			return items, nil
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+		p.skipBlank()
+		if p.i < len(p.s) && p.s[p.i] == ',' {
+			p.i++
+		} else if p.i < len(p.s) && p.s[p.i] != ']' {
+			return nil, p.errorf("expected ',' or ']' in array, found %q", p.rest())
+		}
+	}
+}
+
+// inlineTable parses { k = v, ... } on a single line
+func (p *tomlParser) inlineTable() (any, error) {
+	p.i++ // {
+	table := newTOMLTable()
+	p.skipSpace()
+	if p.i < len(p.s) && p.s[p.i] == '}' {
+		p.i++
+		return table, nil
+	}
+	for {
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.i >= len(p.s) || p.s[p.i] == '\n' {
+			return nil, p.errorf("unterminated inline table; inline tables must fit on one line")
+		}
+		switch p.s[p.i] {
+		case ',':
+			p.i++
+		case '}':
+			p.i++
+			return table, nil
+		default:
+			return nil, p.errorf("expected ',' or '}' in inline table, found %q", p.rest())
+		}
+	}
+}
+
+// tomlEscapes maps the single-character escapes of basic strings
+var tomlEscapes = map[byte]rune{
+	'b': '\b', 't': '\t', 'n': '\n', 'f': '\f', 'r': '\r', 'e': 0x1b, '"': '"', '\\': '\\',
+}
+
+// str parses a basic, literal or multi-line string
+func (p *tomlParser) str() (string, error) {
+	q := p.s[p.i]
+	multiline := strings.HasPrefix(p.s[p.i:], strings.Repeat(string(q), 3))
+	if multiline {
+		p.i += 3
+		// A newline right after the opening delimiter is trimmed
+		if strings.HasPrefix(p.s[p.i:], "\r\n") {
+			p.i += 2
+			p.line++
+		} else if strings.HasPrefix(p.s[p.i:], "\n") {
+			p.i++
+			p.line++
+		}
+	} else {
+		p.i++
+	}
+
+	var b strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case multiline && strings.HasPrefix(p.s[p.i:], strings.Repeat(string(q), 3)):
+			p.i += 3
+			// Up to two quotes may directly precede the closing delimiter
+			for n := 0; n < 2 && p.i < len(p.s) && p.s[p.i] == q; n++ {
+				b.WriteByte(q)
+				p.i++
+			}
+			return b.String(), nil
+		case !multiline && c == q:
+			p.i++
+			return b.String(), nil
+		case c == '\n':
+			if !multiline {
+				return "", p.errorf("unterminated string")
+			}
+			b.WriteByte(c)
+			p.i++
+			p.line++
+		case c == '\\' && q == '"':
+			if err := p.escape(&b, multiline); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.i++
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *tomlParser) escape(b *strings.Builder, multiline bool) error {
+	p.i++ // backslash
+	if p.i >= len(p.s) {
+		return p.errorf("unterminated string")
+	}
+	c := p.s[p.i]
+
+	// A backslash at the end of a line in a multi-line string joins the lines
+	if multiline && (c == ' ' || c == '\t' || c == '\r' || c == '\n') {
+		for p.i < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.i])) {
+			if p.s[p.i] == '\n' {
+				p.line++
+			}
+			p.i++
+		}
+		return nil
+	}
+
+	p.i++
+	if r, ok := tomlEscapes[c]; ok {
+		b.WriteRune(r)
+		return nil
+	}
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if digits == 0 || p.i+digits > len(p.s) {
+		return p.errorf("invalid escape sequence \\%c", c)
+	}
+	code, err := strconv.ParseUint(p.s[p.i:p.i+digits], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return p.errorf("invalid escape sequence \\%c%s", c, p.s[p.i:p.i+digits])
+	}
+	p.i += digits
+	b.WriteRune(rune(code))
+	return nil
+}
+
+// writeTOMLTable writes the plain values of a table, then its sub-tables and
+// arrays of tables under their full dotted names
+func writeTOMLTable(b *bytes.Buffer, path []string, table object) error {
+	var sections []member
+	for _, m := range table {
+		switch v := m.value.(type) {
+		case nil:
+			continue
+		case object:
+			if len(v) > 0 {
+				sections = append(sections, m)
+				continue
+			}
+		case []any:
+			if isTableArray(v) {
+				sections = append(sections, m)
+				continue
+			}
+		}
+		value, err := tomlInline(m.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", tomlKey(append(path, m.key)), err)
+		}
+		b.WriteString(tomlKey([]string{m.key}) + " = " + value + "\n")
+	}
+
+	for _, m := range sections {
+		name := append(append([]string{}, path...), m.key)
+		if obj, ok := m.value.(object); ok {
+			var body bytes.Buffer
+			if err := writeTOMLTable(&body, name, obj); err != nil {
+				return err
+			}
+			// A table holding only sub-tables is defined implicitly by their headers
+			if !bytes.HasPrefix(body.Bytes(), []byte("\n[")) {
+				b.WriteString("\n[" + tomlKey(name) + "]\n")
+			}
+			b.Write(body.Bytes())
+			continue
+		}
+		for _, item := range m.value.([]any) {
+			b.WriteString("\n[[" + tomlKey(name) + "]]\n")
+			if err := writeTOMLTable(b, name, item.(object)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isTableArray reports whether an array is written as [[array]] sections
+func isTableArray(items []any) bool {
+	for _, item := range items {
+		if _, ok := item.(object); !ok {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+// tomlInline formats a value on one line
+func tomlInline(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return quote(v), nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			part, err := tomlInline(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case object:
+		parts := make([]string, 0, len(v))
+		for _, m := range v {
+			if m.value == nil {
+				continue
+			}
+			part, err := tomlInline(m.value)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey([]string{m.key})+" = "+part)
+		}
+		if len(parts) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("null cannot be written inside an array")
+}
+
+// tomlKey formats a dotted key, quoting parts that are not bare keys
+func tomlKey(path []string) string {
+	parts := make([]string, len(path))
+	for i, key := range path {
+		if bareKey.FindString(key) == key && key != "" {
+			parts[i] = key
+		} else {
+			parts[i] = quote(key)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,143 @@
+package codec
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTOMLToJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "Config file",
+			input: `# weather-cli
+schema_version = 1
+temperature_unit = "F" # imperial
+forecast_interval = 12
+default_location = 'Tokyo'
+
+[[locations]]
+name = "Tokyo"
+latitude = 35.6895
+longitude = 139.6917
+aliases = [
+  "tyo", # short
+  "東京",
+]
+timezone = "Asia/Tokyo"
+
+[[locations]]
+name = "O'Hare"
+latitude = 41.97
+longitude = -87.9
+`,
+			want: `{"schema_version": 1, "temperature_unit": "F", "forecast_interval": 12, "default_location": "Tokyo",
+				"locations": [
+					{"name": "Tokyo", "latitude": 35.6895, "longitude": 139.6917, "aliases": ["tyo", "東京"], "timezone": "Asia/Tokyo"},
+					{"name": "O'Hare", "latitude": 41.97, "longitude": -87.9}
+				]}`,
+		},
+		{
+			name:  "Tables and dotted keys",
+			input: "[profiles.work]\nunit = \"C\"\nsite.name = \"office\"\n\n[profiles.\"home base\"]\ninline = { a = 1, b = [true, false] }\n",
+			want:  `{"profiles": {"work": {"unit": "C", "site": {"name": "office"}}, "home base": {"inline": {"a": 1, "b": [true, false]}}}}`,
+		},
+		{
+			name:  "Nested arrays of tables",
+			input: "[[p]]\nn = 1\n[[p.q]]\nm = 2\n[[p]]\nn = 3\n",
+			want:  `{"p": [{"n": 1, "q": [{"m": 2}]}, {"n": 3}]}`,
+		},
+		{
+			name:  "Numbers and strings",
+			input: "a = 1_000\nb = 0xff\nc = -0.5\nd = 6.02e23\ne = +7\nf = \"tab\\t\\u00e9\"\ng = 'C:\\path'\nh = \"\"\"\nline one\nline \\\n  two\"\"\"\ni = '''raw \\n'''\n",
+			want:  `{"a": 1000, "b": 255, "c": -0.5, "d": 6.02e23, "e": 7, "f": "tab\t\u00e9", "g": "C:\\path", "h": "line one\nline two", "i": "raw \\n"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TOMLToJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("TOMLToJSON() error = %v", err)
+			}
+			if want := decodeJSON(t, []byte(tt.want)); !reflect.DeepEqual(decodeJSON(t, got), want) {
+				t.Errorf("TOMLToJSON() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLToJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Duplicate key", "a = 1\na = 2\n", `line 2: duplicate key "a"`},
+		{"Table defined twice", "[a]\nx = 1\n[a]\ny = 2\n", `line 3: table "a" is defined more than once`},
+		{"Missing equals", "a 1\n", `line 1: expected '=' after key "a"`},
+		{"Two values on a line", "a = 1 b = 2\n", `line 1: expected the end of the line`},
+		{"Date", "a = 2024-01-01\n", "line 1: dates and times are not supported"},
+		{"Unterminated string", "a = \"abc\n", "line 1: unterminated string"},
+		{"Unterminated array", "a = [1,\n2\n", "line 3: unterminated array"},
+		{"Array of tables over a value", "a = 1\n[[a]]\n", `line 2: key "a" is already defined and is not an array of tables`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := TOMLToJSON([]byte(tt.input))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("TOMLToJSON() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONToTOML(t *testing.T) {
+	input := `{"schema_version": 1, "locations": [{"name": "Tokyo", "latitude": 35.6895, "aliases": ["tyo"]}, {"name": "Osaka", "latitude": 34.69}], "api_key": "", "none": null, "empty": {}, "my key": "a \"quoted\" value", "profiles": {"work": {"temperature_unit": "F", "locations": []}}}`
+	want := `schema_version = 1
+api_key = ""
+empty = {}
+"my key" = "a \"quoted\" value"
+
+[[locations]]
+name = "Tokyo"
+latitude = 35.6895
+aliases = ["tyo"]
+
+[[locations]]
+name = "Osaka"
+latitude = 34.69
+
+[profiles.work]
+temperature_unit = "F"
+locations = []
+`
+	got, err := JSONToTOML([]byte(input))
+	if err != nil {
+		t.Fatalf("JSONToTOML() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("JSONToTOML() =\n%s\nwant\n%s", got, want)
+	}
+
+	back, err := TOMLToJSON(got)
+	if err != nil {
+		t.Fatalf("TOMLToJSON() error = %v", err)
+	}
+	expected := decodeJSON(t, []byte(input)).(map[string]any)
+	delete(expected, "none")
+	if !reflect.DeepEqual(decodeJSON(t, back), any(expected)) {
+		t.Errorf("Round trip changed the document:\n%s", back)
+	}
+
+	if _, err := JSONToTOML([]byte(`[1, 2]`)); err == nil {
+		t.Errorf("JSONToTOML() should reject a document that is not an object")
+	}
+}
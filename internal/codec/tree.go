@@ -0,0 +1,163 @@
+// Package codec converts configuration documents between JSON, YAML and TOML.
+//
+// Only the subset of YAML and TOML needed for configuration files is supported:
+// mappings, sequences and scalars in YAML, and tables, arrays of tables, inline
+// tables, arrays and scalars in TOML. Documents are converted through JSON so that
+// callers can keep a single JSON-based decoding path.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SyntaxError reports malformed input with the line it was found on
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// A document is held as a tree of these values: object, []any, string,
+// json.Number, bool and nil
+type member struct {
+	key   string
+	value any
+}
+
+// object is a mapping that keeps the order of its keys
+type object []member
+
+func (o object) index(key string) int {
+	for i, m := range o {
+		if m.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseJSON decodes a JSON document into a tree, keeping the key order
+func parseJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := parseJSONValue(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected content after the top-level value")
+	}
+	return value, nil
+}
+
+func parseJSONValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		obj := object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := parseJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{key: key.(string), value: value})
+		}
+		_, err := decoder.Token()
+		return obj, err
+	case json.Delim('['):
+		items := []any{}
+		for decoder.More() {
+			value, err := parseJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		_, err := decoder.Token()
+		return items, err
+	}
+	return token, nil
+}
+
+// writeJSON encodes a tree as indented JSON
+func writeJSON(value any) ([]byte, error) {
+	var b bytes.Buffer
+	if err := writeJSONValue(&b, value, ""); err != nil {
+		return nil, err
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func writeJSONValue(b *bytes.Buffer, value any, indent string) error {
+	switch v := value.(type) {
+	case object:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return nil
+		}
+		b.WriteString("{\n")
+		for i, m := range v {
+			b.WriteString(indent + "  " + quote(m.key) + ": ")
+			if err := writeJSONValue(b, m.value, indent+"  "); err != nil {
+				return err
+			}
+			if i < len(v)-1 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteString(indent + "}")
+	case []any:
+		if len(v) == 0 {
+			b.WriteString("[]")
+			return nil
+		}
+		b.WriteString("[\n")
+		for i, item := range v {
+			b.WriteString(indent + "  ")
+			if err := writeJSONValue(b, item, indent+"  "); err != nil {
+				return err
+			}
+			if i < len(v)-1 {
+				b.WriteByte(',')
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteString(indent + "]")
+	case string:
+		b.WriteString(quote(v))
+	case json.Number:
+		b.WriteString(v.String())
+	case bool:
+		fmt.Fprint(b, v)
+	case nil:
+		b.WriteString("null")
+	default:
+		return fmt.Errorf("unsupported value %T", value)
+	}
+	return nil
+}
+
+// quote returns s as a double-quoted string with JSON escapes, which YAML and
+// TOML basic strings accept as well
+func quote(s string) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return string(bytes.TrimSuffix(b.Bytes(), []byte("\n")))
+}
@@ -0,0 +1,583 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// YAMLToJSON converts a YAML document to JSON. Anchors, tags, block scalars and
+// multi-line flow collections are not supported.
+func YAMLToJSON(data []byte) ([]byte, error) {
+	value, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	return writeJSON(value)
+}
+
+// JSONToYAML converts a JSON document to block-style YAML, keeping the key order
+func JSONToYAML(data []byte) ([]byte, error) {
+	value, err := parseJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	switch v := value.(type) {
+	case object:
+		if len(v) == 0 {
+			b.WriteString("{}\n")
+		}
+		writeYAMLObject(&b, v, 0)
+	case []any:
+		if len(v) == 0 {
+			b.WriteString("[]\n")
+		}
+		writeYAMLSequence(&b, v, 0)
+	default:
+		b.WriteString(yamlScalar(v) + "\n")
+	}
+	return b.Bytes(), nil
+}
+
+// yamlLine is a line holding content, with its indentation measured in spaces
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// parseYAML decodes a YAML document into a tree
+func parseYAML(data []byte) (any, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(strings.TrimPrefix(string(data), "\ufeff"), "\n") {
+		raw = strings.TrimRight(raw, " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		if text[0] == '\t' {
+			return nil, &SyntaxError{Line: i + 1, Msg: "tabs are not allowed in indentation"}
+		}
+		indent := len(raw) - len(text)
+		if indent == 0 && (text == "---" || strings.HasPrefix(text, "--- #")) {
+			if len(lines) > 0 {
+				return nil, &SyntaxError{Line: i + 1, Msg: "multiple documents are not supported"}
+			}
+			continue
+		}
+		if indent == 0 && text == "..." {
+			break
+		}
+		lines = append(lines, yamlLine{number: i + 1, indent: indent, text: text})
+	}
+	if len(lines) == 0 {
+		return object{}, nil
+	}
+
+	p := &yamlParser{lines: lines}
+	value, err := p.parseBlock(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, &SyntaxError{Line: p.lines[p.pos].number, Msg: "unexpected indentation"}
+	}
+	return value, nil
+}
+
+// parseBlock parses the mapping, sequence or scalar starting at the current line
+func (p *yamlParser) parseBlock(indent int) (any, error) {
+	line := p.lines[p.pos]
+	if isSequenceItem(line.text) {
+		return p.parseSequence(indent)
+	}
+	if _, _, ok, err := splitKey(line.text, line.number); err != nil || ok {
+		if err != nil {
+			return nil, err
+		}
+		return p.parseMapping(indent)
+	}
+	p.pos++
+	return parseInline(line.text, line.number)
+}
+
+func (p *yamlParser) parseMapping(indent int) (any, error) {
+	obj := object{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, &SyntaxError{Line: line.number, Msg: "unexpected indentation"}
+		}
+		if isSequenceItem(line.text) {
+			return nil, &SyntaxError{Line: line.number, Msg: "expected a key, found a sequence item"}
+		}
+
+		key, rest, ok, err := splitKey(line.text, line.number)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, &SyntaxError{Line: line.number, Msg: fmt.Sprintf("expected 'key: value', found %q", line.text)}
+		}
+		if obj.index(key) >= 0 {
+			return nil, &SyntaxError{Line: line.number, Msg: fmt.Sprintf("duplicate key %q", key)}
+		}
+		p.pos++
+
+		value, err := p.parseValue(indent, rest, line.number)
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, member{key: key, value: value})
+	}
+	return obj, nil
+}
+
+// parseValue parses the value of a key: the rest of its line, or the block on the
+// following lines. A sequence may start at the same indentation as its key.
+func (p *yamlParser) parseValue(indent int, rest string, number int) (any, error) {
+	if rest != "" && rest[0] != '#' {
+		return parseInline(rest, number)
+	}
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent || (next.indent == indent && isSequenceItem(next.text)) {
+			return p.parseBlock(next.indent)
+		}
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) parseSequence(indent int) (any, error) {
+	items := []any{}
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent || (line.indent == indent && !isSequenceItem(line.text)) {
+			break
+		}
+		if line.indent > indent {
+			return nil, &SyntaxError{Line: line.number, Msg: "unexpected indentation"}
+		}
+
+		rest := strings.TrimLeft(line.text[1:], " ")
+		if rest == "" || rest[0] == '#' {
+			p.pos++
+			var value any
+			if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+				var err error
+				if value, err = p.parseBlock(p.lines[p.pos].indent); err != nil {
+					return nil, err
+				}
+			}
+			items = append(items, value)
+			continue
+		}
+
+		// A nested mapping or sequence starts on the item's line, at the column after "- "
+		_, _, isKey, err := splitKey(rest, line.number)
+		if err != nil {
+			return nil, err
+		}
+		if isKey || isSequenceItem(rest) {
+			column := indent + len(line.text) - len(rest)
+			p.lines[p.pos] = yamlLine{number: line.number, indent: column, text: rest}
+			value, err := p.parseBlock(column)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+			continue
+		}
+
+		p.pos++
+		value, err := parseInline(rest, line.number)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits "key: value" into its key and the rest of the line. It reports
+// false when the text is not a mapping entry.
+func splitKey(text string, number int) (key, rest string, ok bool, err error) {
+	if text == "" || strings.ContainsRune("[{#", rune(text[0])) {
+		return "", "", false, nil
+	}
+
+	if text[0] == '"' || text[0] == '\'' {
+		s := &inlineScanner{s: text, line: number}
+		quoted, err := s.quoted()
+		if err != nil {
+			return "", "", false, err
+		}
+		after := strings.TrimLeft(text[s.i:], " ")
+		if after == ":" || strings.HasPrefix(after, ": ") {
+			return quoted, strings.TrimSpace(after[1:]), true, nil
+		}
+		return "", "", false, nil
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '#' && i > 0 && text[i-1] == ' ':
+			return "", "", false, nil
+		case text[i] == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+// inlineScanner parses a scalar or flow collection within a single line
+type inlineScanner struct {
+	s    string
+	i    int
+	line int
+}
+
+// parseInline parses the value on a line, which may end with a comment
+func parseInline(text string, number int) (any, error) {
+	s := &inlineScanner{s: text, line: number}
+	value, err := s.value(false)
+	if err != nil {
+		return nil, err
+	}
+	s.skipSpace()
+	if s.i < len(s.s) && s.s[s.i] != '#' {
+		return nil, s.errorf("unexpected %q after value", s.s[s.i:])
+	}
+	return value, nil
+}
+
+func (s *inlineScanner) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: s.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (s *inlineScanner) skipSpace() {
+	for s.i < len(s.s) && s.s[s.i] == ' ' {
+		s.i++
+	}
+}
+
+// value parses a scalar or flow collection. Inside a flow collection, plain
+// scalars end at the next ',', ']' or '}'.
+func (s *inlineScanner) value(inFlow bool) (any, error) {
+	s.skipSpace()
+	if s.i >= len(s.s) {
+		return nil, nil
+	}
+
+	switch c := s.s[s.i]; c {
+	case '[':
+		return s.flowSequence()
+	case '{':
+		return s.flowMapping()
+	case '"', '\'':
+		return s.quoted()
+	case '&', '*', '!':
+		return nil, s.errorf("anchors, aliases and tags are not supported")
+	case '|', '>':
+		return nil, s.errorf("block scalars are not supported; use a quoted string with \\n instead")
+	case '%', '@', '`':
+		return nil, s.errorf("a plain value cannot start with %q", c)
+	}
+
+	start := s.i
+	for s.i < len(s.s) {
+		c := s.s[s.i]
+		if inFlow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if c == '#' && s.i > start && s.s[s.i-1] == ' ' {
+			break
+		}
+		s.i++
+	}
+	return resolvePlain(strings.TrimSpace(s.s[start:s.i])), nil
+}
+
+func (s *inlineScanner) flowSequence() (any, error) {
+	s.i++ // [
+	items := []any{}
+	for {
+		s.skipSpace()
+		if s.i >= len(s.s) {
+			return nil, s.errorf("unterminated flow sequence; flow collections must fit on one line")
+		}
+		if s.s[s.i] == ']' {
+			s.i++
+			return items, nil
+		}
+		value, err := s.value(true)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+		if err := s.flowSeparator(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (s *inlineScanner) flowMapping() (any, error) {
+	s.i++ // {
+	obj := object{}
+	for {
+		s.skipSpace()
+		if s.i >= len(s.s) {
+			return nil, s.errorf("unterminated flow mapping; flow collections must fit on one line")
+		}
+		if s.s[s.i] == '}' {
+			s.i++
+			return obj, nil
+		}
+
+		var key string
+		if c := s.s[s.i]; c == '"' || c == '\'' {
+			quoted, err := s.quoted()
+			if err != nil {
+				return nil, err
+			}
+			key = quoted
+		} else {
+			start := s.i
+			for s.i < len(s.s) && s.s[s.i] != ':' && s.s[s.i] != ',' && s.s[s.i] != '}' {
+				s.i++
+			}
+			key = strings.TrimSpace(s.s[start:s.i])
+		}
+		s.skipSpace()
+		if s.i >= len(s.s) || s.s[s.i] != ':' {
+			return nil, s.errorf("expected ':' after key %q in flow mapping", key)
+		}
+		s.i++
+		if obj.index(key) >= 0 {
+			return nil, s.errorf("duplicate key %q", key)
+		}
+
+		value, err := s.value(true)
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, member{key: key, value: value})
+		if err := s.flowSeparator('}'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// flowSeparator consumes the ',' between flow entries, leaving the closing bracket
+func (s *inlineScanner) flowSeparator(end byte) error {
+	s.skipSpace()
+	if s.i >= len(s.s) {
+		return nil
+	}
+	switch s.s[s.i] {
+	case ',':
+		s.i++
+		return nil
+	case end:
+		return nil
+	}
+	return s.errorf("expected ',' or %q, found %q", end, s.s[s.i:])
+}
+
+// quoted parses a single- or double-quoted scalar
+func (s *inlineScanner) quoted() (string, error) {
+	q := s.s[s.i]
+	s.i++
+	var b strings.Builder
+	for s.i < len(s.s) {
+		c := s.s[s.i]
+		switch {
+		case c == q && q == '\'' && s.i+1 < len(s.s) && s.s[s.i+1] == '\'':
+			b.WriteByte('\'')
+			s.i += 2
+		case c == q:
+			s.i++
+			return b.String(), nil
+		case c == '\\' && q == '"':
+			r, err := s.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(c)
+			s.i++
+		}
+	}
+	return "", s.errorf("unterminated quoted string; quoted strings must fit on one line")
+}
+
+// yamlEscapes maps the single-character escapes of double-quoted scalars
+var yamlEscapes = map[byte]rune{
+	'0': 0, 'a': '\a', 'b': '\b', 't': '\t', 'n': '\n', 'v': '\v', 'f': '\f', 'r': '\r',
+	'e': 0x1b, ' ': ' ', '"': '"', '/': '/', '\\': '\\', 'N': 0x85, '_': 0xa0,
+}
+
+func (s *inlineScanner) escape() (rune, error) {
+	s.i++ // backslash
+	if s.i >= len(s.s) {
+		return 0, s.errorf("unterminated escape sequence")
+	}
+	c := s.s[s.i]
+	s.i++
+	if r, ok := yamlEscapes[c]; ok {
+		return r, nil
+	}
+	digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+	if digits == 0 || s.i+digits > len(s.s) {
+		return 0, s.errorf("invalid escape sequence \\%c", c)
+	}
+	code, err := strconv.ParseUint(s.s[s.i:s.i+digits], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		return 0, s.errorf("invalid escape sequence \\%c%s", c, s.s[s.i:s.i+digits])
+	}
+	s.i += digits
+	return rune(code), nil
+}
+
+var (
+	jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+	yamlInt    = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloat  = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolvePlain types an unquoted scalar following the YAML 1.2 core schema
+func resolvePlain(s string) any {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+
+	if jsonNumber.MatchString(s) {
+		return json.Number(s)
+	}
+	base, digits := 10, s
+	switch {
+	case strings.HasPrefix(s, "0x"):
+		base, digits = 16, s[2:]
+	case strings.HasPrefix(s, "0o"):
+		base, digits = 8, s[2:]
+	case !yamlInt.MatchString(s):
+		if yamlFloat.MatchString(s) {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+			}
+		}
+		return s
+	}
+	if n, err := strconv.ParseInt(digits, base, 64); err == nil {
+		return json.Number(strconv.FormatInt(n, 10))
+	}
+	return s
+}
+
+func writeYAMLObject(b *bytes.Buffer, obj object, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, m := range obj {
+		b.WriteString(pad + yamlString(m.key) + ":")
+		writeYAMLValue(b, m.value, indent)
+	}
+}
+
+func writeYAMLSequence(b *bytes.Buffer, items []any, indent int) {
+	pad := strings.Repeat(" ", indent)
+	for _, item := range items {
+		// A nested block starts on the line of its "- " indicator
+		var nested bytes.Buffer
+		switch v := item.(type) {
+		case object:
+			writeYAMLObject(&nested, v, indent+2)
+		case []any:
+			writeYAMLSequence(&nested, v, indent+2)
+		}
+		if nested.Len() > 0 {
+			b.WriteString(pad + "- " + nested.String()[indent+2:])
+			continue
+		}
+		b.WriteString(pad + "-")
+		writeYAMLValue(b, item, indent)
+	}
+}
+
+// writeYAMLValue writes a value after its key or item indicator
+func writeYAMLValue(b *bytes.Buffer, value any, indent int) {
+	switch v := value.(type) {
+	case object:
+		if len(v) == 0 {
+			b.WriteString(" {}\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLObject(b, v, indent+2)
+	case []any:
+		if len(v) == 0 {
+			b.WriteString(" []\n")
+			return
+		}
+		b.WriteString("\n")
+		writeYAMLSequence(b, v, indent+2)
+	default:
+		b.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(value any) string {
+	switch v := value.(type) {
+	case string:
+		return yamlString(v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return "null"
+}
+
+// yamlString writes s unquoted when it reads back as the same string
+func yamlString(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsRune("-?:,[]{}#&*!|>'\"%@`", rune(s[0])) ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return quote(s)
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return quote(s)
+		}
+	}
+	if resolved, ok := resolvePlain(s).(string); !ok || resolved != s {
+		return quote(s)
+	}
+	// YAML 1.1 parsers read these as booleans
+	switch strings.ToLower(s) {
+	case "y", "yes", "n", "no", "on", "off":
+		return quote(s)
+	}
+	return s
+}
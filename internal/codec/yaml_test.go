@@ -0,0 +1,173 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decodeJSON decodes JSON output for comparison with an expected value
+func decodeJSON(t *testing.T, data []byte) any {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, data)
+	}
+	return value
+}
+
+func TestYAMLToJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name: "Config file",
+			input: `---
+# weather-cli
+schema_version: 1
+temperature_unit: F   # imperial
+forecast_interval: 12
+locations:
+  - name: Tokyo
+    latitude: 35.6895
+    longitude: 139.6917
+    aliases: [tyo, "東京"]
+    timezone: Asia/Tokyo
+  -   name: 'O''Hare'
+      latitude: 41.97
+      longitude: -87.9
+default_location: Tokyo
+`,
+			want: `{"schema_version": 1, "temperature_unit": "F", "forecast_interval": 12,
+				"locations": [
+					{"name": "Tokyo", "latitude": 35.6895, "longitude": 139.6917, "aliases": ["tyo", "東京"], "timezone": "Asia/Tokyo"},
+					{"name": "O'Hare", "latitude": 41.97, "longitude": -87.9}
+				],
+				"default_location": "Tokyo"}`,
+		},
+		{
+			name:  "Sequence at the same indentation as its key",
+			input: "tags:\n- home\n- work\nname: x\n",
+			want:  `{"tags": ["home", "work"], "name": "x"}`,
+		},
+		{
+			name:  "Nested mappings and empty values",
+			input: "a:\n  b:\n    c: 1\n  d:\ne: {}\nf: []\ng: ~\n",
+			want:  `{"a": {"b": {"c": 1}, "d": null}, "e": {}, "f": [], "g": null}`,
+		},
+		{
+			name:  "Scalars",
+			input: "a: true\nb: False\nc: 0x1F\nd: +5\ne: 1.5e3\nf: .5\ng: \"tab\\there\\u00e9\"\nh: http://example.com/a#b\ni: 007\nj: '# not a comment'\nk: -\"x\"\n",
+			want:  `{"a": true, "b": false, "c": 31, "d": 5, "e": 1500, "f": 0.5, "g": "tab\there\u00e9", "h": "http://example.com/a#b", "i": 7, "j": "# not a comment", "k": "-\"x\""}`,
+		},
+		{
+			name:  "Nested sequences and flow mappings",
+			input: "- - 1\n  - 2\n- {a: 1, \"b c\": [x, y]}\n",
+			want:  `[[1, 2], {"a": 1, "b c": ["x", "y"]}]`,
+		},
+		{
+			name:  "Empty document",
+			input: "# nothing here\n",
+			want:  `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := YAMLToJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("YAMLToJSON() error = %v", err)
+			}
+			if want := decodeJSON(t, []byte(tt.want)); !reflect.DeepEqual(decodeJSON(t, got), want) {
+				t.Errorf("YAMLToJSON() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYAMLToJSONErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"Bad indentation", "a: 1\n   b: 2\n", "line 2: unexpected indentation"},
+		{"Duplicate key", "a: 1\na: 2\n", `line 2: duplicate key "a"`},
+		{"Tab indentation", "a:\n\tb: 1\n", "line 2: tabs are not allowed in indentation"},
+		{"Block scalar", "a: |\n  text\n", "line 1: block scalars are not supported"},
+		{"Anchor", "a: &x 1\n", "line 1: anchors, aliases and tags are not supported"},
+		{"Unterminated string", "a: \"abc\n", "line 1: unterminated quoted string"},
+		{"Multi-line flow", "a: [1,\n  2]\n", "line 1: unterminated flow sequence"},
+		{"Not a key", "a: 1\njust text\n", `line 2: expected 'key: value', found "just text"`},
+		{"Two documents", "a: 1\n---\nb: 2\n", "line 2: multiple documents are not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := YAMLToJSON([]byte(tt.input))
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("YAMLToJSON() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONToYAML(t *testing.T) {
+	input := `{"schema_version": 1, "locations": [{"name": "Tokyo", "latitude": 35.6895, "aliases": ["tyo"]}], "api_key": "", "unit": "C", "note": "a: b", "flag": "yes", "number": "42", "empty": {}, "none": null, "nested": [[1, 2], []]}`
+	want := `schema_version: 1
+locations:
+  - name: Tokyo
+    latitude: 35.6895
+    aliases:
+      - tyo
+api_key: ""
+unit: C
+note: "a: b"
+flag: "yes"
+number: "42"
+empty: {}
+none: null
+nested:
+  - - 1
+    - 2
+  - []
+`
+	got, err := JSONToYAML([]byte(input))
+	if err != nil {
+		t.Fatalf("JSONToYAML() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("JSONToYAML() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The output reads back as the same document
+	back, err := YAMLToJSON(got)
+	if err != nil {
+		t.Fatalf("YAMLToJSON() error = %v", err)
+	}
+	if !reflect.DeepEqual(decodeJSON(t, back), decodeJSON(t, []byte(input))) {
+		t.Errorf("Round trip changed the document:\n%s", back)
+	}
+}
+
+func TestYAMLStringQuoting(t *testing.T) {
+	for _, s := range []string{"", " padded", "null", "true", "no", "1.5", "0x10", "- item", "#tag", "key: value", "a #b", "line\nbreak", "ends:", "[x]", "'q'", "Zürich", "São Paulo", "New York", "Asia/Tokyo"} {
+		doc := quote(s)
+		out, err := JSONToYAML([]byte(`{"k": ` + doc + `}`))
+		if err != nil {
+			t.Fatalf("JSONToYAML(%q) error = %v", s, err)
+		}
+		back, err := YAMLToJSON(out)
+		if err != nil {
+			t.Fatalf("YAMLToJSON(%q) error = %v", out, err)
+		}
+		if got := decodeJSON(t, back).(map[string]any)["k"]; got != s {
+			t.Errorf("String %q came back as %#v from:\n%s", s, got, out)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AlertRule is a condition on the forecast to be notified about, e.g. a chance of
+// rain above 0.6 in the next 6 hours at the office
+type AlertRule struct {
+	Name string `json:"name"`
+	// Location is the saved location the rule applies to; the default location when empty
+	Location string `json:"location,omitempty"`
+	// Metric is one of AlertMetrics
+	Metric string `json:"metric"`
+	// Above or Below is the threshold the metric must cross. Temperatures are in
+	// the configured temperature unit.
+	Above *float64 `json:"above,omitempty"`
+	Below *float64 `json:"below,omitempty"`
+	// Within is how far ahead to look, e.g. "6h", or Period is one of AlertPeriods.
+	// Without either the next 24 hours are checked.
+	Within string `json:"within,omitempty"`
+	Period string `json:"period,omitempty"`
+	// Notify replaces the alert_notify targets for this rule
+	Notify []string `json:"notify,omitempty"`
+}
+
+// AlertMetrics are the forecast values an alert rule can check
+var AlertMetrics = []string{"temp", "feels_like", "pop", "rain", "snow", "wind", "gust", "humidity", "clouds"}
+
+// AlertPeriods are the named parts of the day an alert rule can check, in the
+// local time of the location
+var AlertPeriods = []string{"today", "tonight", "tomorrow"}
+
+// validateAlerts checks the alert rules and notification targets. Rules are checked
+// with the locations of the active profile, so a location that no profile has only
+// fails its own rule and is reported as a warning.
+func validateAlerts(cfg *Config, report, warn func(path, format string, args ...any)) {
+	for i, target := range cfg.AlertNotify {
+		if err := CheckNotifyTarget(cfg, target); err != nil {
+			report(fmt.Sprintf("$.alert_notify[%d]", i), "%v", err)
+		}
+	}
+
+	names := make(map[string]string)
+	for i, rule := range cfg.Alerts {
+		path := fmt.Sprintf("$.alerts[%d]", i)
+		if strings.TrimSpace(rule.Name) == "" {
+			report(path+".name", "must not be empty")
+		} else if first, ok := names[strings.ToLower(rule.Name)]; ok {
+			report(path+".name", "%q is already used at %s", rule.Name, first)
+		} else {
+			names[strings.ToLower(rule.Name)] = path + ".name"
+		}
+
+		switch {
+		case rule.Location != "" && !anyHasLocationName(cfg, rule.Location):
+			warn(path+".location", "%q does not match any saved location", rule.Location)
+		case rule.Location == "" && !anyHasDefaultLocation(cfg):
+			warn(path+".location", "must be set when there is no default_location")
+		}
+
+		if !slices.Contains(AlertMetrics, rule.Metric) {
+			report(path+".metric", "must be one of %s, got %q", strings.Join(AlertMetrics, ", "), rule.Metric)
+		}
+		if (rule.Above == nil) == (rule.Below == nil) {
+			report(path, "must set exactly one of above and below")
+		}
+
+		if rule.Within != "" {
+			if rule.Period != "" {
+				report(path, "must not set both within and period")
+			}
+			if d, err := time.ParseDuration(rule.Within); err != nil || d <= 0 {
+				report(path+".within", "must be a positive duration such as 6h, got %q", rule.Within)
+			}
+		}
+		if rule.Period != "" && !slices.Contains(AlertPeriods, rule.Period) {
+			report(path+".period", "must be one of %s, got %q", strings.Join(AlertPeriods, ", "), rule.Period)
+		}
+
+		for k, target := range rule.Notify {
+			if err := CheckNotifyTarget(cfg, target); err != nil {
+				report(fmt.Sprintf("%s.notify[%d]", path, k), "%v", err)
+			}
+		}
+	}
+}
@@ -1,71 +1,136 @@
 package config
 
 import (
-	"encoding/json"
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"syscall"
 )
 
-var defaultConfigFile = "config.json"
 var defaultTempUnit = "C"
 var defaultForecastHours = 24
 
 // Config represents the application configuration
 type Config struct {
+	SchemaVersion    int        `json:"schema_version"`
 	Locations        []Location `json:"locations"`
 	TemperatureUnit  string     `json:"temperature_unit"`
 	ForecastInterval int        `json:"forecast_interval"`
 	APIKey           string     `json:"api_key"`
+	DefaultLocation  string     `json:"default_location"`
+	Provider         string     `json:"provider,omitempty"`
+	Language         string     `json:"language,omitempty"`
+	APIKeyCmd        string     `json:"api_key_cmd,omitempty"`
+	APIKeyFile       string     `json:"api_key_file,omitempty"`
+	APIKeyKeyring    bool       `json:"api_key_keyring,omitempty"`
+
+	CurrentProfile string             `json:"current_profile,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty"`
+
+	Alerts      []AlertRule         `json:"alerts,omitempty"`
+	AlertNotify []string            `json:"alert_notify,omitempty"`
+	Notifiers   map[string]Notifier `json:"notifiers,omitempty"`
+	Jobs        []Job               `json:"jobs,omitempty"`
+
+	// overrides records values applied from the environment or flags
+	overrides map[string]override
+	// version is the file content this config was loaded from or last saved as
+	version fileVersion
+	// profile is the profile applied over the top-level settings, if any
+	profile *activeProfile
+	// warnings are the problems found in the file that don't stop it from loading
+	warnings []Problem
 }
 
 // Location represents a saved location
 type Location struct {
-	Name      string  `json:"name"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
+	Name      string   `json:"name"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Aliases   []string `json:"aliases,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	Timezone  string   `json:"timezone,omitempty"`
 }
 
 // LoadConfig loads the configuration from the config file
 func LoadConfig() (*Config, error) {
-	if _, err := os.Stat(defaultConfigFile); os.IsNotExist(err) {
-		return createDefaultConfig()
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
 	}
 
-	file, err := os.ReadFile(defaultConfigFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		cfg, err := createDefaultConfig()
+		if err != nil && isReadOnly(err) {
+			// In containers and CI the config directory may be read-only; the defaults
+			// still work together with environment overrides
+			return NewDefaultConfig(), nil
+		}
+		return cfg, err
+	}
+
+	file, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("error reading config file: %w", err)
 	}
 
-	var config Config
-	if err := json.Unmarshal(file, &config); err != nil {
-		return nil, fmt.Errorf("error unmarshaling config: %w", err)
+	return parseConfig(path, file)
+}
+
+// parseConfig decodes and validates the content of a config file, upgrading files
+// written with an older schema in memory. Use MigrateConfig to upgrade the file itself.
+func parseConfig(path string, data []byte) (*Config, error) {
+	doc, problems := readDocument(path, data)
+	if doc != nil {
+		doc.migrate()
+		var config *Config
+		if config, problems = doc.decode(); config != nil {
+			config.version = sha256.Sum256(data)
+			config.warnings = problems
+			return config, nil
+		}
 	}
+	return nil, &ValidationError{File: path, Problems: errorsOnly(problems)}
+}
 
-	return &config, nil
+// Warnings returns the problems found in the config file that did not stop it
+// from loading. Run Diagnose for a full report.
+func (c *Config) Warnings() []Problem {
+	return c.warnings
 }
 
-// SaveConfig saves the configuration to the config file
+// SaveConfig saves the configuration to the config file, keeping the previous
+// version as a backup. Use Update for read-modify-write sequences.
 func SaveConfig(cfg *Config) error {
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	path, err := ConfigPath()
 	if err != nil {
-		return fmt.Errorf("error marshaling config: %w", err)
+		return err
 	}
 
-	if err := os.WriteFile(defaultConfigFile, data, 0600); err != nil {
+	cfg.SchemaVersion = SchemaVersion
+	data, err := Marshal(FileView(cfg), FormatFromPath(path))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
 		return fmt.Errorf("error writing config file: %w", err)
 	}
+	cfg.version = sha256.Sum256(data)
 
 	return nil
 }
 
 // createDefaultConfig creates a default configuration
 func createDefaultConfig() (*Config, error) {
-	cfg := &Config{
-		TemperatureUnit:  defaultTempUnit,
-		ForecastInterval: defaultForecastHours,
-	}
+	cfg := NewDefaultConfig()
 
 	if err := SaveConfig(cfg); err != nil {
 		return nil, fmt.Errorf("error saving default config: %w", err)
@@ -74,6 +139,20 @@ func createDefaultConfig() (*Config, error) {
 	return cfg, nil
 }
 
+// isReadOnly reports whether err comes from a config directory that cannot be written
+func isReadOnly(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// NewDefaultConfig returns a configuration holding only the default values, without
+// writing it to the config file
+func NewDefaultConfig() *Config {
+	return &Config{
+		TemperatureUnit:  defaultTempUnit,
+		ForecastInterval: defaultForecastHours,
+	}
+}
+
 // AddLocation adds a new location to the configuration
 func (c *Config) AddLocation(name string, lat, lon float64) {
 	c.Locations = append(c.Locations, Location{
@@ -88,6 +167,9 @@ func (c *Config) RemoveLocation(name string) error {
 	for i, loc := range c.Locations {
 		if loc.Name == name {
 			c.Locations = append(c.Locations[:i], c.Locations[i+1:]...)
+			if c.DefaultLocation == name {
+				c.DefaultLocation = ""
+			}
 			return nil
 		}
 	}
@@ -104,32 +186,24 @@ func (c *Config) SetForecastInterval(hours int) {
 	c.ForecastInterval = hours
 }
 
-// SetAPIKey sets the API key in the configuration
-func (c *Config) SetAPIKey(apiKey string) {
-	c.APIKey = apiKey
+// SetLanguage sets the language of weather descriptions
+func (c *Config) SetLanguage(lang string) {
+	c.Language = lang
 }
 
-// GetConfigDir returns the directory where the config file is stored
-func GetConfigDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("error getting user home directory: %w", err)
-	}
-
-	configDir := filepath.Join(homeDir, ".weather-cli")
-	if err := os.MkdirAll(configDir, 0750); err != nil {
-		return "", fmt.Errorf("error creating config directory: %w", err)
-	}
-
-	return configDir, nil
+// SetDefaultLocation sets the location used when no location is given
+func (c *Config) SetDefaultLocation(name string) {
+	c.DefaultLocation = name
 }
 
-func init() {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting config directory: %v\n", err)
-		os.Exit(1)
-	}
+// SetAPIKey sets the API key in the configuration
+func (c *Config) SetAPIKey(apiKey string) {
+	c.APIKey = apiKey
+}
 
-	defaultConfigFile = filepath.Join(configDir, "config.json")
+// SetAPIKeySource sets where the API key is read from instead of the plaintext api_key
+func (c *Config) SetAPIKeySource(command, file string, keyring bool) {
+	c.APIKeyCmd = command
+	c.APIKeyFile = file
+	c.APIKeyKeyring = keyring
 }
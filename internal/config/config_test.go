@@ -1,9 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
+	"syscall"
 	"testing"
 )
 
@@ -13,12 +16,12 @@ func setupTestEnvironment(t *testing.T) func() {
 		t.Fatalf("Failed to create temp dir: %v", err)
 	}
 
-	oldConfigFile := defaultConfigFile
-	defaultConfigFile = filepath.Join(tempDir, "config.json")
+	oldConfigPath := configPath
+	configPath = filepath.Join(tempDir, "config.json")
 
 	return func() {
 		os.RemoveAll(tempDir)
-		defaultConfigFile = oldConfigFile
+		configPath = oldConfigPath
 	}
 }
 
@@ -73,6 +76,27 @@ func TestAddAndRemoveLocation(t *testing.T) {
 	}
 }
 
+func TestRemoveDefaultLocation(t *testing.T) {
+	config := &Config{}
+	config.AddLocation("Tokyo", 35.6895, 139.6917)
+	config.AddLocation("Osaka", 34.6937, 135.5023)
+	config.SetDefaultLocation("Tokyo")
+
+	if err := config.RemoveLocation("Osaka"); err != nil {
+		t.Fatalf("Failed to remove location: %v", err)
+	}
+	if config.DefaultLocation != "Tokyo" {
+		t.Errorf("Removing another location should keep the default")
+	}
+
+	if err := config.RemoveLocation("Tokyo"); err != nil {
+		t.Fatalf("Failed to remove location: %v", err)
+	}
+	if config.DefaultLocation != "" {
+		t.Errorf("Removing the default location should clear it")
+	}
+}
+
 func TestSetConfigOptions(t *testing.T) {
 	config := &Config{}
 
@@ -136,11 +160,27 @@ func TestLoadNonExistentConfig(t *testing.T) {
 	}
 }
 
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("error saving default config: %w", &fs.PathError{Op: "mkdir", Path: "/etc/weather-cli", Err: syscall.EACCES}), true},
+		{fmt.Errorf("error saving default config: %w", &fs.PathError{Op: "open", Path: "/config", Err: syscall.EROFS}), true},
+		{fmt.Errorf("error saving default config: %w", &fs.PathError{Op: "write", Path: "/config", Err: syscall.ENOSPC}), false},
+	}
+	for _, tt := range tests {
+		if got := isReadOnly(tt.err); got != tt.want {
+			t.Errorf("isReadOnly(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
 func TestLoadInvalidConfig(t *testing.T) {
 	cleanup := setupTestEnvironment(t)
 	defer cleanup()
 
-	err := os.WriteFile(defaultConfigFile, []byte("{invalid json}"), 0644)
+	err := os.WriteFile(configPath, []byte("{invalid json}"), 0644)
 	if err != nil {
 		t.Fatalf("Failed to create invalid config file: %v", err)
 	}
@@ -156,7 +196,7 @@ func TestSaveConfigError(t *testing.T) {
 	defer cleanup()
 
 	// Get the path of the temporary directory
-	tempDir := filepath.Dir(defaultConfigFile)
+	tempDir := filepath.Dir(configPath)
 
 	err := os.Chmod(tempDir, 0555)
 	if err != nil {
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// Diagnosis is the result of checking the config file without loading it
+type Diagnosis struct {
+	Path   string
+	Exists bool
+	// SchemaVersion is the version the file was written with
+	SchemaVersion int
+	// Migrations describes the upgrades the file still needs
+	Migrations []string
+	// Changes describes the values the upgrades will repair
+	Changes []string
+	// Problems holds errors that stop the file from loading and warnings
+	Problems []Problem
+}
+
+// Diagnose checks the config file and reports every problem found in it, after
+// applying pending migrations in memory
+func Diagnose() (*Diagnosis, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis := &Diagnosis{Path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return diagnosis, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	diagnosis.Exists = true
+
+	doc, problems := readDocument(path, data)
+	if doc == nil {
+		diagnosis.Problems = problems
+		return diagnosis, nil
+	}
+	diagnosis.SchemaVersion = doc.version
+	diagnosis.Migrations = doc.pendingMigrations()
+
+	diagnosis.Changes = doc.migrate()
+	_, diagnosis.Problems = doc.decode()
+	return diagnosis, nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"weather-cli/internal/codec"
+)
+
+// Config file formats, chosen by the file extension
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// configFileNames are the names looked up in the config directory, in order
+var configFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// FormatFromPath returns the format of a config file from its extension; files
+// without a .yaml, .yml or .toml extension are JSON
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatJSON
+}
+
+// Marshal encodes cfg in the given format as it is written to the config file
+func Marshal(cfg *Config, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling config: %w", err)
+	}
+	switch format {
+	case FormatJSON:
+		return data, nil
+	case FormatYAML:
+		return codec.JSONToYAML(data)
+	case FormatTOML:
+		return codec.JSONToTOML(data)
+	}
+	return nil, fmt.Errorf("unsupported config format '%s'. Use json, yaml or toml", format)
+}
+
+// toJSON converts the content of a config file to JSON for decoding
+func toJSON(data []byte, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return codec.YAMLToJSON(data)
+	case FormatTOML:
+		return codec.TOMLToJSON(data)
+	}
+	return data, nil
+}
+
+// ConvertConfig rewrites the config file in another format next to the current one,
+// e.g. config.json to config.yaml. The current file is kept with BackupSuffix appended,
+// so that only the converted file is found afterwards.
+func ConvertConfig(format string) (from, to string, err error) {
+	from, err = ConfigPath()
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := Marshal(&Config{}, format); err != nil {
+		return "", "", err
+	}
+	if FormatFromPath(from) == format {
+		return "", "", fmt.Errorf("%s is already in %s format", from, format)
+	}
+	to = strings.TrimSuffix(from, filepath.Ext(from)) + "." + format
+	if _, err := os.Stat(to); err == nil {
+		return "", "", fmt.Errorf("%s already exists", to)
+	}
+
+	unlock, err := lockFile(from+".lock", LockTimeout)
+	if err != nil {
+		return "", "", fmt.Errorf("error locking config file: %w", err)
+	}
+	defer unlock()
+
+	file, err := os.ReadFile(from)
+	if err != nil {
+		return "", "", fmt.Errorf("error reading config file: %w", err)
+	}
+	cfg, err := parseConfig(from, file)
+	if err != nil {
+		return "", "", err
+	}
+	cfg.SchemaVersion = SchemaVersion
+	data, err := Marshal(cfg, format)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := writeFileAtomic(to, data); err != nil {
+		return "", "", fmt.Errorf("error writing config file: %w", err)
+	}
+	if err := os.Rename(from, from+BackupSuffix); err != nil {
+		os.Remove(to)
+		return "", "", fmt.Errorf("error moving %s aside: %w", from, err)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"config.json":      FormatJSON,
+		"config.yaml":      FormatYAML,
+		"/etc/weather.YML": FormatYAML,
+		"config.toml":      FormatTOML,
+		"config":           FormatJSON,
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestSaveAndLoadFormats(t *testing.T) {
+	for _, name := range []string{"config.yaml", "config.toml"} {
+		t.Run(name, func(t *testing.T) {
+			oldConfigPath := configPath
+			configPath = filepath.Join(t.TempDir(), name)
+			defer func() { configPath = oldConfigPath }()
+
+			saved := &Config{
+				Locations: []Location{
+					{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917, Aliases: []string{"tyo"}, Tags: []string{"asia"}, Timezone: "Asia/Tokyo"},
+					{Name: "São Paulo", Latitude: -23.5505, Longitude: -46.6333},
+				},
+				TemperatureUnit:  "F",
+				ForecastInterval: 12,
+				APIKey:           "key: with # characters",
+				DefaultLocation:  "tyo",
+				APIKeyKeyring:    true,
+			}
+			if err := SaveConfig(saved); err != nil {
+				t.Fatalf("SaveConfig() error = %v", err)
+			}
+			data, _ := os.ReadFile(configPath)
+			if strings.HasPrefix(string(data), "{") {
+				t.Errorf("%s was written as JSON:\n%s", name, data)
+			}
+
+			loaded, err := LoadConfig()
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v\n%s", err, data)
+			}
+			if !reflect.DeepEqual(saved, loaded) {
+				t.Errorf("Loaded config does not match saved config.\nExpected: %+v\nGot: %+v\n%s", saved, loaded, data)
+			}
+		})
+	}
+}
+
+func TestLoadYAMLProblems(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	configPath = strings.TrimSuffix(configPath, ".json") + ".yaml"
+
+	content := "schema_version: 1\nlocations:\n  - name: Tokyo\n    latitude: 35\n    longitud: 139\n    timezone: Mars/Olympus\n"
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	_, err := LoadConfig()
+	if err == nil || !strings.Contains(err.Error(), `$.locations[0].timezone: unknown time zone "Mars/Olympus"`) ||
+		!strings.Contains(err.Error(), "$.locations[0].longitud: unknown key") {
+		t.Errorf("LoadConfig() error = %v, want the problems with their paths", err)
+	}
+
+	if err := os.WriteFile(configPath, []byte("schema_version: 1\n  locations: []\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "$: line 2: unexpected indentation") {
+		t.Errorf("LoadConfig() error = %v, want the YAML syntax error", err)
+	}
+}
+
+func TestConvertConfig(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cfg := &Config{Locations: []Location{{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}}, TemperatureUnit: "C", ForecastInterval: 24}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	jsonPath := configPath
+
+	if _, _, err := ConvertConfig(FormatJSON); err == nil {
+		t.Errorf("Converting to the current format should fail")
+	}
+	if _, _, err := ConvertConfig("ini"); err == nil {
+		t.Errorf("Converting to an unknown format should fail")
+	}
+
+	from, to, err := ConvertConfig(FormatYAML)
+	if err != nil {
+		t.Fatalf("ConvertConfig() error = %v", err)
+	}
+	wantTo := strings.TrimSuffix(jsonPath, ".json") + ".yaml"
+	if from != jsonPath || to != wantTo {
+		t.Errorf("ConvertConfig() = %s, %s, want %s, %s", from, to, jsonPath, wantTo)
+	}
+	if _, err := os.Stat(jsonPath); !os.IsNotExist(err) {
+		t.Errorf("%s should be moved aside, stat error = %v", jsonPath, err)
+	}
+	if _, err := os.Stat(jsonPath + BackupSuffix); err != nil {
+		t.Errorf("%s was not kept: %v", jsonPath+BackupSuffix, err)
+	}
+
+	configPath = to
+	loaded, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Locations, cfg.Locations) {
+		t.Errorf("Converted locations = %+v, want %+v", loaded.Locations, cfg.Locations)
+	}
+}
+
+func TestDefaultConfigPathFindsFormats(t *testing.T) {
+	home := setupPathEnvironment(t)
+	dir := filepath.Join(home, ".config", "weather-cli")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "config.toml"), []byte("schema_version = 1\n"), 0600)
+	if got, _ := ConfigPath(); got != filepath.Join(dir, "config.toml") {
+		t.Errorf("ConfigPath() = %s, want config.toml", got)
+	}
+
+	os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("schema_version: 1\n"), 0600)
+	if got, _ := ConfigPath(); got != filepath.Join(dir, "config.yaml") {
+		t.Errorf("ConfigPath() = %s, want config.yaml before config.toml", got)
+	}
+}
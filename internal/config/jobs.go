@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"weather-cli/internal/schedule"
+)
+
+// Tasks a scheduled job can run
+const (
+	// TaskSummary sends the daily summary for a location to notification targets
+	TaskSummary = "summary"
+	// TaskAlerts checks the alert rules
+	TaskAlerts = "alerts"
+)
+
+// JobTasks are the tasks a scheduled job can run
+var JobTasks = []string{TaskSummary, TaskAlerts}
+
+// DefaultJobTimeout is how long a job may run when it sets no timeout
+const DefaultJobTimeout = 2 * time.Minute
+
+// Job is a task that weather daemon runs on a schedule, e.g. the daily summary
+// for the office sent to Slack at 07:30 on weekdays
+type Job struct {
+	Name string `json:"name"`
+	// Schedule is a cron expression such as "30 7 * * mon-fri", or "@every 30m"
+	Schedule string `json:"schedule"`
+	// Task is one of JobTasks
+	Task string `json:"task"`
+	// Location is the saved location of a summary; the default location when empty
+	Location string `json:"location,omitempty"`
+	// Notify are the targets of a summary; standard output when empty
+	Notify []string `json:"notify,omitempty"`
+	// Timeout limits a run of the job, e.g. "1m"; DefaultJobTimeout when empty
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// JobTimeout returns how long a run of the job may take
+func (j Job) JobTimeout() time.Duration {
+	if d, err := time.ParseDuration(j.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultJobTimeout
+}
+
+// validateJobs checks the scheduled jobs. Like alert rules, a summary job whose
+// location no profile has only fails when it runs, so that is a warning.
+func validateJobs(cfg *Config, report, warn func(path, format string, args ...any)) {
+	names := make(map[string]string)
+	for i, job := range cfg.Jobs {
+		path := fmt.Sprintf("$.jobs[%d]", i)
+		if strings.TrimSpace(job.Name) == "" {
+			report(path+".name", "must not be empty")
+		} else if first, ok := names[strings.ToLower(job.Name)]; ok {
+			report(path+".name", "%q is already used at %s", job.Name, first)
+		} else {
+			names[strings.ToLower(job.Name)] = path + ".name"
+		}
+
+		if _, err := schedule.Parse(job.Schedule); err != nil {
+			report(path+".schedule", "%v", err)
+		}
+		if job.Timeout != "" {
+			if d, err := time.ParseDuration(job.Timeout); err != nil || d <= 0 {
+				report(path+".timeout", "must be a positive duration such as 1m, got %q", job.Timeout)
+			}
+		}
+
+		switch job.Task {
+		case TaskSummary:
+			switch {
+			case job.Location != "" && !anyHasLocationName(cfg, job.Location):
+				warn(path+".location", "%q does not match any saved location", job.Location)
+			case job.Location == "" && !anyHasDefaultLocation(cfg):
+				warn(path+".location", "must be set when there is no default_location")
+			}
+			for k, target := range job.Notify {
+				if err := CheckNotifyTarget(cfg, target); err != nil {
+					report(fmt.Sprintf("%s.notify[%d]", path, k), "%v", err)
+				}
+			}
+		case TaskAlerts:
+			if len(cfg.Alerts) == 0 {
+				report(path+".task", "there are no alert rules to check")
+			}
+			if job.Location != "" || len(job.Notify) > 0 {
+				report(path, "location and notify only apply to %s jobs; alerts go to the targets of each rule", TaskSummary)
+			}
+		default:
+			report(path+".task", "must be one of %s, got %q", strings.Join(JobTasks, ", "), job.Task)
+		}
+	}
+}
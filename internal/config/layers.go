@@ -0,0 +1,289 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Layers a setting can come from, lowest precedence first
+const (
+	LayerDefault = "default"
+	LayerFile    = "file"
+	// LayerProfile marks a value set by the active profile or a profile it inherits from
+	LayerProfile = "profile"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+	// LayerSecret marks an API key read from a keyring, command or file
+	LayerSecret = "secret"
+)
+
+// DefaultProvider is the weather provider used when none is configured
+const DefaultProvider = "openweather"
+
+// Setting is the resolved value of a config key and where it came from
+type Setting struct {
+	Key   string
+	Value string
+	Layer string
+	// Source names the file, environment variable or flag that set the value
+	Source string
+}
+
+// configKey describes a config value that can be overridden by environment or flags
+type configKey struct {
+	name         string
+	envVar       string
+	defaultValue string
+	get          func(*Config) string
+	set          func(*Config, string) error
+}
+
+// configKeys lists the overridable keys in display order
+var configKeys = []configKey{
+	{
+		name:   "api_key",
+		envVar: "WEATHER_CLI_API_KEY",
+		get:    func(c *Config) string { return c.APIKey },
+		set:    func(c *Config, v string) error { c.APIKey = v; return nil },
+	},
+	{
+		name:   "api_key_cmd",
+		envVar: "WEATHER_CLI_API_KEY_CMD",
+		get:    func(c *Config) string { return c.APIKeyCmd },
+		set:    func(c *Config, v string) error { c.APIKeyCmd = v; return nil },
+	},
+	{
+		name:   "api_key_file",
+		envVar: "WEATHER_CLI_API_KEY_FILE",
+		get:    func(c *Config) string { return c.APIKeyFile },
+		set:    func(c *Config, v string) error { c.APIKeyFile = v; return nil },
+	},
+	{
+		name:         "provider",
+		envVar:       "WEATHER_CLI_PROVIDER",
+		defaultValue: DefaultProvider,
+		get:          func(c *Config) string { return c.Provider },
+		set: func(c *Config, v string) error {
+			if strings.ToLower(v) != DefaultProvider {
+				return fmt.Errorf("unsupported provider '%s'. Only %s is available", v, DefaultProvider)
+			}
+			c.Provider = DefaultProvider
+			return nil
+		},
+	},
+	{
+		name:         "temperature_unit",
+		envVar:       "WEATHER_CLI_UNITS",
+		defaultValue: defaultTempUnit,
+		get:          func(c *Config) string { return c.TemperatureUnit },
+		set: func(c *Config, v string) error {
+			switch strings.ToLower(v) {
+			case "c", "metric":
+				c.TemperatureUnit = "C"
+			case "f", "imperial":
+				c.TemperatureUnit = "F"
+			default:
+				return fmt.Errorf("invalid temperature unit '%s'. Use C or F", v)
+			}
+			return nil
+		},
+	},
+	{
+		name:   "language",
+		envVar: "WEATHER_CLI_LANG",
+		get:    func(c *Config) string { return c.Language },
+		set: func(c *Config, v string) error {
+			lang, err := NormalizeLanguage(v)
+			if err != nil {
+				return err
+			}
+			c.Language = lang
+			return nil
+		},
+	},
+	{
+		name:         "forecast_interval",
+		envVar:       "WEATHER_CLI_FORECAST_INTERVAL",
+		defaultValue: strconv.Itoa(defaultForecastHours),
+		get: func(c *Config) string {
+			if c.ForecastInterval == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.ForecastInterval)
+		},
+		set: func(c *Config, v string) error {
+			hours, err := strconv.Atoi(v)
+			if err != nil || hours <= 0 {
+				return fmt.Errorf("invalid forecast interval '%s'. Must be a positive number of hours", v)
+			}
+			c.ForecastInterval = hours
+			return nil
+		},
+	},
+	{
+		name:   "default_location",
+		envVar: "WEATHER_CLI_DEFAULT_LOCATION",
+		get:    func(c *Config) string { return c.DefaultLocation },
+		set:    func(c *Config, v string) error { c.DefaultLocation = v; return nil },
+	},
+}
+
+// languageCode matches the language codes OpenWeather accepts, e.g. en, de or zh_cn
+var languageCode = regexp.MustCompile(`^[a-z]{2,3}(_[a-z]{2})?$`)
+
+// NormalizeLanguage checks a language code for weather descriptions and returns it
+// in the form OpenWeather expects, so zh-CN becomes zh_cn
+func NormalizeLanguage(lang string) (string, error) {
+	normalized := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "-", "_")
+	if !languageCode.MatchString(normalized) {
+		return "", fmt.Errorf("invalid language '%s'. Use a code such as en, de or zh_cn", lang)
+	}
+	return normalized, nil
+}
+
+// override remembers a value that did not come from the config file, so that saving
+// the config writes back what the file had instead of an environment or flag value
+type override struct {
+	layer     string
+	source    string
+	value     string
+	fileValue string
+}
+
+// ConfigKeys returns the names of the keys that can be overridden
+func ConfigKeys() []string {
+	names := make([]string, len(configKeys))
+	for i, key := range configKeys {
+		names[i] = key.name
+	}
+	return names
+}
+
+// EnvVar returns the environment variable that overrides a config key
+func EnvVar(key string) string {
+	for _, k := range configKeys {
+		if k.name == key {
+			return k.envVar
+		}
+	}
+	return ""
+}
+
+// ApplyOverrides layers defaults, environment variables and flag values over the
+// values loaded from the config file. Precedence is flags > env > file > defaults.
+func ApplyOverrides(cfg *Config, flags map[string]string) error {
+	for name := range flags {
+		if EnvVar(name) == "" {
+			return fmt.Errorf("unknown config key '%s'. Valid keys: %s", name, strings.Join(ConfigKeys(), ", "))
+		}
+	}
+
+	cfg.overrides = make(map[string]override)
+	for _, key := range configKeys {
+		fileValue := key.get(cfg)
+
+		layer, source, value := "", "", ""
+		if fileValue == "" && key.defaultValue != "" {
+			layer, source, value = LayerDefault, "", key.defaultValue
+		}
+		if v := os.Getenv(key.envVar); v != "" {
+			layer, source, value = LayerEnv, key.envVar, v
+		}
+		if v, ok := flags[key.name]; ok {
+			layer, source, value = LayerFlag, "--override "+key.name, v
+		}
+		if layer == "" {
+			continue
+		}
+
+		if err := key.set(cfg, value); err != nil {
+			return &SettingError{Key: key.name, Source: describeLayer(layer, source), Err: err}
+		}
+		cfg.overrides[key.name] = override{layer: layer, source: source, value: key.get(cfg), fileValue: fileValue}
+	}
+	return nil
+}
+
+// SettingError reports an override that is not a valid value for its key. It
+// matches ErrInvalidConfig.
+type SettingError struct {
+	Key    string
+	Source string
+	Err    error
+}
+
+func (e *SettingError) Error() string {
+	return fmt.Sprintf("%s from %s: %v", e.Key, e.Source, e.Err)
+}
+
+func (e *SettingError) Unwrap() []error {
+	return []error{ErrInvalidConfig, e.Err}
+}
+
+// EffectiveSettings returns every overridable key with its resolved value and layer
+func EffectiveSettings(cfg *Config) []Setting {
+	path, _ := ConfigPath()
+	settings := make([]Setting, 0, len(configKeys))
+	for _, key := range configKeys {
+		setting := Setting{Key: key.name, Value: key.get(cfg), Layer: LayerFile, Source: path}
+		if o, ok := cfg.overrides[key.name]; ok && o.value == setting.Value {
+			setting.Layer, setting.Source = o.layer, o.source
+		} else if profile := profileSource(cfg, key.name); profile != "" {
+			setting.Layer, setting.Source = LayerProfile, profile
+		} else if setting.Value == "" {
+			setting.Layer, setting.Source = LayerDefault, ""
+		}
+		settings = append(settings, setting)
+	}
+	return settings
+}
+
+// FileView returns a copy of cfg as it should be written to the config file:
+// values from the environment or flags are replaced by what the file had, unless
+// a command has changed them since they were applied. Changes made while a profile
+// is active are moved into that profile.
+func FileView(cfg *Config) *Config {
+	view := *cfg
+	for _, key := range configKeys {
+		if o, ok := cfg.overrides[key.name]; ok && key.get(cfg) == o.value {
+			restoreFileValue(&view, key.name, o.fileValue)
+		}
+	}
+	if cfg.profile != nil {
+		unapplyProfile(&view, cfg.profile)
+	}
+	return &view
+}
+
+// restoreFileValue sets a key to its raw file value, which may be empty
+func restoreFileValue(cfg *Config, name, value string) {
+	switch name {
+	case "api_key":
+		cfg.APIKey = value
+	case "api_key_cmd":
+		cfg.APIKeyCmd = value
+	case "api_key_file":
+		cfg.APIKeyFile = value
+	case "provider":
+		cfg.Provider = value
+	case "temperature_unit":
+		cfg.TemperatureUnit = value
+	case "language":
+		cfg.Language = value
+	case "forecast_interval":
+		cfg.ForecastInterval, _ = strconv.Atoi(value)
+	case "default_location":
+		cfg.DefaultLocation = value
+	}
+}
+
+// describeLayer formats a layer and its source for messages
+func describeLayer(layer, source string) string {
+	if source == "" {
+		return layer
+	}
+	return fmt.Sprintf("%s %s", layer, source)
+}
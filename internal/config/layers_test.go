@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// clearEnvOverrides unsets every override environment variable for the test
+func clearEnvOverrides(t *testing.T) {
+	for _, key := range configKeys {
+		t.Setenv(key.envVar, "")
+	}
+}
+
+func TestApplyOverridesPrecedence(t *testing.T) {
+	clearEnvOverrides(t)
+	t.Setenv("WEATHER_CLI_API_KEY", "env-key")
+	t.Setenv("WEATHER_CLI_UNITS", "imperial")
+	t.Setenv("WEATHER_CLI_FORECAST_INTERVAL", "12")
+
+	cfg := &Config{APIKey: "file-key", TemperatureUnit: "C", ForecastInterval: 24, DefaultLocation: "Tokyo"}
+	if err := ApplyOverrides(cfg, map[string]string{"forecast_interval": "6"}); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+
+	got := make(map[string]Setting)
+	for _, s := range EffectiveSettings(cfg) {
+		got[s.Key] = s
+	}
+
+	want := map[string]Setting{
+		"api_key":           {Key: "api_key", Value: "env-key", Layer: LayerEnv, Source: "WEATHER_CLI_API_KEY"},
+		"provider":          {Key: "provider", Value: DefaultProvider, Layer: LayerDefault},
+		"temperature_unit":  {Key: "temperature_unit", Value: "F", Layer: LayerEnv, Source: "WEATHER_CLI_UNITS"},
+		"forecast_interval": {Key: "forecast_interval", Value: "6", Layer: LayerFlag, Source: "--override forecast_interval"},
+	}
+	for key, w := range want {
+		if !reflect.DeepEqual(got[key], w) {
+			t.Errorf("EffectiveSettings()[%s] = %+v, want %+v", key, got[key], w)
+		}
+	}
+	if s := got["default_location"]; s.Value != "Tokyo" || s.Layer != LayerFile {
+		t.Errorf("EffectiveSettings()[default_location] = %+v, want Tokyo from the file", s)
+	}
+	if cfg.APIKey != "env-key" || cfg.TemperatureUnit != "F" || cfg.ForecastInterval != 6 {
+		t.Errorf("ApplyOverrides() did not update the config: %+v", cfg)
+	}
+}
+
+func TestApplyOverridesInvalid(t *testing.T) {
+	clearEnvOverrides(t)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		flags   map[string]string
+		invalid bool
+	}{
+		{"Unknown key", nil, map[string]string{"colour": "blue"}, false},
+		{"Invalid unit from env", map[string]string{"WEATHER_CLI_UNITS": "kelvin"}, nil, true},
+		{"Invalid interval from flag", nil, map[string]string{"forecast_interval": "-3"}, true},
+		{"Unsupported provider", map[string]string{"WEATHER_CLI_PROVIDER": "acme"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			err := ApplyOverrides(&Config{}, tt.flags)
+			if err == nil {
+				t.Fatalf("ApplyOverrides() should fail")
+			}
+			if errors.Is(err, ErrInvalidConfig) != tt.invalid {
+				t.Errorf("errors.Is(%v, ErrInvalidConfig) = %v, want %v", err, !tt.invalid, tt.invalid)
+			}
+		})
+	}
+}
+
+func TestSaveConfigKeepsOverridesOutOfFile(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	clearEnvOverrides(t)
+	t.Setenv("WEATHER_CLI_API_KEY", "env-key")
+	t.Setenv("WEATHER_CLI_UNITS", "F")
+
+	cfg := &Config{APIKey: "file-key", TemperatureUnit: "C", ForecastInterval: 24}
+	if err := ApplyOverrides(cfg, nil); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+
+	// A command changes one overridden value and one file value
+	cfg.SetTemperatureUnit("C")
+	cfg.SetForecastInterval(48)
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	saved, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if saved.APIKey != "file-key" {
+		t.Errorf("SaveConfig() wrote the API key from the environment: %s", saved.APIKey)
+	}
+	if saved.TemperatureUnit != "C" || saved.ForecastInterval != 48 {
+		t.Errorf("SaveConfig() lost values changed by a command: %+v", saved)
+	}
+	if saved.Provider != "" {
+		t.Errorf("SaveConfig() wrote the default provider: %s", saved.Provider)
+	}
+}
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		lang    string
+		want    string
+		wantErr bool
+	}{
+		{"en", "en", false},
+		{" DE ", "de", false},
+		{"zh-CN", "zh_cn", false},
+		{"pt_BR", "pt_br", false},
+		{"german", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeLanguage(tt.lang)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeLanguage(%q) = %q, %v, want %q, wantErr %v", tt.lang, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
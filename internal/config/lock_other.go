@@ -0,0 +1,11 @@
+//go:build !unix
+
+package config
+
+import "time"
+
+// lockFile is a no-op on platforms without flock; writes are still atomic,
+// but concurrent read-modify-write sequences are not serialized
+func lockFile(path string, timeout time.Duration) (func(), error) {
+	return func() {}, nil
+}
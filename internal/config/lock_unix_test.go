@@ -0,0 +1,30 @@
+//go:build unix
+
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockFileTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json.lock")
+
+	unlock, err := lockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+
+	if _, err := lockFile(path, 100*time.Millisecond); err == nil || !strings.Contains(err.Error(), "locked by another process") {
+		t.Errorf("Second lockFile() error = %v, want a lock timeout", err)
+	}
+
+	unlock()
+	unlock, err = lockFile(path, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("lockFile() after unlock error = %v", err)
+	}
+	unlock()
+}
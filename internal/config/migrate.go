@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// Upgrade describes a config file upgraded by MigrateConfig
+type Upgrade struct {
+	From, To int
+	// Backup is the path of the original file
+	Backup string
+	// Changes describes the values the upgrade had to repair
+	Changes []string
+}
+
+// MigrateConfig upgrades a config file written with an older schema version to the
+// current one. The original file is kept as config.json.v<from>.bak. It returns nil
+// when the file did not need an upgrade.
+func MigrateConfig() (*Upgrade, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if !needsMigration(path) {
+		return nil, nil
+	}
+
+	unlock, err := lockFile(path+".lock", LockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error locking config file: %w", err)
+	}
+	defer unlock()
+
+	// Another process may have upgraded the file while we waited for the lock
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
+	doc, problems := readDocument(path, data)
+	if doc == nil {
+		return nil, &ValidationError{File: path, Problems: problems}
+	}
+	if doc.version == SchemaVersion {
+		return nil, nil
+	}
+
+	upgrade := &Upgrade{From: doc.version, To: SchemaVersion}
+	upgrade.Changes = doc.migrate()
+	cfg, problems := doc.decode()
+	if cfg == nil {
+		return nil, &ValidationError{File: path, Problems: errorsOnly(problems)}
+	}
+
+	upgrade.Backup = fmt.Sprintf("%s.v%d%s", path, upgrade.From, BackupSuffix)
+	if err := replaceFile(upgrade.Backup, data); err != nil {
+		return nil, fmt.Errorf("error writing backup: %w", err)
+	}
+	if err := SaveConfig(cfg); err != nil {
+		return nil, err
+	}
+	return upgrade, nil
+}
+
+// needsMigration reports whether path holds a readable config with an older schema
+func needsMigration(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	doc, _ := readDocument(path, data)
+	return doc != nil && doc.version < SchemaVersion
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+)
+
+// diacritics maps accented Latin letters to their base letters
+var diacritics = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'į': "i", 'ı': "i",
+	'ł': "l", 'ľ': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ŕ': "r", 'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ș': "s", 'ß': "ss",
+	'ť': "t", 'ţ': "t", 'ț': "t", 'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// NormalizeName folds case, diacritics and repeated whitespace so that
+// "Zürich", "zurich" and " ZURICH " compare equal. Location names and aliases
+// are looked up and checked for clashes in this form.
+func NormalizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.Join(strings.Fields(name), " ") {
+		r = unicode.ToLower(r)
+		if base, ok := diacritics[r]; ok {
+			sb.WriteString(base)
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,22 @@
+package config
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Tokyo", "tokyo"},
+		{"  São   Paulo ", "sao paulo"},
+		{"Zürich", "zurich"},
+		{"KRAKÓW", "krakow"},
+		{"Straße", "strasse"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeName(tt.input); got != tt.want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Built-in notification targets. Any http or https URL is a generic webhook
+// target, and the names in the notifiers section are targets too.
+const (
+	NotifyStdout  = "stdout"
+	NotifyDesktop = "desktop"
+)
+
+// NotifierTypes are the kinds of notifier that can be configured
+var NotifierTypes = []string{"webhook", "slack", "discord", "email"}
+
+// DefaultSMTPPort is the mail submission port used when smtp_port is not set
+const DefaultSMTPPort = 587
+
+// Notifier is a named notification target, e.g. a Slack incoming webhook or a
+// mailbox reached through an SMTP server
+type Notifier struct {
+	// Type is one of NotifierTypes
+	Type string `json:"type"`
+	// URL is the webhook URL of the webhook, slack and discord types
+	URL string `json:"url,omitempty"`
+
+	SMTPHost string `json:"smtp_host,omitempty"`
+	SMTPPort int    `json:"smtp_port,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Password is the SMTP password in plaintext, or PasswordCmd prints it
+	Password    string   `json:"password,omitempty"`
+	PasswordCmd string   `json:"password_cmd,omitempty"`
+	From        string   `json:"from,omitempty"`
+	To          []string `json:"to,omitempty"`
+}
+
+// CheckNotifyTarget reports whether target is somewhere notifications can be sent:
+// stdout, desktop, a webhook URL or the name of a configured notifier
+func CheckNotifyTarget(cfg *Config, target string) error {
+	switch target {
+	case NotifyStdout, NotifyDesktop:
+		return nil
+	}
+	if _, ok := cfg.Notifiers[target]; ok {
+		return nil
+	}
+	if isWebhookURL(target) {
+		return nil
+	}
+	names := append([]string{NotifyStdout, NotifyDesktop}, sortedKeys(cfg.Notifiers)...)
+	return fmt.Errorf("unknown notification target %q. Use %s or a webhook URL", target, strings.Join(names, ", "))
+}
+
+// isWebhookURL reports whether target is an absolute http or https URL
+func isWebhookURL(target string) bool {
+	u, err := url.Parse(target)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateNotifiers checks the settings of each configured notifier
+func validateNotifiers(cfg *Config, report func(path, format string, args ...any)) {
+	for _, name := range sortedKeys(cfg.Notifiers) {
+		path := childPath("$.notifiers", name)
+		n := cfg.Notifiers[name]
+		if name == NotifyStdout || name == NotifyDesktop || isWebhookURL(name) {
+			report(path, "%q cannot be used as a notifier name", name)
+		}
+
+		switch n.Type {
+		case "webhook", "slack", "discord":
+			if !isWebhookURL(n.URL) {
+				report(path+".url", "must be an http or https URL, got %q", n.URL)
+			}
+		case "email":
+			if n.SMTPHost == "" {
+				report(path+".smtp_host", "must not be empty")
+			}
+			if n.SMTPPort < 0 || n.SMTPPort > 65535 {
+				report(path+".smtp_port", "must be a port number, got %d", n.SMTPPort)
+			}
+			if !strings.Contains(n.From, "@") {
+				report(path+".from", "must be an email address, got %q", n.From)
+			}
+			if len(n.To) == 0 {
+				report(path+".to", "must list at least one recipient")
+			}
+			for i, to := range n.To {
+				if !strings.Contains(to, "@") {
+					report(fmt.Sprintf("%s.to[%d]", path, i), "must be an email address, got %q", to)
+				}
+			}
+			if n.Password != "" && n.PasswordCmd != "" {
+				report(path, "must not set both password and password_cmd")
+			}
+		default:
+			report(path+".type", "must be one of %s, got %q", strings.Join(NotifierTypes, ", "), n.Type)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ConfigEnvVar names an environment variable holding the config file path
+const ConfigEnvVar = "WEATHER_CLI_CONFIG"
+
+// appDirName is the directory created under the XDG config and cache directories
+const appDirName = "weather-cli"
+
+// legacyDirName is the directory under the home directory used by older versions
+const legacyDirName = ".weather-cli"
+
+// configPath is the config file set with SetConfigPath; when empty the path is
+// resolved from the environment each time it is needed
+var configPath string
+
+// SetConfigPath makes LoadConfig and SaveConfig use the given file, e.g. from --config
+func SetConfigPath(path string) {
+	configPath = path
+}
+
+// ConfigPath returns the config file in use: the path set with SetConfigPath, then
+// $WEATHER_CLI_CONFIG, then the config file in the XDG config directory
+func ConfigPath() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+	if path := os.Getenv(ConfigEnvVar); path != "" {
+		return path, nil
+	}
+	return defaultConfigPath()
+}
+
+// ConfigPathOverridden reports whether the config file was chosen with SetConfigPath
+// or $WEATHER_CLI_CONFIG rather than looked up in the XDG config directory
+func ConfigPathOverridden() bool {
+	return configPath != "" || os.Getenv(ConfigEnvVar) != ""
+}
+
+// ConfigExists reports whether the config file has been created
+func ConfigExists() bool {
+	path, err := ConfigPath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// GetConfigDir returns the directory where the config file is stored
+func GetConfigDir() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(path), nil
+}
+
+// GetCacheDir returns the directory for cached data: $XDG_CACHE_HOME/weather-cli,
+// falling back to ~/.cache/weather-cli
+func GetCacheDir() (string, error) {
+	base, err := xdgDir("XDG_CACHE_HOME", ".cache")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, appDirName), nil
+}
+
+// defaultConfigPath returns the config file in $XDG_CONFIG_HOME/weather-cli, falling
+// back to ~/.config/weather-cli: the first of config.json, config.yaml, config.yml and
+// config.toml that exists, or config.json for a new file
+func defaultConfigPath() (string, error) {
+	base, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, appDirName)
+	for _, name := range configFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return filepath.Join(dir, name), nil
+		}
+	}
+	return filepath.Join(dir, configFileNames[0]), nil
+}
+
+// xdgDir returns the directory in an XDG environment variable or, when it is unset
+// or relative as the specification requires, the fallback under the home directory
+func xdgDir(envVar, fallback string) (string, error) {
+	if dir := os.Getenv(envVar); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error getting user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, fallback), nil
+}
+
+// MigrateLegacyConfig moves config.json from ~/.weather-cli to the XDG config directory
+// when the default location is in use and has no config yet. It returns the paths the
+// file was moved between, or empty strings when there was nothing to migrate.
+func MigrateLegacyConfig() (from, to string, err error) {
+	if ConfigPathOverridden() {
+		return "", "", nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("error getting user home directory: %w", err)
+	}
+	legacyDir := filepath.Join(homeDir, legacyDirName)
+	from = filepath.Join(legacyDir, "config.json")
+	if _, err := os.Stat(from); err != nil {
+		return "", "", nil
+	}
+
+	to, err = defaultConfigPath()
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := os.Stat(to); err == nil {
+		// A config in the new location wins; the legacy one is left for the user to remove
+		return "", "", nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(to), 0750); err != nil {
+		return "", "", fmt.Errorf("error creating config directory: %w", err)
+	}
+	if err := moveFile(from, to); err != nil {
+		return "", "", fmt.Errorf("error migrating config file: %w", err)
+	}
+
+	// The old forecast cache is only an optimization, so it is dropped rather than moved
+	os.RemoveAll(filepath.Join(legacyDir, "cache"))
+	os.Remove(legacyDir)
+
+	return from, to, nil
+}
+
+// moveFile renames a file, copying it when the destination is on another file system
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupPathEnvironment points HOME and the XDG variables at a temporary directory
+// and clears any config path override
+func setupPathEnvironment(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	t.Setenv(ConfigEnvVar, "")
+
+	oldConfigPath := configPath
+	configPath = ""
+	t.Cleanup(func() { configPath = oldConfigPath })
+
+	return home
+}
+
+func TestConfigPath(t *testing.T) {
+	home := setupPathEnvironment(t)
+
+	assertPath := func(want string) {
+		t.Helper()
+		got, err := ConfigPath()
+		if err != nil {
+			t.Fatalf("ConfigPath() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("ConfigPath() = %s, want %s", got, want)
+		}
+	}
+
+	assertPath(filepath.Join(home, ".config", "weather-cli", "config.json"))
+
+	// Relative XDG directories are ignored as the specification requires
+	t.Setenv("XDG_CONFIG_HOME", "relative/config")
+	assertPath(filepath.Join(home, ".config", "weather-cli", "config.json"))
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	assertPath("/xdg/config/weather-cli/config.json")
+
+	t.Setenv(ConfigEnvVar, "/env/weather.json")
+	assertPath("/env/weather.json")
+
+	SetConfigPath("/flag/weather.json")
+	assertPath("/flag/weather.json")
+
+	dir, err := GetConfigDir()
+	if err != nil || dir != "/flag" {
+		t.Errorf("GetConfigDir() = %s, %v, want /flag", dir, err)
+	}
+}
+
+func TestGetCacheDir(t *testing.T) {
+	home := setupPathEnvironment(t)
+
+	if dir, err := GetCacheDir(); err != nil || dir != filepath.Join(home, ".cache", "weather-cli") {
+		t.Errorf("GetCacheDir() = %s, %v", dir, err)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+	if dir, err := GetCacheDir(); err != nil || dir != "/xdg/cache/weather-cli" {
+		t.Errorf("GetCacheDir() = %s, %v, want /xdg/cache/weather-cli", dir, err)
+	}
+}
+
+func TestSaveConfigCreatesDirectory(t *testing.T) {
+	home := setupPathEnvironment(t)
+
+	if err := SaveConfig(&Config{TemperatureUnit: "C"}); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "weather-cli", "config.json")); err != nil {
+		t.Errorf("SaveConfig() did not create the config file: %v", err)
+	}
+}
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	home := setupPathEnvironment(t)
+
+	legacyDir := filepath.Join(home, ".weather-cli")
+	if err := os.MkdirAll(filepath.Join(legacyDir, "cache"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	legacy := []byte(`{"temperature_unit":"F","forecast_interval":12}`)
+	if err := os.WriteFile(filepath.Join(legacyDir, "config.json"), legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	from, to, err := MigrateLegacyConfig()
+	if err != nil {
+		t.Fatalf("MigrateLegacyConfig() error = %v", err)
+	}
+	if from != filepath.Join(legacyDir, "config.json") || to != filepath.Join(home, ".config", "weather-cli", "config.json") {
+		t.Errorf("MigrateLegacyConfig() = %s, %s", from, to)
+	}
+	if _, err := os.Stat(legacyDir); !os.IsNotExist(err) {
+		t.Errorf("MigrateLegacyConfig() should remove the empty legacy directory")
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.TemperatureUnit != "F" || cfg.ForecastInterval != 12 {
+		t.Errorf("LoadConfig() after migration = %+v", cfg)
+	}
+
+	// Nothing is left to migrate the second time
+	if from, _, err := MigrateLegacyConfig(); err != nil || from != "" {
+		t.Errorf("MigrateLegacyConfig() second run = %s, %v", from, err)
+	}
+}
+
+func TestMigrateLegacyConfigSkipped(t *testing.T) {
+	home := setupPathEnvironment(t)
+
+	legacyFile := filepath.Join(home, ".weather-cli", "config.json")
+	os.MkdirAll(filepath.Dir(legacyFile), 0750)
+	os.WriteFile(legacyFile, []byte(`{}`), 0600)
+
+	// An explicit config path means the legacy file is not the one in use
+	t.Setenv(ConfigEnvVar, filepath.Join(home, "other.json"))
+	if from, _, err := MigrateLegacyConfig(); err != nil || from != "" {
+		t.Errorf("MigrateLegacyConfig() with %s set = %s, %v", ConfigEnvVar, from, err)
+	}
+	t.Setenv(ConfigEnvVar, "")
+
+	// An existing config in the new location is never overwritten
+	newFile := filepath.Join(home, ".config", "weather-cli", "config.json")
+	os.MkdirAll(filepath.Dir(newFile), 0750)
+	os.WriteFile(newFile, []byte(`{"temperature_unit":"C"}`), 0600)
+	if from, _, err := MigrateLegacyConfig(); err != nil || from != "" {
+		t.Errorf("MigrateLegacyConfig() with an existing config = %s, %v", from, err)
+	}
+	if _, err := os.Stat(legacyFile); err != nil {
+		t.Errorf("The legacy config should be left in place: %v", err)
+	}
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// DefaultProfile names the top-level settings, which apply when no profile is active
+const DefaultProfile = "default"
+
+// ProfileEnvVar names an environment variable selecting the active profile
+const ProfileEnvVar = "WEATHER_CLI_PROFILE"
+
+// Profile holds settings that replace the top-level ones while the profile is active.
+// Settings left empty are inherited from the base profile, or from the top level when
+// there is no base. Locations is a pointer so that a profile can hold an empty list.
+type Profile struct {
+	Base             string      `json:"base,omitempty"`
+	Locations        *[]Location `json:"locations,omitempty"`
+	TemperatureUnit  string      `json:"temperature_unit,omitempty"`
+	ForecastInterval int         `json:"forecast_interval,omitempty"`
+	APIKey           string      `json:"api_key,omitempty"`
+	DefaultLocation  string      `json:"default_location,omitempty"`
+	Provider         string      `json:"provider,omitempty"`
+	Language         string      `json:"language,omitempty"`
+	APIKeyCmd        string      `json:"api_key_cmd,omitempty"`
+	APIKeyFile       string      `json:"api_key_file,omitempty"`
+	APIKeyKeyring    bool        `json:"api_key_keyring,omitempty"`
+}
+
+// activeProfile remembers how a profile was applied, so that saving writes the
+// settings changed since then into the profile instead of the top level
+type activeProfile struct {
+	name string
+	// root holds the top-level settings and applied the settings in effect afterwards
+	root    Profile
+	applied Profile
+	// sources maps a config key to the profile that set it
+	sources map[string]string
+}
+
+// profileSettings are the Profile fields holding settings. Each has the same name
+// as the Config field it replaces.
+var profileSettings = func() []reflect.StructField {
+	var fields []reflect.StructField
+	t := reflect.TypeOf(Profile{})
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Name != "Base" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}()
+
+// UseProfile applies a profile's settings over the top-level ones. The profile is
+// name, or when empty $WEATHER_CLI_PROFILE, or else current_profile from the config
+// file. Commands then read and change the profile's settings.
+func UseProfile(cfg *Config, name string) error {
+	if name == "" {
+		name = os.Getenv(ProfileEnvVar)
+	}
+	if name == "" {
+		name = cfg.CurrentProfile
+	}
+	if name == "" || name == DefaultProfile {
+		return nil
+	}
+
+	chain, err := profileChain(cfg, name)
+	if err != nil {
+		return err
+	}
+
+	active := &activeProfile{name: name, root: settingsOf(cfg), sources: make(map[string]string)}
+	// Bases are applied first so that each profile overrides the one it inherits from
+	for i := len(chain) - 1; i >= 0; i-- {
+		profile := cfg.Profiles[chain[i]]
+		// The API key settings are alternative sources of one key, so a profile that
+		// sets any of them replaces the inherited ones
+		if setsAPIKey(profile) {
+			applySettings(cfg, Profile{}, false, apiKeySettings...)
+			for _, key := range apiKeySettings {
+				active.sources[key] = chain[i]
+			}
+		}
+		for _, key := range applySettings(cfg, profile, true) {
+			active.sources[key] = chain[i]
+		}
+	}
+	active.applied = settingsOf(cfg)
+	cfg.profile = active
+	return nil
+}
+
+// ActiveProfile returns the name of the profile in use, or an empty string
+func ActiveProfile(cfg *Config) string {
+	if cfg.profile == nil {
+		return ""
+	}
+	return cfg.profile.name
+}
+
+// ProfileNames returns the names of the profiles in the config, sorted
+func ProfileNames(cfg *Config) []string {
+	return sortedKeys(cfg.Profiles)
+}
+
+// profileChain returns a profile followed by the profiles it inherits from
+func profileChain(cfg *Config, name string) ([]string, error) {
+	var chain []string
+	for name != "" && name != DefaultProfile {
+		if slices.Contains(chain, name) {
+			return nil, fmt.Errorf("profile '%s' inherits from itself: %s", chain[0], strings.Join(append(chain, name), " -> "))
+		}
+		profile, ok := cfg.Profiles[name]
+		if !ok {
+			if len(chain) == 0 {
+				return nil, fmt.Errorf("unknown profile '%s'. Available profiles: %s", name, strings.Join(append([]string{DefaultProfile}, ProfileNames(cfg)...), ", "))
+			}
+			return nil, fmt.Errorf("profile '%s' has unknown base '%s'", chain[len(chain)-1], name)
+		}
+		chain = append(chain, name)
+		name = profile.Base
+	}
+	return chain, nil
+}
+
+// apiKeySettings are the config keys of the API key and its secret sources
+var apiKeySettings = []string{"api_key", "api_key_cmd", "api_key_file", "api_key_keyring"}
+
+// setsAPIKey reports whether p sets the API key or one of its sources
+func setsAPIKey(p Profile) bool {
+	return p.APIKey != "" || p.APIKeyCmd != "" || p.APIKeyFile != "" || p.APIKeyKeyring
+}
+
+// settingsOf copies the settings a profile can replace from cfg
+func settingsOf(cfg *Config) Profile {
+	var p Profile
+	profile := reflect.ValueOf(&p).Elem()
+	config := reflect.ValueOf(cfg).Elem()
+	for _, field := range profileSettings {
+		setSetting(profile.FieldByName(field.Name), config.FieldByName(field.Name))
+	}
+	locations := cloneLocations(*p.Locations)
+	p.Locations = &locations
+	return p
+}
+
+// applySettings copies the settings of p to cfg, only the ones p sets when onlySet
+// is true, and returns the config keys it set. keys limits the settings copied.
+func applySettings(cfg *Config, p Profile, onlySet bool, keys ...string) []string {
+	var set []string
+	profile := reflect.ValueOf(p)
+	config := reflect.ValueOf(cfg).Elem()
+	for _, field := range profileSettings {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		value := profile.FieldByName(field.Name)
+		if onlySet && value.IsZero() || len(keys) > 0 && !slices.Contains(keys, name) {
+			continue
+		}
+		config.FieldByName(field.Name).Set(settingValue(value))
+		set = append(set, name)
+	}
+	// Commands change locations in place, which must not reach the profile's copy
+	cfg.Locations = cloneLocations(cfg.Locations)
+	return set
+}
+
+// settingValue returns the setting a Profile field holds. A nil pointer is unset,
+// which is the zero value when it is applied.
+func settingValue(field reflect.Value) reflect.Value {
+	if field.Kind() != reflect.Pointer {
+		return field
+	}
+	if field.IsNil() {
+		return reflect.Zero(field.Type().Elem())
+	}
+	return field.Elem()
+}
+
+// setSetting stores value in a Profile field. Pointer fields point to a copy, which
+// is an empty list rather than nil for an empty slice so that it is written out.
+func setSetting(field, value reflect.Value) {
+	if field.Kind() != reflect.Pointer {
+		field.Set(value)
+		return
+	}
+	if value.Kind() == reflect.Slice && value.IsNil() {
+		value = reflect.MakeSlice(value.Type(), 0, 0)
+	}
+	ptr := reflect.New(value.Type())
+	ptr.Elem().Set(value)
+	field.Set(ptr)
+}
+
+// unapplyProfile moves the settings changed since the profile was applied into the
+// profile and restores the top-level settings, for writing view to the config file
+func unapplyProfile(view *Config, active *activeProfile) {
+	if profile, ok := view.Profiles[active.name]; ok {
+		current := reflect.ValueOf(settingsOf(view))
+		applied := reflect.ValueOf(active.applied)
+		target := reflect.ValueOf(&profile).Elem()
+		changed := false
+		for _, field := range profileSettings {
+			value := settingValue(current.FieldByName(field.Name))
+			if !reflect.DeepEqual(value.Interface(), settingValue(applied.FieldByName(field.Name)).Interface()) {
+				setSetting(target.FieldByName(field.Name), value)
+				changed = true
+			}
+		}
+		if changed {
+			view.Profiles = maps.Clone(view.Profiles)
+			view.Profiles[active.name] = profile
+		}
+	}
+	applySettings(view, active.root, false)
+}
+
+// profileSource returns the profile that set a config key, or an empty string
+func profileSource(cfg *Config, key string) string {
+	if cfg.profile == nil {
+		return ""
+	}
+	return cfg.profile.sources[key]
+}
+
+// cloneLocations copies locations including their aliases and tags
+func cloneLocations(locations []Location) []Location {
+	if locations == nil {
+		return nil
+	}
+	clone := make([]Location, len(locations))
+	for i, loc := range locations {
+		loc.Aliases = slices.Clone(loc.Aliases)
+		loc.Tags = slices.Clone(loc.Tags)
+		clone[i] = loc
+	}
+	return clone
+}
+
+// CreateProfile adds an empty profile that inherits from base, or from the top-level
+// settings when base is empty
+func (c *Config) CreateProfile(name, base string) error {
+	if err := checkProfileName(name); err != nil {
+		return err
+	}
+	if _, exists := c.Profiles[name]; exists {
+		return fmt.Errorf("profile '%s' already exists", name)
+	}
+	if base == DefaultProfile {
+		base = ""
+	}
+	if base != "" {
+		if _, ok := c.Profiles[base]; !ok {
+			return fmt.Errorf("unknown base profile '%s'", base)
+		}
+	}
+
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]Profile)
+	}
+	c.Profiles[name] = Profile{Base: base}
+	return nil
+}
+
+// DeleteProfile removes a profile that no other profile inherits from
+func (c *Config) DeleteProfile(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile '%s'", name)
+	}
+	var children []string
+	for _, other := range ProfileNames(c) {
+		if c.Profiles[other].Base == name {
+			children = append(children, other)
+		}
+	}
+	if len(children) > 0 {
+		return fmt.Errorf("profile '%s' is the base of %s", name, strings.Join(children, ", "))
+	}
+
+	delete(c.Profiles, name)
+	if c.CurrentProfile == name {
+		c.CurrentProfile = ""
+	}
+	return nil
+}
+
+// SetCurrentProfile sets the profile used when none is selected with --profile or
+// $WEATHER_CLI_PROFILE. DefaultProfile selects the top-level settings.
+func (c *Config) SetCurrentProfile(name string) error {
+	if name == DefaultProfile {
+		c.CurrentProfile = ""
+		return nil
+	}
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("unknown profile '%s'", name)
+	}
+	c.CurrentProfile = name
+	return nil
+}
+
+// checkProfileName rejects names that cannot be used for a profile
+func checkProfileName(name string) error {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return fmt.Errorf("profile name must not be empty")
+	case name == DefaultProfile:
+		return fmt.Errorf("'%s' is reserved for the top-level settings", DefaultProfile)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// profileConfig returns a config with a work profile inheriting from a base profile
+func profileConfig() *Config {
+	return &Config{
+		Locations:        []Location{{Name: "Home", Latitude: 1, Longitude: 2}},
+		TemperatureUnit:  "C",
+		ForecastInterval: 24,
+		APIKey:           "root-key",
+		DefaultLocation:  "Home",
+		Profiles: map[string]Profile{
+			"base": {TemperatureUnit: "F", APIKey: "base-key"},
+			"work": {
+				Base:            "base",
+				Locations:       &[]Location{{Name: "Office", Latitude: 3, Longitude: 4, Tags: []string{"work"}}},
+				DefaultLocation: "Office",
+				APIKey:          "work-key",
+			},
+		},
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "")
+	clearEnvOverrides(t)
+
+	cfg := profileConfig()
+	if err := UseProfile(cfg, "work"); err != nil {
+		t.Fatalf("UseProfile() error = %v", err)
+	}
+	if ActiveProfile(cfg) != "work" {
+		t.Errorf("ActiveProfile() = %q, want work", ActiveProfile(cfg))
+	}
+	if cfg.APIKey != "work-key" || cfg.TemperatureUnit != "F" || cfg.ForecastInterval != 24 || cfg.DefaultLocation != "Office" {
+		t.Errorf("UseProfile() settings = %+v, want work, base and top-level values", cfg)
+	}
+	if len(cfg.Locations) != 1 || cfg.Locations[0].Name != "Office" {
+		t.Errorf("UseProfile() locations = %+v, want the work locations", cfg.Locations)
+	}
+
+	if err := ApplyOverrides(cfg, nil); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+	got := make(map[string]Setting)
+	for _, s := range EffectiveSettings(cfg) {
+		got[s.Key] = s
+	}
+	if s := got["api_key"]; s.Layer != LayerProfile || s.Source != "work" {
+		t.Errorf("EffectiveSettings()[api_key] = %+v, want the work profile", s)
+	}
+	if s := got["temperature_unit"]; s.Layer != LayerProfile || s.Source != "base" {
+		t.Errorf("EffectiveSettings()[temperature_unit] = %+v, want the base profile", s)
+	}
+	if s := got["forecast_interval"]; s.Layer != LayerFile {
+		t.Errorf("EffectiveSettings()[forecast_interval] = %+v, want the file", s)
+	}
+}
+
+func TestUseProfileSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		env     string
+		current string
+		want    string
+	}{
+		{"None", "", "", "", ""},
+		{"Current profile", "", "", "work", "work"},
+		{"Environment wins over current", "", "base", "work", "base"},
+		{"Flag wins over environment", "work", "base", "base", "work"},
+		{"Default selects the top level", "default", "", "work", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ProfileEnvVar, tt.env)
+			cfg := profileConfig()
+			cfg.CurrentProfile = tt.current
+			if err := UseProfile(cfg, tt.flag); err != nil {
+				t.Fatalf("UseProfile() error = %v", err)
+			}
+			if got := ActiveProfile(cfg); got != tt.want {
+				t.Errorf("ActiveProfile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUseProfileErrors(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "")
+
+	cfg := profileConfig()
+	err := UseProfile(cfg, "travel")
+	if err == nil || !strings.Contains(err.Error(), "Available profiles: default, base, work") {
+		t.Errorf("UseProfile() with an unknown profile error = %v, want the available profiles", err)
+	}
+
+	cfg.Profiles["base"] = Profile{Base: "work"}
+	if err := UseProfile(cfg, "work"); err == nil || !strings.Contains(err.Error(), "work -> base -> work") {
+		t.Errorf("UseProfile() with a cycle error = %v, want the cycle", err)
+	}
+	if cfg.profile != nil || cfg.APIKey != "root-key" {
+		t.Errorf("UseProfile() changed the config on error: %+v", cfg)
+	}
+}
+
+func TestSaveConfigWritesProfileChanges(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	clearEnvOverrides(t)
+	t.Setenv(ProfileEnvVar, "")
+	t.Setenv("WEATHER_CLI_UNITS", "C")
+
+	if err := SaveConfig(profileConfig()); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if err := UseProfile(cfg, "work"); err != nil {
+		t.Fatalf("UseProfile() error = %v", err)
+	}
+	if err := ApplyOverrides(cfg, nil); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+
+	// Inherited and overridden settings stay where they are; changed ones move into the profile
+	cfg.Locations[0].Tags = append(cfg.Locations[0].Tags, "downtown")
+	cfg.AddLocation("Client", 5, 6)
+	cfg.SetForecastInterval(12)
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	saved, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	want := profileConfig()
+	if !reflect.DeepEqual(settingsOf(saved), settingsOf(want)) {
+		t.Errorf("SaveConfig() changed the top-level settings:\n got %+v\nwant %+v", settingsOf(saved), settingsOf(want))
+	}
+	if !reflect.DeepEqual(saved.Profiles["base"], want.Profiles["base"]) {
+		t.Errorf("SaveConfig() changed the base profile: %+v", saved.Profiles["base"])
+	}
+	work := want.Profiles["work"]
+	work.Locations = &[]Location{
+		{Name: "Office", Latitude: 3, Longitude: 4, Tags: []string{"work", "downtown"}},
+		{Name: "Client", Latitude: 5, Longitude: 6},
+	}
+	work.ForecastInterval = 12
+	if !reflect.DeepEqual(saved.Profiles["work"], work) {
+		t.Errorf("SaveConfig() work profile =\n %+v\nwant\n %+v", saved.Profiles["work"], work)
+	}
+}
+
+func TestProfileCommands(t *testing.T) {
+	cfg := &Config{}
+
+	if err := cfg.CreateProfile("home", ""); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	if err := cfg.CreateProfile("travel", "home"); err != nil {
+		t.Fatalf("CreateProfile() with a base error = %v", err)
+	}
+	for _, tt := range []struct{ name, base string }{{"home", ""}, {"default", ""}, {" ", ""}, {"work", "office"}} {
+		if err := cfg.CreateProfile(tt.name, tt.base); err == nil {
+			t.Errorf("CreateProfile(%q, %q) error = nil, want an error", tt.name, tt.base)
+		}
+	}
+	if got := ProfileNames(cfg); !reflect.DeepEqual(got, []string{"home", "travel"}) {
+		t.Errorf("ProfileNames() = %v", got)
+	}
+
+	if err := cfg.SetCurrentProfile("travel"); err != nil || cfg.CurrentProfile != "travel" {
+		t.Errorf("SetCurrentProfile() error = %v, current = %q", err, cfg.CurrentProfile)
+	}
+	if err := cfg.SetCurrentProfile("office"); err == nil {
+		t.Error("SetCurrentProfile() with an unknown profile error = nil")
+	}
+
+	if err := cfg.DeleteProfile("home"); err == nil || !strings.Contains(err.Error(), "base of travel") {
+		t.Errorf("DeleteProfile() of a base error = %v", err)
+	}
+	if err := cfg.DeleteProfile("travel"); err != nil {
+		t.Fatalf("DeleteProfile() error = %v", err)
+	}
+	if cfg.CurrentProfile != "" {
+		t.Errorf("DeleteProfile() kept the deleted current profile %q", cfg.CurrentProfile)
+	}
+	if err := cfg.SetCurrentProfile(DefaultProfile); err != nil || cfg.CurrentProfile != "" {
+		t.Errorf("SetCurrentProfile(default) error = %v, current = %q", err, cfg.CurrentProfile)
+	}
+}
+
+func TestSaveConfigKeepsEmptyProfileLocations(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	clearEnvOverrides(t)
+	t.Setenv(ProfileEnvVar, "")
+
+	if err := SaveConfig(profileConfig()); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if err := UseProfile(cfg, "work"); err != nil {
+		t.Fatalf("UseProfile() error = %v", err)
+	}
+	if err := cfg.RemoveLocation("Office"); err != nil {
+		t.Fatalf("RemoveLocation() error = %v", err)
+	}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	// The emptied list must not fall back to the top-level locations
+	saved, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if locations := saved.Profiles["work"].Locations; locations == nil || len(*locations) != 0 {
+		t.Errorf("SaveConfig() work locations = %v, want an empty list", locations)
+	}
+	if err := UseProfile(saved, "work"); err != nil {
+		t.Fatalf("UseProfile() error = %v", err)
+	}
+	if len(saved.Locations) != 0 {
+		t.Errorf("UseProfile() locations = %+v, want none", saved.Locations)
+	}
+}
+
+func TestUseProfileReplacesAPIKeySources(t *testing.T) {
+	t.Setenv(ProfileEnvVar, "")
+
+	cfg := profileConfig()
+	cfg.APIKey = ""
+	cfg.APIKeyCmd = "pass show weather"
+	cfg.APIKeyKeyring = true
+	if err := UseProfile(cfg, "work"); err != nil {
+		t.Fatalf("UseProfile() error = %v", err)
+	}
+	if cfg.APIKey != "work-key" || cfg.APIKeyCmd != "" || cfg.APIKeyKeyring {
+		t.Errorf("UseProfile() key settings = %q, %q, %v, want only the work key", cfg.APIKey, cfg.APIKeyCmd, cfg.APIKeyKeyring)
+	}
+	if source := profileSource(cfg, "api_key_cmd"); source != "work" {
+		t.Errorf("profileSource(api_key_cmd) = %q, want work", source)
+	}
+}
@@ -0,0 +1,563 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchemaVersion is the version of the config file format written by this version
+const SchemaVersion = 1
+
+// Problem is a single invalid value in a config file
+type Problem struct {
+	// Path is the JSON path of the value, e.g. $.locations[2].latitude
+	Path    string
+	Message string
+	// Warning marks a value that only breaks the feature using it, so the
+	// config still loads
+	Warning bool
+}
+
+func (p Problem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+// ErrInvalidConfig is matched by errors.Is for a config file or setting that
+// cannot be used
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ValidationError reports every problem found in a config file
+type ValidationError struct {
+	File     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "invalid config file %s:", e.File)
+	for _, p := range e.Problems {
+		sb.WriteString("\n  " + p.String())
+	}
+	return sb.String()
+}
+
+// Is makes every ValidationError match ErrInvalidConfig
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// errorsOnly returns the problems that stop a config from loading
+func errorsOnly(problems []Problem) []Problem {
+	var errs []Problem
+	for _, p := range problems {
+		if !p.Warning {
+			errs = append(errs, p)
+		}
+	}
+	return errs
+}
+
+// migration upgrades a decoded config document by one schema version
+type migration struct {
+	description string
+	// apply upgrades doc and describes the values it had to change
+	apply func(doc map[string]any) []string
+}
+
+// migrations[i] upgrades a document from schema version i to i+1
+var migrations = []migration{
+	{
+		description: "add schema_version, write out the default temperature unit and forecast interval, " +
+			"rename locations whose names clash and clear a default location that matches none",
+		apply: migrateV0,
+	},
+}
+
+// migrateV0 upgrades files written before schema_version existed, where a missing
+// forecast_interval was loaded as 0 and the unit could be spelled metric or imperial.
+// Those versions also saved names differing only in case or accents, and kept
+// default_location after the location was gone.
+func migrateV0(doc map[string]any) []string {
+	if unit, ok := doc["temperature_unit"]; !ok || unit == nil {
+		doc["temperature_unit"] = defaultTempUnit
+	} else if unit, ok := unit.(string); ok {
+		switch strings.ToLower(unit) {
+		case "", "c", "metric":
+			doc["temperature_unit"] = "C"
+		case "f", "imperial":
+			doc["temperature_unit"] = "F"
+		}
+	}
+	if interval, ok := doc["forecast_interval"]; !ok || interval == nil || interval == json.Number("0") {
+		doc["forecast_interval"] = json.Number(fmt.Sprint(defaultForecastHours))
+	}
+
+	// Values of the wrong type are left for validation to report
+	var changes []string
+	taken := make(map[string]bool)
+	locations, _ := doc["locations"].([]any)
+	for _, item := range locations {
+		loc, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, ok := loc["name"].(string); ok && NormalizeName(name) != "" {
+			if taken[NormalizeName(name)] {
+				renamed := uniqueName(name, taken)
+				loc["name"] = renamed
+				changes = append(changes, fmt.Sprintf("renamed location %q to %q, as the name was already used", name, renamed))
+				name = renamed
+			}
+			taken[NormalizeName(name)] = true
+		}
+
+		aliases, ok := loc["aliases"].([]any)
+		if !ok {
+			continue
+		}
+		kept := []any{}
+		for _, alias := range aliases {
+			if name, ok := alias.(string); ok && NormalizeName(name) != "" {
+				if taken[NormalizeName(name)] {
+					changes = append(changes, fmt.Sprintf("removed alias %q of location %v, as the name was already used", name, loc["name"]))
+					continue
+				}
+				taken[NormalizeName(name)] = true
+			}
+			kept = append(kept, alias)
+		}
+		loc["aliases"] = kept
+	}
+
+	if name, ok := doc["default_location"].(string); ok && name != "" && !taken[NormalizeName(name)] {
+		doc["default_location"] = ""
+		changes = append(changes, fmt.Sprintf("cleared default_location %q, as it matches no saved location", name))
+	}
+	return changes
+}
+
+// uniqueName appends a counter to name until it is not taken
+func uniqueName(name string, taken map[string]bool) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if !taken[NormalizeName(candidate)] {
+			return candidate
+		}
+	}
+}
+
+// document is a config file decoded without a schema, so that it can be migrated
+// and validated before it is mapped onto Config
+type document struct {
+	fields  map[string]any
+	version int
+}
+
+// decodeDocument decodes the content of a config file. Syntax errors are reported
+// with their line and column.
+func decodeDocument(data []byte) (*document, []Problem) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := position(data, syntaxErr.Offset)
+			return nil, []Problem{{Path: "$", Message: fmt.Sprintf("line %d, column %d: %v", line, column, err)}}
+		}
+		return nil, []Problem{{Path: "$", Message: err.Error()}}
+	}
+	if decoder.More() {
+		return nil, []Problem{{Path: "$", Message: "unexpected content after the top-level object"}}
+	}
+
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, []Problem{{Path: "$", Message: "must be an object, got " + describeJSON(value)}}
+	}
+
+	doc := &document{fields: fields}
+	if raw, ok := fields["schema_version"]; ok {
+		n, isNumber := raw.(json.Number)
+		v, err := n.Int64()
+		if !isNumber || err != nil || v < 0 {
+			return nil, []Problem{{Path: "$.schema_version", Message: "must be a non-negative integer, got " + describeJSON(raw)}}
+		}
+		doc.version = int(v)
+	}
+	if doc.version > SchemaVersion {
+		return nil, []Problem{{
+			Path:    "$.schema_version",
+			Message: fmt.Sprintf("version %d is newer than this version of weather-cli supports (%d); upgrade weather-cli", doc.version, SchemaVersion),
+		}}
+	}
+	return doc, nil
+}
+
+// readDocument converts the content of a config file from its format and decodes it
+func readDocument(path string, data []byte) (*document, []Problem) {
+	converted, err := toJSON(data, FormatFromPath(path))
+	if err != nil {
+		return nil, []Problem{{Path: "$", Message: err.Error()}}
+	}
+	return decodeDocument(converted)
+}
+
+// pendingMigrations describes the migrations needed to bring doc up to date
+func (doc *document) pendingMigrations() []string {
+	var pending []string
+	for v := doc.version; v < SchemaVersion; v++ {
+		pending = append(pending, fmt.Sprintf("%d -> %d: %s", v, v+1, migrations[v].description))
+	}
+	return pending
+}
+
+// migrate applies the pending migrations to doc and describes the values they changed
+func (doc *document) migrate() []string {
+	var changes []string
+	for ; doc.version < SchemaVersion; doc.version++ {
+		changes = append(changes, migrations[doc.version].apply(doc.fields)...)
+	}
+	doc.fields["schema_version"] = json.Number(fmt.Sprint(doc.version))
+	return changes
+}
+
+// decode validates doc and maps it onto a Config, reporting every problem found.
+// The config is nil if any of the problems is not a warning.
+func (doc *document) decode() (*Config, []Problem) {
+	var problems []Problem
+	// Values of the wrong type are left out, so that the rest can still be checked
+	valid := checkType("$", doc.fields, reflect.TypeOf(Config{}), &problems)
+
+	data, err := json.Marshal(valid)
+	if err != nil {
+		return nil, append(problems, Problem{Path: "$", Message: err.Error()})
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, append(problems, Problem{Path: "$", Message: err.Error()})
+	}
+
+	// A value left out above has already been reported
+	reported := make(map[string]bool, len(problems))
+	for _, p := range problems {
+		reported[p.Path] = true
+	}
+	for _, p := range validate(&cfg) {
+		if !reported[p.Path] {
+			problems = append(problems, p)
+		}
+	}
+	if len(errorsOnly(problems)) > 0 {
+		return nil, problems
+	}
+	return &cfg, problems
+}
+
+// checkType reports keys that no field of t decodes and values whose JSON type
+// does not match the field they decode into. It returns value without those parts.
+// Null is accepted everywhere.
+func checkType(path string, value any, t reflect.Type, problems *[]Problem) any {
+	if value == nil {
+		return nil
+	}
+	mismatch := func(want string) any {
+		*problems = append(*problems, Problem{Path: path, Message: fmt.Sprintf("must be %s, got %s", want, describeJSON(value))})
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return checkType(path, value, t.Elem(), problems)
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return mismatch("an object")
+		}
+		fields := jsonFields(t)
+		valid := make(map[string]any, len(object))
+		for _, key := range sortedKeys(object) {
+			field, ok := fields[key]
+			if !ok {
+				*problems = append(*problems, Problem{Path: childPath(path, key), Message: "unknown key"})
+				continue
+			}
+			valid[key] = checkType(childPath(path, key), object[key], field.Type, problems)
+		}
+		return valid
+	case reflect.Map:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return mismatch("an object")
+		}
+		valid := make(map[string]any, len(object))
+		for _, key := range sortedKeys(object) {
+			valid[key] = checkType(childPath(path, key), object[key], t.Elem(), problems)
+		}
+		return valid
+	case reflect.Slice:
+		items, ok := value.([]any)
+		if !ok {
+			return mismatch("an array")
+		}
+		valid := make([]any, len(items))
+		for i, item := range items {
+			valid[i] = checkType(fmt.Sprintf("%s[%d]", path, i), item, t.Elem(), problems)
+		}
+		return valid
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return mismatch("a string")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return mismatch("true or false")
+		}
+	case reflect.Int, reflect.Int64:
+		n, ok := value.(json.Number)
+		if !ok {
+			return mismatch("an integer")
+		}
+		if _, err := n.Int64(); err != nil {
+			return mismatch("an integer")
+		}
+	case reflect.Float64:
+		if _, ok := value.(json.Number); !ok {
+			return mismatch("a number")
+		}
+	}
+	return value
+}
+
+// validate reports values that decode but cannot be used
+func validate(cfg *Config) []Problem {
+	var problems []Problem
+	report := func(path, format string, args ...any) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	warn := func(path, format string, args ...any) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, args...), Warning: true})
+	}
+
+	validateSettings("$", settingsOf(cfg), cfg.Locations, report, warn)
+
+	for _, name := range ProfileNames(cfg) {
+		path := childPath("$.profiles", name)
+		profile := cfg.Profiles[name]
+		if err := checkProfileName(name); err != nil {
+			report(path, "%v", err)
+		}
+		// Locations are inherited as a whole, so default_location is checked against
+		// the list the profile ends up with
+		locations := cfg.Locations
+		if chain, err := profileChain(cfg, name); err != nil {
+			report(path+".base", "%v", err)
+		} else {
+			for _, link := range chain {
+				if own := cfg.Profiles[link].Locations; own != nil {
+					locations = *own
+					break
+				}
+			}
+		}
+		validateSettings(path, profile, locations, report, warn)
+	}
+
+	validateNotifiers(cfg, report)
+	validateAlerts(cfg, report, warn)
+	validateJobs(cfg, report, warn)
+
+	if cfg.CurrentProfile != "" && cfg.CurrentProfile != DefaultProfile {
+		if _, ok := cfg.Profiles[cfg.CurrentProfile]; !ok {
+			report("$.current_profile", "unknown profile %q", cfg.CurrentProfile)
+		}
+	}
+	return problems
+}
+
+// validateSettings checks the settings at path, the top level or a profile.
+// default_location must match one of locations. Coordinates out of range and
+// a default location that matches none only break forecasts for that location,
+// so they are reported as warnings.
+func validateSettings(prefix string, s Profile, locations []Location, report, warn func(path, format string, args ...any)) {
+	switch s.TemperatureUnit {
+	case "", "C", "F":
+	default:
+		report(prefix+".temperature_unit", `must be "C" or "F", got %q`, s.TemperatureUnit)
+	}
+	if s.ForecastInterval < 0 {
+		report(prefix+".forecast_interval", "must be a positive number of hours, got %d", s.ForecastInterval)
+	}
+	if s.Language != "" && !languageCode.MatchString(s.Language) {
+		report(prefix+".language", "must be a language code such as en, de or zh_cn, got %q", s.Language)
+	}
+	if s.Provider != "" && s.Provider != DefaultProvider {
+		report(prefix+".provider", "unsupported provider %q. Only %s is available", s.Provider, DefaultProvider)
+	}
+
+	// Names and aliases share one namespace, compared like lookups compare them
+	seen := make(map[string]string)
+	var own []Location
+	if s.Locations != nil {
+		own = *s.Locations
+	}
+	for i, loc := range own {
+		path := fmt.Sprintf("%s.locations[%d]", prefix, i)
+		if strings.TrimSpace(loc.Name) == "" {
+			report(path+".name", "must not be empty")
+		}
+		if loc.Latitude < -90 || loc.Latitude > 90 {
+			warn(path+".latitude", "must be between -90 and 90, got %v", loc.Latitude)
+		}
+		if loc.Longitude < -180 || loc.Longitude > 180 {
+			warn(path+".longitude", "must be between -180 and 180, got %v", loc.Longitude)
+		}
+		if loc.Timezone != "" {
+			if _, err := time.LoadLocation(loc.Timezone); err != nil || loc.Timezone == "Local" {
+				report(path+".timezone", "unknown time zone %q", loc.Timezone)
+			}
+		}
+
+		namePaths := []string{path + ".name"}
+		names := []string{loc.Name}
+		for k, alias := range loc.Aliases {
+			namePaths = append(namePaths, fmt.Sprintf("%s.aliases[%d]", path, k))
+			names = append(names, alias)
+		}
+		for k, name := range names {
+			key := NormalizeName(name)
+			if key == "" {
+				continue
+			}
+			if first, ok := seen[key]; ok {
+				report(namePaths[k], "%q is already used at %s", name, first)
+				continue
+			}
+			seen[key] = namePaths[k]
+		}
+	}
+
+	if s.DefaultLocation != "" && !hasLocationName(locations, s.DefaultLocation) {
+		warn(prefix+".default_location", "%q does not match any saved location", s.DefaultLocation)
+	}
+}
+
+// anyHasLocationName reports whether name matches a location at the top level or
+// in any profile
+func anyHasLocationName(cfg *Config, name string) bool {
+	if hasLocationName(cfg.Locations, name) {
+		return true
+	}
+	for _, profile := range cfg.Profiles {
+		if profile.Locations != nil && hasLocationName(*profile.Locations, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// anyHasDefaultLocation reports whether default_location is set at the top level or
+// in any profile
+func anyHasDefaultLocation(cfg *Config) bool {
+	if cfg.DefaultLocation != "" {
+		return true
+	}
+	for _, profile := range cfg.Profiles {
+		if profile.DefaultLocation != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLocationName reports whether name matches the name or an alias of a location
+func hasLocationName(locations []Location, name string) bool {
+	key := NormalizeName(name)
+	for _, loc := range locations {
+		if NormalizeName(loc.Name) == key {
+			return true
+		}
+		for _, alias := range loc.Aliases {
+			if NormalizeName(alias) == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonFields maps the JSON keys of a struct type to its fields
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// identifier matches keys that can be written as .key in a JSON path
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// childPath appends an object key to a JSON path
+func childPath(path, key string) string {
+	if identifier.MatchString(key) {
+		return path + "." + key
+	}
+	return fmt.Sprintf("%s[%q]", path, key)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// describeJSON names the JSON type of a decoded value for messages
+func describeJSON(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprint(v)
+	case []any:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = int(offset) - (bytes.LastIndexByte(before, '\n') + 1)
+	return line, column
+}
@@ -0,0 +1,375 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "Valid",
+			content: `{"schema_version": 1, "locations": [{"name": "Tokyo", "latitude": 35.6895, "longitude": 139.6917, "aliases": ["tyo"], "timezone": "UTC"}], "default_location": "tyo"}`,
+			want:    nil,
+		},
+		{
+			name: "Warnings only",
+			content: `{"schema_version": 1, "default_location": "Paris", "locations": [
+				{"name": "Tokyo", "latitude": 95, "longitude": 200}
+			]}`,
+			want: []string{
+				"$.locations[0].latitude: must be between -90 and 90, got 95 (warning)",
+				"$.locations[0].longitude: must be between -180 and 180, got 200 (warning)",
+				`$.default_location: "Paris" does not match any saved location (warning)`,
+			},
+		},
+		{
+			name:    "Unknown keys",
+			content: `{"schema_version": 1, "colour": true, "locations": [{"name": "Tokyo", "lat": 35}], "my key": 1}`,
+			want: []string{
+				"$.colour: unknown key",
+				"$.locations[0].lat: unknown key",
+				`$["my key"]: unknown key`,
+			},
+		},
+		{
+			name:    "Wrong types",
+			content: `{"schema_version": 1, "forecast_interval": "24", "api_key_keyring": "yes", "locations": [{"name": 5, "latitude": 1, "aliases": "a"}]}`,
+			want: []string{
+				`$.api_key_keyring: must be true or false, got "yes"`,
+				`$.forecast_interval: must be an integer, got "24"`,
+				"$.locations[0].aliases: must be an array, got \"a\"",
+				"$.locations[0].name: must be a string, got 5",
+			},
+		},
+		{
+			name:    "Invalid values",
+			content: `{"schema_version": 1, "temperature_unit": "K", "forecast_interval": -1, "provider": "metoffice"}`,
+			want: []string{
+				`$.temperature_unit: must be "C" or "F", got "K"`,
+				"$.forecast_interval: must be a positive number of hours, got -1",
+				`$.provider: unsupported provider "metoffice". Only openweather is available`,
+			},
+		},
+		{
+			name: "Invalid locations",
+			content: `{"schema_version": 1, "default_location": "Paris", "locations": [
+				{"name": "Tokyo", "latitude": 91, "longitude": 0},
+				{"name": "Osaka", "latitude": 0, "longitude": -181, "aliases": ["TOKYO"], "timezone": "Mars/Olympus"},
+				{"name": " ", "latitude": 0, "longitude": 0},
+				{"name": "Zürich", "latitude": 47.3769, "longitude": 8.5417},
+				{"name": "Zurich", "latitude": 47.3769, "longitude": 8.5417}
+			]}`,
+			want: []string{
+				"$.locations[0].latitude: must be between -90 and 90, got 91 (warning)",
+				"$.locations[1].longitude: must be between -180 and 180, got -181 (warning)",
+				`$.locations[1].timezone: unknown time zone "Mars/Olympus"`,
+				`$.locations[1].aliases[0]: "TOKYO" is already used at $.locations[0].name`,
+				"$.locations[2].name: must not be empty",
+				`$.locations[4].name: "Zurich" is already used at $.locations[3].name`,
+				`$.default_location: "Paris" does not match any saved location (warning)`,
+			},
+		},
+		{
+			name: "Invalid profiles",
+			content: `{"schema_version": 1, "current_profile": "office", "locations": [{"name": "Tokyo"}], "profiles": {
+				"default": {},
+				"home": {"default_location": "Tokyo", "temperature_unit": "K"},
+				"loop": {"base": "work"},
+				"travel": {"base": "abroad", "locations": [{"name": "Paris", "latitude": 95}]},
+				"work": {"base": "loop", "default_location": "Osaka", "colour": 1}
+			}}`,
+			want: []string{
+				"$.profiles.work.colour: unknown key",
+				"$.profiles.default: 'default' is reserved for the top-level settings",
+				`$.profiles.home.temperature_unit: must be "C" or "F", got "K"`,
+				"$.profiles.loop.base: profile 'loop' inherits from itself: loop -> work -> loop",
+				"$.profiles.travel.base: profile 'travel' has unknown base 'abroad'",
+				"$.profiles.travel.locations[0].latitude: must be between -90 and 90, got 95 (warning)",
+				"$.profiles.work.base: profile 'work' inherits from itself: work -> loop -> work",
+				`$.profiles.work.default_location: "Osaka" does not match any saved location (warning)`,
+				`$.current_profile: unknown profile "office"`,
+			},
+		},
+		{
+			name: "Invalid alerts",
+			content: `{"schema_version": 1, "locations": [{"name": "Tokyo"}], "alert_notify": ["stdout", "pager"], "alerts": [
+				{"name": "Rain", "location": "tokyo", "metric": "pop", "above": 0.6, "within": "6h", "notify": ["https://example.com/hook"]},
+				{"name": "rain", "location": "Osaka", "metric": "visibility", "above": 1, "below": 2},
+				{"name": "", "metric": "temp", "below": "-5", "within": "soon", "period": "weekend"}
+			]}`,
+			want: []string{
+				`$.alerts[2].below: must be a number, got "-5"`,
+				`$.alert_notify[1]: unknown notification target "pager". Use stdout, desktop or a webhook URL`,
+				`$.alerts[1].name: "rain" is already used at $.alerts[0].name`,
+				`$.alerts[1].location: "Osaka" does not match any saved location (warning)`,
+				`$.alerts[1].metric: must be one of temp, feels_like, pop, rain, snow, wind, gust, humidity, clouds, got "visibility"`,
+				"$.alerts[1]: must set exactly one of above and below",
+				"$.alerts[2].name: must not be empty",
+				"$.alerts[2].location: must be set when there is no default_location (warning)",
+				"$.alerts[2]: must set exactly one of above and below",
+				"$.alerts[2]: must not set both within and period",
+				`$.alerts[2].within: must be a positive duration such as 6h, got "soon"`,
+				`$.alerts[2].period: must be one of today, tonight, tomorrow, got "weekend"`,
+			},
+		},
+		{
+			name: "Alerts on profile locations",
+			content: `{"schema_version": 1, "profiles": {"work": {"default_location": "Office", "locations": [{"name": "Office"}]}}, "alerts": [
+				{"name": "Rain", "location": "office", "metric": "pop", "above": 0.6},
+				{"name": "Frost", "metric": "temp", "below": 0}
+			]}`,
+			want: nil,
+		},
+		{
+			name: "Invalid notifiers",
+			content: `{"schema_version": 1, "alert_notify": ["team", "desktop"], "notifiers": {
+				"team": {"type": "slack", "url": "https://hooks.slack.com/services/x"},
+				"chat": {"type": "discord", "url": "discord.com/api/webhooks/x"},
+				"mail": {"type": "email", "smtp_port": 70000, "from": "weather", "to": ["ops@example.com", "team"], "password": "a", "password_cmd": "b"},
+				"pager": {"type": "sms"},
+				"stdout": {"type": "webhook", "url": "http://localhost:9000"}
+			}}`,
+			want: []string{
+				`$.notifiers.chat.url: must be an http or https URL, got "discord.com/api/webhooks/x"`,
+				"$.notifiers.mail.smtp_host: must not be empty",
+				"$.notifiers.mail.smtp_port: must be a port number, got 70000",
+				`$.notifiers.mail.from: must be an email address, got "weather"`,
+				`$.notifiers.mail.to[1]: must be an email address, got "team"`,
+				"$.notifiers.mail: must not set both password and password_cmd",
+				`$.notifiers.pager.type: must be one of webhook, slack, discord, email, got "sms"`,
+				`$.notifiers.stdout: "stdout" cannot be used as a notifier name`,
+			},
+		},
+		{
+			name: "Invalid jobs",
+			content: `{"schema_version": 1, "locations": [{"name": "Tokyo"}], "jobs": [
+				{"name": "Morning", "schedule": "30 7 * * mon-fri", "task": "summary", "location": "tokyo", "notify": ["stdout"], "timeout": "1m"},
+				{"name": "morning", "schedule": "30 25 * * *", "task": "summary", "location": "Osaka", "notify": ["pager"]},
+				{"name": "", "schedule": "@every 30m", "task": "alerts", "location": "Tokyo", "timeout": "soon"},
+				{"name": "Backup", "schedule": "@daily", "task": "backup"}
+			]}`,
+			want: []string{
+				`$.jobs[1].name: "morning" is already used at $.jobs[0].name`,
+				`$.jobs[1].schedule: invalid schedule "30 25 * * *": hour must be between 0 and 23, got "25"`,
+				`$.jobs[1].location: "Osaka" does not match any saved location (warning)`,
+				`$.jobs[1].notify[0]: unknown notification target "pager". Use stdout, desktop or a webhook URL`,
+				"$.jobs[2].name: must not be empty",
+				`$.jobs[2].timeout: must be a positive duration such as 1m, got "soon"`,
+				"$.jobs[2].task: there are no alert rules to check",
+				"$.jobs[2]: location and notify only apply to summary jobs; alerts go to the targets of each rule",
+				`$.jobs[3].task: must be one of summary, alerts, got "backup"`,
+			},
+		},
+		{
+			name:    "Syntax error",
+			content: "{\n  \"api_key\": \"abc\"\n  \"provider\": \"openweather\"\n}",
+			want:    []string{"$: line 3, column 3: invalid character '\"' after object key:value pair"},
+		},
+		{
+			name:    "Not an object",
+			content: `[1, 2]`,
+			want:    []string{"$: must be an object, got an array"},
+		},
+		{
+			name:    "Newer schema",
+			content: `{"schema_version": 7}`,
+			want:    []string{"$.schema_version: version 7 is newer than this version of weather-cli supports (1); upgrade weather-cli"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, problems := readDocument("config.json", []byte(tt.content))
+			if doc != nil {
+				doc.migrate()
+				_, problems = doc.decode()
+			}
+			var got, errs []string
+			for _, p := range problems {
+				if p.Warning {
+					got = append(got, p.String()+" (warning)")
+				} else {
+					got = append(got, p.String())
+					errs = append(errs, p.String())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Problems =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+
+			// The file loads unless a problem is an error, which the ValidationError lists
+			cfg, err := parseConfig("config.json", []byte(tt.content))
+			if len(errs) == 0 {
+				if err != nil || cfg == nil {
+					t.Fatalf("parseConfig() error = %v, want a valid config", err)
+				}
+				if len(cfg.Warnings()) != len(got) {
+					t.Errorf("Warnings() = %v, want %v", cfg.Warnings(), got)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("parseConfig() error = %v, want a ValidationError", err)
+			}
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("errors.Is(%v, ErrInvalidConfig) = false", err)
+			}
+			var listed []string
+			for _, p := range validationErr.Problems {
+				listed = append(listed, p.String())
+			}
+			if !reflect.DeepEqual(listed, errs) {
+				t.Errorf("ValidationError problems = %v, want %v", listed, errs)
+			}
+		})
+	}
+}
+
+func TestLoadConfigMigratesInMemory(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	legacy := []byte(`{"locations": [], "temperature_unit": "imperial", "api_key": "abc"}`)
+	if err := os.WriteFile(configPath, legacy, 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.SchemaVersion != SchemaVersion || cfg.TemperatureUnit != "F" || cfg.ForecastInterval != defaultForecastHours {
+		t.Errorf("Migrated config = %+v, want schema %d, unit F and interval %d", cfg, SchemaVersion, defaultForecastHours)
+	}
+
+	data, _ := os.ReadFile(configPath)
+	if string(data) != string(legacy) {
+		t.Errorf("LoadConfig() should not rewrite the file, got %s", data)
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	legacy := []byte(`{"locations": [{"name": "Tokyo", "latitude": 35.6895, "longitude": 139.6917}], "temperature_unit": "C", "forecast_interval": 0}`)
+	if err := os.WriteFile(configPath, legacy, 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	upgrade, err := MigrateConfig()
+	if err != nil {
+		t.Fatalf("MigrateConfig() error = %v", err)
+	}
+	if upgrade == nil || upgrade.From != 0 || upgrade.To != SchemaVersion || upgrade.Backup != configPath+".v0.bak" || upgrade.Changes != nil {
+		t.Fatalf("MigrateConfig() = %+v", upgrade)
+	}
+
+	saved, _ := os.ReadFile(upgrade.Backup)
+	if string(saved) != string(legacy) {
+		t.Errorf("Backup = %s, want the original file", saved)
+	}
+	data, _ := os.ReadFile(configPath)
+	for _, want := range []string{`"schema_version": 1`, `"forecast_interval": 24`, `"name": "Tokyo"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Upgraded file is missing %s:\n%s", want, data)
+		}
+	}
+
+	// An up-to-date file is left alone
+	if upgrade, err := MigrateConfig(); err != nil || upgrade != nil {
+		t.Errorf("Second MigrateConfig() = %+v, %v, want no migration", upgrade, err)
+	}
+}
+
+func TestMigrateConfigRepairs(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	// Older versions saved these without complaint
+	legacy := []byte(`{"default_location": "Paris", "locations": [
+		{"name": "Tokyo", "latitude": 35.6895, "longitude": 139.6917},
+		{"name": "tokyo", "latitude": 35.7, "longitude": 139.7, "aliases": ["TYO", "Tōkyō", "home"]},
+		{"name": "Zürich", "latitude": 47.3769, "longitude": 8.5417, "aliases": ["HOME"]},
+		{"name": "Nowhere", "latitude": 95, "longitude": 200}
+	]}`)
+	if err := os.WriteFile(configPath, legacy, 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	upgrade, err := MigrateConfig()
+	if err != nil {
+		t.Fatalf("MigrateConfig() error = %v", err)
+	}
+	want := []string{
+		`renamed location "tokyo" to "tokyo (2)", as the name was already used`,
+		`removed alias "Tōkyō" of location tokyo (2), as the name was already used`,
+		`removed alias "HOME" of location Zürich, as the name was already used`,
+		`cleared default_location "Paris", as it matches no saved location`,
+	}
+	if !reflect.DeepEqual(upgrade.Changes, want) {
+		t.Errorf("Changes =\n  %s\nwant\n  %s", strings.Join(upgrade.Changes, "\n  "), strings.Join(want, "\n  "))
+	}
+
+	// The coordinates are kept and reported as warnings
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.Locations[1].Name != "tokyo (2)" || !reflect.DeepEqual(cfg.Locations[1].Aliases, []string{"TYO", "home"}) || cfg.DefaultLocation != "" {
+		t.Errorf("Upgraded config = %+v", cfg)
+	}
+	if len(cfg.Warnings()) != 2 || cfg.Warnings()[0].Path != "$.locations[3].latitude" {
+		t.Errorf("Warnings() = %v, want the coordinates of Nowhere", cfg.Warnings())
+	}
+}
+
+func TestMigrateInvalidConfig(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := os.WriteFile(configPath, []byte(`{"temperature_unit": "K"}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var validationErr *ValidationError
+	if _, err := MigrateConfig(); !errors.As(err, &validationErr) {
+		t.Fatalf("MigrateConfig() error = %v, want a ValidationError", err)
+	}
+	if _, err := os.Stat(configPath + ".v0.bak"); !os.IsNotExist(err) {
+		t.Errorf("An invalid file should not be upgraded, stat error = %v", err)
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	diagnosis, err := Diagnose()
+	if err != nil || diagnosis.Exists {
+		t.Fatalf("Diagnose() = %+v, %v, want a missing file", diagnosis, err)
+	}
+
+	if err := os.WriteFile(configPath, []byte(`{"forecast_interval": 6, "extra": 1}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	diagnosis, err = Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose() error = %v", err)
+	}
+	if !diagnosis.Exists || diagnosis.SchemaVersion != 0 || len(diagnosis.Migrations) != 1 {
+		t.Errorf("Diagnose() = %+v, want schema 0 with one pending migration", diagnosis)
+	}
+	if len(diagnosis.Problems) != 1 || diagnosis.Problems[0].String() != "$.extra: unknown key" {
+		t.Errorf("Problems = %v, want the unknown key", diagnosis.Problems)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"weather-cli/internal/secret"
+)
+
+// APIKeyAccount is the keyring account under which the API key is stored
+const APIKeyAccount = "api_key"
+
+// KeyringAccount returns the keyring account for the API key of the active
+// profile, which is APIKeyAccount@<profile> when a profile is in use
+func KeyringAccount(cfg *Config) string {
+	if name := ActiveProfile(cfg); name != "" {
+		return APIKeyAccount + "@" + name
+	}
+	return APIKeyAccount
+}
+
+// ResolveAPIKey fills in the API key from the configured secret source. An API key
+// from the environment or a flag wins; otherwise api_key_cmd, api_key_file and the
+// keyring are tried in that order, falling back to the plaintext api_key. The
+// resolved key is never written back to the config file.
+func ResolveAPIKey(cfg *Config) error {
+	if o, ok := cfg.overrides["api_key"]; ok && (o.layer == LayerEnv || o.layer == LayerFlag || o.layer == LayerSecret) {
+		return nil
+	}
+
+	var source string
+	var value string
+	var err error
+	switch {
+	case cfg.APIKeyCmd != "":
+		source = "api_key_cmd: " + cfg.APIKeyCmd
+		value, err = secret.RunCommand(cfg.APIKeyCmd)
+	case cfg.APIKeyFile != "":
+		source = "api_key_file: " + cfg.APIKeyFile
+		value, err = secret.ReadFile(cfg.APIKeyFile)
+	case cfg.APIKeyKeyring:
+		account := KeyringAccount(cfg)
+		value, err = secret.DefaultKeyring.Get(account)
+		// Profiles without a key of their own share the one stored without a profile
+		if errors.Is(err, secret.ErrNotFound) && account != APIKeyAccount {
+			account = APIKeyAccount
+			value, err = secret.DefaultKeyring.Get(account)
+		}
+		source = "keyring: " + secret.Service + "/" + account
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read API key from %s: %w", source, err)
+	}
+
+	if cfg.overrides == nil {
+		cfg.overrides = make(map[string]override)
+	}
+	cfg.overrides["api_key"] = override{layer: LayerSecret, source: source, value: value, fileValue: cfg.APIKey}
+	cfg.APIKey = value
+	return nil
+}
+
+// PlaintextAPIKey reports whether the API key in use is stored in plaintext in the config file
+func PlaintextAPIKey(cfg *Config) bool {
+	if _, ok := cfg.overrides["api_key"]; ok {
+		return false
+	}
+	return cfg.APIKey != "" && cfg.APIKeyCmd == "" && cfg.APIKeyFile == "" && !cfg.APIKeyKeyring
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"weather-cli/internal/secret"
+	"weather-cli/internal/secret/secrettest"
+)
+
+// useMemoryKeyring replaces the system keyring for the duration of a test
+func useMemoryKeyring(t *testing.T) *secrettest.Keyring {
+	keyring := &secrettest.Keyring{}
+	old := secret.DefaultKeyring
+	secret.DefaultKeyring = keyring
+	t.Cleanup(func() { secret.DefaultKeyring = old })
+	return keyring
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	keyring := useMemoryKeyring(t)
+	keyring.Set(APIKeyAccount, "keyring-key")
+
+	keyFile := filepath.Join(t.TempDir(), "owm-key")
+	os.WriteFile(keyFile, []byte("file-key\n"), 0600)
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		want    string
+		wantErr bool
+	}{
+		{"Plaintext", &Config{APIKey: "plain-key"}, "plain-key", false},
+		{"Keyring", &Config{APIKey: "plain-key", APIKeyKeyring: true}, "keyring-key", false},
+		{"File", &Config{APIKeyFile: keyFile, APIKeyKeyring: true}, "file-key", false},
+		{"Missing file", &Config{APIKeyFile: keyFile + ".missing"}, "", true},
+		{"Command", &Config{APIKeyCmd: "echo cmd-key", APIKeyFile: keyFile}, "cmd-key", false},
+		{"Nothing configured", &Config{}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ResolveAPIKey(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tt.cfg.APIKey != tt.want {
+				t.Errorf("ResolveAPIKey() key = %q, want %q", tt.cfg.APIKey, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveAPIKeyProfileKeyring(t *testing.T) {
+	keyring := useMemoryKeyring(t)
+	keyring.Set(APIKeyAccount, "shared-key")
+	keyring.Set(APIKeyAccount+"@work", "work-key")
+	t.Setenv(ProfileEnvVar, "")
+
+	for _, tt := range []struct{ profile, want string }{{"work", "work-key"}, {"home", "shared-key"}} {
+		cfg := &Config{APIKeyKeyring: true, Profiles: map[string]Profile{"work": {}, "home": {}}}
+		if err := UseProfile(cfg, tt.profile); err != nil {
+			t.Fatalf("UseProfile() error = %v", err)
+		}
+		if err := ResolveAPIKey(cfg); err != nil {
+			t.Fatalf("ResolveAPIKey() error = %v", err)
+		}
+		if cfg.APIKey != tt.want {
+			t.Errorf("ResolveAPIKey() with profile %s key = %q, want %q", tt.profile, cfg.APIKey, tt.want)
+		}
+	}
+}
+
+func TestResolveAPIKeyEnvWins(t *testing.T) {
+	useMemoryKeyring(t).Set(APIKeyAccount, "keyring-key")
+	clearEnvOverrides(t)
+	t.Setenv("WEATHER_CLI_API_KEY", "env-key")
+
+	cfg := &Config{APIKeyKeyring: true}
+	if err := ApplyOverrides(cfg, nil); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+	if err := ResolveAPIKey(cfg); err != nil {
+		t.Fatalf("ResolveAPIKey() error = %v", err)
+	}
+	if cfg.APIKey != "env-key" {
+		t.Errorf("ResolveAPIKey() key = %q, want the key from the environment", cfg.APIKey)
+	}
+}
+
+func TestResolvedAPIKeyNotSaved(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	useMemoryKeyring(t).Set(APIKeyAccount, "keyring-key")
+
+	cfg := &Config{APIKeyKeyring: true}
+	if err := ResolveAPIKey(cfg); err != nil {
+		t.Fatalf("ResolveAPIKey() error = %v", err)
+	}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	saved, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if saved.APIKey != "" || !saved.APIKeyKeyring {
+		t.Errorf("SaveConfig() wrote the resolved key: %+v", saved)
+	}
+
+	var found bool
+	for _, s := range EffectiveSettings(cfg) {
+		if s.Key == "api_key" {
+			found = true
+			if s.Layer != LayerSecret || s.Source != "keyring: weather-cli/api_key" {
+				t.Errorf("EffectiveSettings()[api_key] = %+v", s)
+			}
+		}
+	}
+	if !found {
+		t.Error("EffectiveSettings() is missing api_key")
+	}
+}
+
+func TestPlaintextAPIKey(t *testing.T) {
+	if !PlaintextAPIKey(&Config{APIKey: "plain"}) {
+		t.Error("PlaintextAPIKey() should be true for a key in the config file")
+	}
+	if PlaintextAPIKey(&Config{}) || PlaintextAPIKey(&Config{APIKey: "plain", APIKeyFile: "key.txt"}) {
+		t.Error("PlaintextAPIKey() should be false without a plaintext key in use")
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BackupSuffix is appended to the config file name for the copy of its previous version
+const BackupSuffix = ".bak"
+
+// LockTimeout is how long to wait for another process to release the config lock
+var LockTimeout = 5 * time.Second
+
+// fileVersion identifies the content a Config was loaded from or last saved as
+type fileVersion [sha256.Size]byte
+
+// Update runs a read-modify-write sequence on the config file while holding an
+// advisory lock, so concurrent invocations do not overwrite each other's changes.
+// If the file changed on disk since cfg was loaded, cfg is first refreshed from it;
+// fn then applies its changes to cfg, which is saved unless fn returns an error.
+func Update(cfg *Config, fn func() error) error {
+	path, err := ConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("error creating config directory: %w", err)
+	}
+
+	unlock, err := lockFile(path+".lock", LockTimeout)
+	if err != nil {
+		return fmt.Errorf("error locking config file: %w", err)
+	}
+	defer unlock()
+
+	if err := refresh(cfg, path); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	return SaveConfig(cfg)
+}
+
+// refresh reloads cfg from path when the file no longer holds the version cfg was
+// loaded from. Values from the environment, flags or secret sources stay in effect.
+// A Config that was never loaded from disk is left alone.
+func refresh(cfg *Config, path string) error {
+	if cfg.version == (fileVersion{}) {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || sha256.Sum256(data) == cfg.version {
+		return nil
+	}
+
+	fresh, err := parseConfig(path, data)
+	if err != nil {
+		return err
+	}
+	if cfg.profile != nil {
+		if err := UseProfile(fresh, cfg.profile.name); err != nil {
+			return err
+		}
+	}
+
+	overrides := cfg.overrides
+	*cfg = *fresh
+	cfg.overrides = make(map[string]override, len(overrides))
+	for _, key := range configKeys {
+		o, ok := overrides[key.name]
+		if !ok {
+			continue
+		}
+		o.fileValue = key.get(fresh)
+		cfg.overrides[key.name] = o
+		restoreFileValue(cfg, key.name, o.value)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data by writing a temporary file in the same
+// directory and renaming it over the original, so readers never see a partial file.
+// The previous content is kept in path+BackupSuffix.
+func writeFileAtomic(path string, data []byte) error {
+	if previous, err := os.ReadFile(path); err == nil && !bytes.Equal(previous, data) {
+		if err := replaceFile(path+BackupSuffix, previous); err != nil {
+			return fmt.Errorf("error writing backup: %w", err)
+		}
+	}
+	return replaceFile(path, data)
+}
+
+// replaceFile writes data to a temporary file and renames it to path
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	// Removing after a successful rename fails harmlessly
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
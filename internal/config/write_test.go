@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSaveConfigKeepsBackup(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cfg := &Config{TemperatureUnit: "C", ForecastInterval: 24}
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if _, err := os.Stat(configPath + BackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("First save should not create a backup, stat error = %v", err)
+	}
+	first, _ := os.ReadFile(configPath)
+
+	cfg.SetTemperatureUnit("F")
+	if err := SaveConfig(cfg); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	backup, err := os.ReadFile(configPath + BackupSuffix)
+	if err != nil {
+		t.Fatalf("Backup was not written: %v", err)
+	}
+	if string(backup) != string(first) {
+		t.Errorf("Backup = %s, want the previous content %s", backup, first)
+	}
+
+	info, err := os.Stat(configPath)
+	if err != nil {
+		t.Fatalf("Failed to stat config: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("Config permissions = %o, want 600", perm)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(configPath))
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("Temporary file %s was left behind", entry.Name())
+		}
+	}
+}
+
+func TestUpdateRefreshesChangedFile(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+	clearEnvOverrides(t)
+
+	if err := SaveConfig(&Config{TemperatureUnit: "C", ForecastInterval: 24}); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	t.Setenv("WEATHER_CLI_UNITS", "F")
+	if err := ApplyOverrides(cfg, nil); err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+
+	// Another process adds a location after cfg was loaded
+	other, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	err = Update(other, func() error {
+		other.AddLocation("Tokyo", 35.6895, 139.6917)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	err = Update(cfg, func() error {
+		cfg.AddLocation("Osaka", 34.6937, 135.5023)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	saved, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(saved.Locations) != 2 {
+		t.Errorf("Saved locations = %+v, want Tokyo and Osaka", saved.Locations)
+	}
+	if saved.TemperatureUnit != "C" {
+		t.Errorf("Saved unit = %s, the environment override must not be persisted", saved.TemperatureUnit)
+	}
+	if cfg.TemperatureUnit != "F" {
+		t.Errorf("Refreshed unit = %s, the environment override should stay in effect", cfg.TemperatureUnit)
+	}
+}
+
+func TestUpdateError(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	cfg := &Config{TemperatureUnit: "C"}
+	err := Update(cfg, func() error {
+		cfg.SetTemperatureUnit("F")
+		return fmt.Errorf("rejected")
+	})
+	if err == nil || err.Error() != "rejected" {
+		t.Errorf("Update() error = %v, want the error from fn", err)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Errorf("Config should not be saved when fn fails, stat error = %v", err)
+	}
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	cleanup := setupTestEnvironment(t)
+	defer cleanup()
+
+	if err := SaveConfig(NewDefaultConfig()); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cfg, err := LoadConfig()
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- Update(cfg, func() error {
+				cfg.AddLocation(fmt.Sprintf("Location %d", i), float64(i), float64(i))
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if len(cfg.Locations) != workers {
+		t.Errorf("Saved %d locations, want %d: %+v", len(cfg.Locations), workers, cfg.Locations)
+	}
+}
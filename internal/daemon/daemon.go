@@ -0,0 +1,215 @@
+// Package daemon runs the scheduled jobs of the config file: daily summaries and
+// alert checks.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"weather-cli/internal/alert"
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/notify"
+	"weather-cli/internal/schedule"
+	"weather-cli/internal/weather"
+)
+
+// ErrNoJobs is returned by New when the config has no scheduled jobs
+var ErrNoJobs = errors.New("no scheduled jobs are configured. Add them under \"jobs\" in the config file")
+
+// job is a configured job with its schedule parsed
+type job struct {
+	config.Job
+	schedule schedule.Schedule
+	timeout  time.Duration
+	// running is set while a run of the job is in progress, including one that
+	// has timed out but not yet returned
+	running atomic.Bool
+}
+
+// Daemon runs each job whenever its schedule is due. Jobs run concurrently, and a
+// job that is still running when it is due again skips that run.
+type Daemon struct {
+	cfg    *config.Config
+	logger *slog.Logger
+	jobs   []*job
+	now    func() time.Time
+
+	mu sync.Mutex
+	// notified is the forecast time of the last match sent for each alert rule,
+	// so a rule that stays met is not sent again on every check
+	notified map[string]time.Time
+}
+
+// New prepares the jobs of cfg to run, logging to logger
+func New(cfg *config.Config, logger *slog.Logger) (*Daemon, error) {
+	if len(cfg.Jobs) == 0 {
+		return nil, ErrNoJobs
+	}
+	d := &Daemon{cfg: cfg, logger: logger, now: time.Now, notified: make(map[string]time.Time)}
+	for _, j := range cfg.Jobs {
+		s, err := schedule.Parse(j.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job %s: %w", j.Name, err)
+		}
+		d.jobs = append(d.jobs, &job{Job: j, schedule: s, timeout: j.JobTimeout()})
+	}
+	return d, nil
+}
+
+// Run runs the jobs until ctx is cancelled. Jobs that are running then are given
+// until their timeout to finish before Run returns.
+func (d *Daemon) Run(ctx context.Context) error {
+	d.logger.Info("daemon started", "jobs", len(d.jobs))
+	var wg sync.WaitGroup
+	for _, j := range d.jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			d.loop(ctx, j)
+		}(j)
+	}
+
+	<-ctx.Done()
+	d.logger.Info("daemon stopping, waiting for running jobs")
+	wg.Wait()
+	d.logger.Info("daemon stopped")
+	return nil
+}
+
+// loop waits for each time j is due and runs it
+func (d *Daemon) loop(ctx context.Context, j *job) {
+	for {
+		next := j.schedule.Next(d.now())
+		if next.IsZero() {
+			d.logger.Warn("job schedule never comes due", "job", j.Name, "schedule", j.Schedule)
+			return
+		}
+		d.logger.Debug("job scheduled", "job", j.Name, "next", next)
+
+		timer := time.NewTimer(next.Sub(d.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		d.runJob(ctx, j)
+	}
+}
+
+// runJob runs j once, limited to its timeout. Shutting down doesn't cancel a
+// job that has started.
+func (d *Daemon) runJob(ctx context.Context, j *job) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), j.timeout)
+	defer cancel()
+
+	logger := d.logger.With("job", j.Name, "task", j.Task)
+	if !j.running.CompareAndSwap(false, true) {
+		logger.Warn("job still running, skipping this run")
+		return
+	}
+	logger.Info("job started")
+	start := time.Now()
+
+	// Fetching the forecast can't be cancelled, so the timeout is enforced here
+	done := make(chan error, 1)
+	go func() {
+		defer j.running.Store(false)
+		done <- d.runTask(ctx, j, logger)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", j.timeout)
+	}
+
+	duration := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		logger.Error("job failed", "duration", duration, "error", err)
+		return
+	}
+	logger.Info("job finished", "duration", duration)
+}
+
+// runTask does the work of j
+func (d *Daemon) runTask(ctx context.Context, j *job, logger *slog.Logger) error {
+	switch j.Task {
+	case config.TaskSummary:
+		return d.sendSummary(ctx, j, logger)
+	case config.TaskAlerts:
+		return d.checkAlerts(ctx, logger)
+	}
+	return fmt.Errorf("unknown task %q", j.Task)
+}
+
+// sendSummary sends the daily summary for the job's location to its targets
+func (d *Daemon) sendSummary(ctx context.Context, j *job, logger *slog.Logger) error {
+	manager := location.NewManager(d.cfg)
+	var loc *config.Location
+	var err error
+	if j.Location == "" {
+		loc, err = manager.GetDefaultLocation()
+	} else {
+		loc, err = manager.GetLocation(j.Location)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get location: %w", err)
+	}
+
+	data, err := weather.GetWeatherForecast(d.cfg, *loc)
+	if err != nil {
+		return fmt.Errorf("failed to fetch weather data for %s: %w", loc.Name, err)
+	}
+	msg, err := notify.DailySummary(loc.Name, data, d.cfg.TemperatureUnit)
+	if err != nil {
+		return err
+	}
+	targets := j.Notify
+	if len(targets) == 0 {
+		targets = []string{config.NotifyStdout}
+	}
+	if err := notify.Send(ctx, d.cfg, targets, msg); err != nil {
+		return err
+	}
+	redacted := make([]string, len(targets))
+	for i, target := range targets {
+		redacted[i] = notify.RedactTarget(target)
+	}
+	logger.Info("summary sent", "location", loc.Name, "targets", redacted)
+	return nil
+}
+
+// checkAlerts checks the alert rules and notifies the ones that are met, leaving
+// out matches that were already sent
+func (d *Daemon) checkAlerts(ctx context.Context, logger *slog.Logger) error {
+	results := alert.Check(d.cfg, d.now())
+	met := 0
+	d.mu.Lock()
+	for i, result := range results {
+		name := result.Rule.Name
+		switch {
+		case result.Match == nil:
+			if result.Err == nil {
+				delete(d.notified, name)
+			}
+		case d.notified[name].Equal(result.Match.Time):
+			met++
+			results[i].Match = nil
+		default:
+			met++
+			d.notified[name] = result.Match.Time
+		}
+	}
+	d.mu.Unlock()
+
+	notified, err := alert.Notify(ctx, d.cfg, results)
+	logger.Info("alert rules checked", "rules", len(results), "met", met, "notified", notified)
+	return err
+}
@@ -0,0 +1,222 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/schedule"
+	"weather-cli/internal/weather"
+	"weather-cli/internal/weather/weathertest"
+)
+
+// receiver is a webhook that records the payloads it receives
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []map[string]string
+}
+
+func newReceiver(t *testing.T, delay time.Duration) *receiver {
+	r := &receiver{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(req.Body).Decode(&payload)
+		r.mu.Lock()
+		r.payloads = append(r.payloads, payload)
+		r.mu.Unlock()
+		time.Sleep(delay)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]map[string]string(nil), r.payloads...)
+}
+
+func testConfig(jobs ...config.Job) *config.Config {
+	return &config.Config{
+		APIKey:          "test_api_key",
+		TemperatureUnit: "C",
+		Locations:       []config.Location{{Name: "office", Latitude: 35.6895, Longitude: 139.6917}},
+		DefaultLocation: "office",
+		Jobs:            jobs,
+	}
+}
+
+func testLogger() (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(slog.NewJSONHandler(&buf, nil)), &buf
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(testConfig(), slog.Default()); !errors.Is(err, ErrNoJobs) {
+		t.Errorf("New() without jobs error = %v, want ErrNoJobs", err)
+	}
+	_, err := New(testConfig(config.Job{Name: "Morning", Schedule: "30 7 * *", Task: config.TaskSummary}), slog.Default())
+	if err == nil || !strings.Contains(err.Error(), "job Morning: invalid schedule") {
+		t.Errorf("New() error = %v", err)
+	}
+
+	d, err := New(testConfig(config.Job{Name: "Morning", Schedule: "@every 1m", Task: config.TaskSummary}), slog.Default())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if d.jobs[0].timeout != config.DefaultJobTimeout {
+		t.Errorf("timeout = %s, want %s", d.jobs[0].timeout, config.DefaultJobTimeout)
+	}
+}
+
+func TestSummaryJob(t *testing.T) {
+	weathertest.Serve(t)
+	hook := newReceiver(t, 0)
+	logger, logs := testLogger()
+	// The webhook path stays out of the logs
+	d, err := New(testConfig(config.Job{Name: "Morning", Schedule: "30 7 * * mon-fri", Task: config.TaskSummary, Notify: []string{hook.URL + "/services/secret"}}), logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	d.runJob(context.Background(), d.jobs[0])
+	payloads := hook.received()
+	if len(payloads) != 1 || !strings.HasPrefix(payloads[0]["title"], "Weather for office on ") || !strings.HasPrefix(payloads[0]["text"], "Clear sky, 12.0°C") {
+		t.Errorf("Webhook received %v", payloads)
+	}
+	for _, expected := range []string{`"msg":"summary sent","job":"Morning","task":"summary","location":"office","targets":["` + hook.URL + `"]`, `"msg":"job finished"`} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("Logs didn't contain %s:\n%s", expected, logs)
+		}
+	}
+	if strings.Contains(logs.String(), "secret") {
+		t.Errorf("Logs contain the webhook path:\n%s", logs)
+	}
+}
+
+func TestAlertsJobSendsEachMatchOnce(t *testing.T) {
+	weathertest.Serve(t)
+	hook := newReceiver(t, 0)
+	gust := 15.0
+	cfg := testConfig(config.Job{Name: "Alerts", Schedule: "@every 30m", Task: config.TaskAlerts})
+	cfg.Alerts = []config.AlertRule{{Name: "Gusts", Metric: "gust", Above: &gust, Notify: []string{hook.URL}}}
+	logger, logs := testLogger()
+	d, err := New(cfg, logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	d.runJob(context.Background(), d.jobs[0])
+	d.runJob(context.Background(), d.jobs[0])
+	if payloads := hook.received(); len(payloads) != 1 || payloads[0]["title"] != "Weather alert: Gusts" {
+		t.Errorf("Webhook received %v, want one alert", payloads)
+	}
+	if !strings.Contains(logs.String(), `"met":1,"notified":0`) {
+		t.Errorf("The second check should log the match as already sent:\n%s", logs)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	weathertest.Serve(t)
+	hook := newReceiver(t, 500*time.Millisecond)
+	logger, logs := testLogger()
+	d, err := New(testConfig(config.Job{Name: "Slow", Schedule: "@hourly", Task: config.TaskSummary, Notify: []string{hook.URL}, Timeout: "50ms"}), logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	start := time.Now()
+	d.runJob(context.Background(), d.jobs[0])
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("runJob() took %s despite a 50ms timeout", elapsed)
+	}
+	if !strings.Contains(logs.String(), `"level":"ERROR","msg":"job failed","job":"Slow"`) {
+		t.Errorf("Logs didn't report the failure:\n%s", logs)
+	}
+}
+
+func TestJobSkipsOverlappingRuns(t *testing.T) {
+	// The forecast request can't be cancelled, so it outlasts the timeout
+	provider := &weathertest.Provider{Entries: weathertest.Gusty, Delay: 300 * time.Millisecond}
+	provider.Start(t, &weather.RealWeatherService{})
+	logger, logs := testLogger()
+	d, err := New(testConfig(config.Job{Name: "Slow", Schedule: "@every 1m", Task: config.TaskSummary, Timeout: "50ms"}), logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// wait lets the run that timed out return before the logs are read
+	wait := func() {
+		for d.jobs[0].running.Load() {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	d.runJob(context.Background(), d.jobs[0])
+	d.runJob(context.Background(), d.jobs[0])
+	if provider.Calls() != 1 {
+		t.Errorf("Provider received %d requests, want 1 while the first run is in progress", provider.Calls())
+	}
+	wait()
+	if !strings.Contains(logs.String(), `"level":"WARN","msg":"job still running, skipping this run","job":"Slow"`) {
+		t.Errorf("Logs didn't report the skipped run:\n%s", logs)
+	}
+
+	d.runJob(context.Background(), d.jobs[0])
+	wait()
+	if provider.Calls() != 2 {
+		t.Errorf("Provider received %d requests, want 2 once the first run has returned", provider.Calls())
+	}
+}
+
+func TestRun(t *testing.T) {
+	logger, logs := testLogger()
+	d, err := New(testConfig(config.Job{Name: "Fast", Schedule: "@hourly", Task: config.TaskSummary}), logger)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// Two jobs on a short interval run concurrently until shutdown
+	var runs atomic.Int32
+	d.jobs = []*job{
+		{Job: config.Job{Name: "a", Task: "count"}, schedule: schedule.Every(10 * time.Millisecond), timeout: time.Second},
+		{Job: config.Job{Name: "b", Task: "count"}, schedule: schedule.Every(10 * time.Millisecond), timeout: time.Second},
+	}
+	d.now = func() time.Time {
+		runs.Add(1)
+		return time.Now()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() didn't return after the context was cancelled")
+	}
+	for _, expected := range []string{`"msg":"daemon started","jobs":2`, `"job":"a","task":"count"`, `"job":"b","task":"count"`, `"msg":"daemon stopped"`} {
+		if !strings.Contains(logs.String(), expected) {
+			t.Errorf("Logs didn't contain %s:\n%s", expected, logs)
+		}
+	}
+	if runs.Load() < 4 {
+		t.Errorf("Jobs were scheduled %d times, want several", runs.Load())
+	}
+}
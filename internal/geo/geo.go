@@ -0,0 +1,62 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// EarthRadiusKm is the mean Earth radius used for great-circle distances
+const EarthRadiusKm = 6371.0088
+
+// compassPoints are the 16 compass directions, clockwise from north
+var compassPoints = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// Distance returns the great-circle distance in kilometers between two points
+// given in decimal degrees, using the haversine formula
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dPhi := toRadians(lat2 - lat1)
+	dLambda := toRadians(lon2 - lon1)
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// Bearing returns the initial great-circle bearing in degrees clockwise from north,
+// in [0, 360), for travelling from the first point to the second
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := toRadians(lat1)
+	phi2 := toRadians(lat2)
+	dLambda := toRadians(lon2 - lon1)
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(toDegrees(math.Atan2(y, x))+360, 360)
+}
+
+// Compass returns the 16-point compass direction closest to a bearing in degrees
+func Compass(bearing float64) string {
+	bearing = math.Mod(math.Mod(bearing, 360)+360, 360)
+	i := int(math.Round(bearing/22.5)) % len(compassPoints)
+	return compassPoints[i]
+}
+
+// FormatDistance formats a distance in kilometers, switching to meters below one kilometer
+func FormatDistance(km float64) string {
+	if km < 1 {
+		return fmt.Sprintf("%.0f m", km*1000)
+	}
+	return fmt.Sprintf("%.1f km", km)
+}
+
+// toRadians converts degrees to radians
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// toDegrees converts radians to degrees
+func toDegrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
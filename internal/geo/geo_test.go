@@ -0,0 +1,106 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// cityPairs are well-known great-circle distances and initial bearings
+var cityPairs = []struct {
+	name                   string
+	lat1, lon1, lat2, lon2 float64
+	distanceKm             float64
+	bearing                float64
+}{
+	{"London to Paris", 51.5074, -0.1278, 48.8566, 2.3522, 343.5, 148.1},
+	{"New York to Los Angeles", 40.7128, -74.0060, 34.0522, -118.2437, 3935.7, 273.7},
+	{"London to New York", 51.5074, -0.1278, 40.7128, -74.0060, 5570.2, 288.3},
+	{"Tokyo to Osaka", 35.6895, 139.6917, 34.6937, 135.5023, 396.4, 255.0},
+	{"Sydney to Auckland", -33.8688, 151.2093, -36.8485, 174.7633, 2155.9, 105.6},
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+		tolerance              float64
+	}{
+		{"Same point", 35.6895, 139.6917, 35.6895, 139.6917, 0, 1e-9},
+		{"One degree of latitude", 0, 0, 1, 0, 111.195, 0.01},
+		{"Antipodes", 0, 0, 0, 180, math.Pi * EarthRadiusKm, 0.01},
+		{"Across the antimeridian", 0, 179.5, 0, -179.5, 111.195, 0.01},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("Distance() = %.4f km, want %.4f km", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistanceCityPairs(t *testing.T) {
+	for _, tt := range cityPairs {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2); math.Abs(got-tt.distanceKm) > 1 {
+				t.Errorf("Distance() = %.1f km, want %.1f km", got, tt.distanceKm)
+			}
+			// Distance is symmetric
+			if got, back := Distance(tt.lat1, tt.lon1, tt.lat2, tt.lon2), Distance(tt.lat2, tt.lon2, tt.lat1, tt.lon1); math.Abs(got-back) > 1e-9 {
+				t.Errorf("Distance() is not symmetric: %.6f vs %.6f", got, back)
+			}
+		})
+	}
+}
+
+func TestBearing(t *testing.T) {
+	for _, tt := range cityPairs {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Bearing(tt.lat1, tt.lon1, tt.lat2, tt.lon2); math.Abs(got-tt.bearing) > 0.5 {
+				t.Errorf("Bearing() = %.1f°, want %.1f°", got, tt.bearing)
+			}
+		})
+	}
+
+	if got := Bearing(0, 0, 1, 0); math.Abs(got) > 1e-9 {
+		t.Errorf("Bearing() due north = %f, want 0", got)
+	}
+	if got := Bearing(0, 0, 0, -1); math.Abs(got-270) > 1e-9 {
+		t.Errorf("Bearing() due west = %f, want 270", got)
+	}
+}
+
+func TestCompass(t *testing.T) {
+	tests := map[float64]string{
+		0:     "N",
+		11:    "N",
+		12:    "NNE",
+		45:    "NE",
+		148.1: "SSE",
+		255:   "WSW",
+		350:   "N",
+		-90:   "W",
+		720:   "N",
+	}
+	for bearing, want := range tests {
+		if got := Compass(bearing); got != want {
+			t.Errorf("Compass(%v) = %s, want %s", bearing, got, want)
+		}
+	}
+}
+
+func TestFormatDistance(t *testing.T) {
+	tests := map[float64]string{
+		0.25:   "250 m",
+		1:      "1.0 km",
+		343.56: "343.6 km",
+	}
+	for km, want := range tests {
+		if got := FormatDistance(km); got != want {
+			t.Errorf("FormatDistance(%v) = %s, want %s", km, got, want)
+		}
+	}
+}
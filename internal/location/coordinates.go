@@ -0,0 +1,171 @@
+package location
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/geo"
+)
+
+// NearbyRadiusKm is the distance under which a new location is reported as a likely duplicate
+const NearbyRadiusKm = 0.3
+
+// dmsPattern matches degrees with optional minutes, seconds and hemisphere, e.g. 35°41'22"N
+var dmsPattern = regexp.MustCompile(`^([+-]?\d+(?:\.\d+)?)\s*°?\s*(?:(\d+(?:\.\d+)?)\s*['′]\s*)?(?:(\d+(?:\.\d+)?)\s*(?:"|″|'')\s*)?([NSEWnsew])?$`)
+
+// ParseLatitude parses a latitude in decimal degrees or DMS notation
+func ParseLatitude(s string) (float64, error) {
+	lat, err := parseCoordinate(s, "NS")
+	if err != nil {
+		return 0, fmt.Errorf("invalid latitude '%s': %w", s, err)
+	}
+	return lat, nil
+}
+
+// ParseLongitude parses a longitude in decimal degrees or DMS notation
+func ParseLongitude(s string) (float64, error) {
+	lon, err := parseCoordinate(s, "EW")
+	if err != nil {
+		return 0, fmt.Errorf("invalid longitude '%s': %w", s, err)
+	}
+	return lon, nil
+}
+
+// parseCoordinate parses a decimal or DMS value; hemispheres lists the allowed
+// positive and negative hemisphere letters
+func parseCoordinate(s string, hemispheres string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+
+	m := dmsPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, errors.New("expected decimal degrees or degrees/minutes/seconds such as 35°41'22\"N")
+	}
+
+	degrees, _ := strconv.ParseFloat(m[1], 64)
+	var minutes, seconds float64
+	if m[2] != "" {
+		minutes, _ = strconv.ParseFloat(m[2], 64)
+	}
+	if m[3] != "" {
+		seconds, _ = strconv.ParseFloat(m[3], 64)
+	}
+	if minutes >= 60 || seconds >= 60 {
+		return 0, errors.New("minutes and seconds must be less than 60")
+	}
+
+	value := math.Abs(degrees) + minutes/60 + seconds/3600
+	if strings.HasPrefix(m[1], "-") {
+		value = -value
+	}
+
+	if hemisphere := strings.ToUpper(m[4]); hemisphere != "" {
+		switch {
+		case !strings.Contains(hemispheres, hemisphere):
+			return 0, fmt.Errorf("hemisphere must be one of %s", strings.Join(strings.Split(hemispheres, ""), "/"))
+		case strings.HasPrefix(m[1], "-"):
+			return 0, errors.New("use either a sign or a hemisphere, not both")
+		case hemisphere == hemispheres[1:]:
+			value = -value
+		}
+	}
+	return value, nil
+}
+
+// ParseGeoURI parses an RFC 5870 geo: URI such as geo:35.6895,139.6917;u=30
+func ParseGeoURI(uri string) (float64, float64, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(uri), "geo:")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid geo URI '%s': must start with geo:", uri)
+	}
+
+	// Drop parameters (;u=..., ;crs=...) and query strings (?z=...)
+	if i := strings.IndexAny(rest, ";?"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, fmt.Errorf("invalid geo URI '%s': expected geo:<latitude>,<longitude>", uri)
+	}
+
+	lat, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid geo URI '%s': invalid latitude", uri)
+	}
+	lon, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid geo URI '%s': invalid longitude", uri)
+	}
+	return lat, lon, nil
+}
+
+// NormalizeCoordinates rejects unusable coordinates and wraps the longitude into [-180, 180]
+func NormalizeCoordinates(lat, lon float64) (float64, float64, error) {
+	if math.IsNaN(lat) || math.IsInf(lat, 0) || lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("invalid latitude %v. Must be between -90 and 90", lat)
+	}
+	if math.IsNaN(lon) || math.IsInf(lon, 0) {
+		return 0, 0, fmt.Errorf("invalid longitude %v", lon)
+	}
+	if lon < -180 || lon > 180 {
+		lon = math.Mod(math.Mod(lon+180, 360)+360, 360) - 180
+	}
+	return lat, lon, nil
+}
+
+// NearbyLocation is a saved location close to a given point
+type NearbyLocation struct {
+	Location   config.Location
+	DistanceKm float64
+	// Bearing is the direction from the point to the location, in degrees from north
+	Bearing float64
+}
+
+// NearestLocations ranks the saved locations by great-circle distance from a point,
+// closest first. A limit of zero or less returns every location.
+func (m *Manager) NearestLocations(lat, lon float64, limit int) []NearbyLocation {
+	ranked := make([]NearbyLocation, 0, len(m.cfg.Locations))
+	for _, loc := range m.cfg.Locations {
+		ranked = append(ranked, NearbyLocation{
+			Location:   loc,
+			DistanceKm: geo.Distance(lat, lon, loc.Latitude, loc.Longitude),
+			Bearing:    geo.Bearing(lat, lon, loc.Latitude, loc.Longitude),
+		})
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].DistanceKm < ranked[j].DistanceKm })
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// NearestLocation returns the saved location closest to a point, or nil when none are saved
+func (m *Manager) NearestLocation(lat, lon float64) *NearbyLocation {
+	ranked := m.NearestLocations(lat, lon, 1)
+	if len(ranked) == 0 {
+		return nil
+	}
+	return &ranked[0]
+}
+
+// NearbyLocations returns the saved locations within radiusKm of a point, closest first
+func (m *Manager) NearbyLocations(lat, lon, radiusKm float64) []NearbyLocation {
+	var nearby []NearbyLocation
+	for _, n := range m.NearestLocations(lat, lon, 0) {
+		if n.DistanceKm > radiusKm {
+			break
+		}
+		nearby = append(nearby, n)
+	}
+	return nearby
+}
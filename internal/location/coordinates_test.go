@@ -0,0 +1,158 @@
+package location
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"weather-cli/internal/config"
+)
+
+func TestParseLatitudeLongitude(t *testing.T) {
+	tests := []struct {
+		input   string
+		parse   func(string) (float64, error)
+		want    float64
+		wantErr bool
+	}{
+		{"35.6895", ParseLatitude, 35.6895, false},
+		{"-74.0060", ParseLongitude, -74.0060, false},
+		{`35°41'22"N`, ParseLatitude, 35 + 41.0/60 + 22.0/3600, false},
+		{`33°52'04.0"S`, ParseLatitude, -(33 + 52.0/60 + 4.0/3600), false},
+		{`139°41′30″E`, ParseLongitude, 139 + 41.0/60 + 30.0/3600, false},
+		{`74° 0' 21.6" W`, ParseLongitude, -(74 + 21.6/3600), false},
+		{"51.5N", ParseLatitude, 51.5, false},
+		{`35°41'N`, ParseLatitude, 35 + 41.0/60, false},
+		{`35°41'22"E`, ParseLatitude, 0, true},
+		{`-35°41'22"S`, ParseLatitude, 0, true},
+		{`35°61'N`, ParseLatitude, 0, true},
+		{"north", ParseLatitude, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.parse(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("parse(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseGeoURI(t *testing.T) {
+	tests := []struct {
+		input   string
+		lat     float64
+		lon     float64
+		wantErr bool
+	}{
+		{"geo:35.6895,139.6917", 35.6895, 139.6917, false},
+		{"geo:37.786971,-122.399677;u=35", 37.786971, -122.399677, false},
+		{"geo:48.2010,16.3695,183", 48.2010, 16.3695, false},
+		{"geo:0,0?z=10", 0, 0, false},
+		{"geo:35.6895", 0, 0, true},
+		{"35.6895,139.6917", 0, 0, true},
+		{"geo:north,east", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		lat, lon, err := ParseGeoURI(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseGeoURI(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (lat != tt.lat || lon != tt.lon) {
+			t.Errorf("ParseGeoURI(%q) = %v,%v, want %v,%v", tt.input, lat, lon, tt.lat, tt.lon)
+		}
+	}
+}
+
+func TestNormalizeCoordinates(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		wantLon  float64
+		wantErr  bool
+	}{
+		{35.6895, 139.6917, 139.6917, false},
+		{0, 180, 180, false},
+		{0, -180, -180, false},
+		{0, 190, -170, false},
+		{0, -999, 81, false},
+		{0, 540, -180, false},
+		{200, 0, 0, true},
+		{-90.5, 0, 0, true},
+		{math.NaN(), 0, 0, true},
+		{0, math.Inf(1), 0, true},
+	}
+
+	for _, tt := range tests {
+		lat, lon, err := NormalizeCoordinates(tt.lat, tt.lon)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeCoordinates(%v, %v) error = %v, wantErr %v", tt.lat, tt.lon, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (lat != tt.lat || math.Abs(lon-tt.wantLon) > 1e-9) {
+			t.Errorf("NormalizeCoordinates(%v, %v) = %v,%v, want %v,%v", tt.lat, tt.lon, lat, lon, tt.lat, tt.wantLon)
+		}
+	}
+}
+
+func TestNearbyLocations(t *testing.T) {
+	manager := NewManager(&config.Config{
+		Locations: []config.Location{
+			{Name: "Shinjuku", Latitude: 35.6938, Longitude: 139.7034},
+			{Name: "Tokyo Station", Latitude: 35.6812, Longitude: 139.7671},
+			{Name: "Shinjuku Gyoen", Latitude: 35.6852, Longitude: 139.7101},
+		},
+	})
+
+	nearby := manager.NearbyLocations(35.6925, 139.7020, NearbyRadiusKm)
+	if len(nearby) != 1 || nearby[0].Location.Name != "Shinjuku" {
+		t.Fatalf("NearbyLocations() = %+v, want only Shinjuku", nearby)
+	}
+	if nearby[0].DistanceKm <= 0 || nearby[0].DistanceKm > NearbyRadiusKm {
+		t.Errorf("Unexpected distance %.3f km", nearby[0].DistanceKm)
+	}
+
+	nearby = manager.NearbyLocations(35.6925, 139.7020, 2)
+	if len(nearby) != 2 || nearby[1].Location.Name != "Shinjuku Gyoen" {
+		t.Errorf("NearbyLocations() should sort by distance, got %+v", nearby)
+	}
+}
+
+func TestNearestLocations(t *testing.T) {
+	manager := NewManager(&config.Config{
+		Locations: []config.Location{
+			{Name: "London", Latitude: 51.5074, Longitude: -0.1278},
+			{Name: "Paris", Latitude: 48.8566, Longitude: 2.3522},
+			{Name: "New York", Latitude: 40.7128, Longitude: -74.0060},
+		},
+	})
+
+	// Brussels is closer to Paris than to London
+	ranked := manager.NearestLocations(50.8503, 4.3517, 0)
+	var names []string
+	for _, n := range ranked {
+		names = append(names, n.Location.Name)
+	}
+	if strings.Join(names, ",") != "Paris,London,New York" {
+		t.Errorf("NearestLocations() order = %v", names)
+	}
+	if ranked[0].Bearing < 180 || ranked[0].Bearing > 270 {
+		t.Errorf("Paris should be south-west of Brussels, got bearing %.1f", ranked[0].Bearing)
+	}
+
+	if got := manager.NearestLocations(50.8503, 4.3517, 2); len(got) != 2 {
+		t.Errorf("NearestLocations() with limit 2 returned %d locations", len(got))
+	}
+
+	nearest := manager.NearestLocation(40.7580, -73.9855)
+	if nearest == nil || nearest.Location.Name != "New York" {
+		t.Errorf("NearestLocation() = %+v, want New York", nearest)
+	}
+
+	if NewManager(&config.Config{}).NearestLocation(0, 0) != nil {
+		t.Error("NearestLocation() should return nil without saved locations")
+	}
+}
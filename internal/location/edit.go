@@ -0,0 +1,267 @@
+package location
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+// Change describes one field modified by a rename or edit
+type Change struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// String formats the change as "field: old -> new"
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, c.Old, c.New)
+}
+
+// LocationEdit lists the fields to change on a saved location; nil fields are left as they are
+type LocationEdit struct {
+	Latitude  *float64
+	Longitude *float64
+	Timezone  *string
+	Aliases   *[]string
+}
+
+// RenameLocation gives a saved location a new name, keeping the default location pointing at it
+func (m *Manager) RenameLocation(oldName, newName string) ([]Change, error) {
+	var changes []Change
+	err := config.Update(m.cfg, func() error {
+		var err error
+		changes, err = m.renameLocation(oldName, newName)
+		return err
+	})
+	return changes, err
+}
+
+// renameLocation applies a rename to the loaded config
+func (m *Manager) renameLocation(oldName, newName string) ([]Change, error) {
+	i := findLocation(m.cfg.Locations, oldName)
+	if i < 0 {
+		return nil, ErrLocationNotFound
+	}
+	loc := &m.cfg.Locations[i]
+
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, errors.New("new name must not be empty")
+	}
+	if j := findLocation(m.cfg.Locations, newName); j >= 0 && j != i {
+		return nil, nameTaken("'%s' is already used by location '%s'", newName, m.cfg.Locations[j].Name)
+	}
+	if newName == loc.Name {
+		return nil, nil
+	}
+
+	changes := []Change{{Field: "name", Old: loc.Name, New: newName}}
+
+	// A location renamed to one of its own aliases no longer needs that alias
+	if j := indexFold(loc.Aliases, newName); j >= 0 {
+		aliases := slices.Delete(slices.Clone(loc.Aliases), j, j+1)
+		changes = append(changes, Change{Field: "aliases", Old: formatList(loc.Aliases), New: formatList(aliases)})
+		loc.Aliases = aliases
+	}
+
+	if m.cfg.DefaultLocation == loc.Name {
+		changes = append(changes, Change{Field: "default_location", Old: loc.Name, New: newName})
+		m.cfg.SetDefaultLocation(newName)
+	}
+	loc.Name = newName
+
+	return changes, nil
+}
+
+// EditLocation changes the coordinates, timezone or aliases of a saved location
+func (m *Manager) EditLocation(name string, edit LocationEdit) ([]Change, error) {
+	var changes []Change
+	err := config.Update(m.cfg, func() error {
+		var err error
+		changes, err = m.editLocation(name, edit)
+		return err
+	})
+	return changes, err
+}
+
+// editLocation applies an edit to the loaded config
+func (m *Manager) editLocation(name string, edit LocationEdit) ([]Change, error) {
+	i := findLocation(m.cfg.Locations, name)
+	if i < 0 {
+		return nil, ErrLocationNotFound
+	}
+	updated := m.cfg.Locations[i]
+	var changes []Change
+
+	if edit.Latitude != nil || edit.Longitude != nil {
+		lat, lon := updated.Latitude, updated.Longitude
+		if edit.Latitude != nil {
+			lat = *edit.Latitude
+		}
+		if edit.Longitude != nil {
+			lon = *edit.Longitude
+		}
+		lat, lon, err := NormalizeCoordinates(lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		if lat != updated.Latitude {
+			changes = append(changes, Change{Field: "latitude", Old: formatCoordinate(updated.Latitude), New: formatCoordinate(lat)})
+		}
+		if lon != updated.Longitude {
+			changes = append(changes, Change{Field: "longitude", Old: formatCoordinate(updated.Longitude), New: formatCoordinate(lon)})
+		}
+		updated.Latitude, updated.Longitude = lat, lon
+	}
+
+	if edit.Timezone != nil && *edit.Timezone != updated.Timezone {
+		if err := checkTimezone(*edit.Timezone); err != nil {
+			return nil, err
+		}
+		changes = append(changes, Change{Field: "timezone", Old: formatValue(updated.Timezone), New: formatValue(*edit.Timezone)})
+		updated.Timezone = *edit.Timezone
+	}
+
+	if edit.Aliases != nil && !slices.Equal(*edit.Aliases, updated.Aliases) {
+		aliases := *edit.Aliases
+		for k, alias := range aliases {
+			if config.NormalizeName(alias) == "" {
+				return nil, errors.New("alias must not be empty")
+			}
+			if j := findLocation(m.cfg.Locations, alias); j >= 0 && j != i {
+				return nil, nameTaken("'%s' is already used by location '%s'", alias, m.cfg.Locations[j].Name)
+			}
+			if config.NormalizeName(alias) == config.NormalizeName(updated.Name) || indexFold(aliases[:k], alias) >= 0 {
+				return nil, fmt.Errorf("alias '%s' is given more than once", alias)
+			}
+		}
+		changes = append(changes, Change{Field: "aliases", Old: formatList(updated.Aliases), New: formatList(aliases)})
+		updated.Aliases = aliases
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	m.cfg.Locations[i] = updated
+	return changes, nil
+}
+
+// LocationChange lists the changes to make to a saved location at once; nil fields
+// are left as they are
+type LocationChange struct {
+	LocationEdit
+	Name *string
+	// Tags replaces the location's tags
+	Tags *[]string
+}
+
+// ChangeLocation edits, retags and renames a saved location in one update of the
+// config file, making none of the changes when one is rejected. It returns the
+// name the location ends up with.
+func (m *Manager) ChangeLocation(name string, change LocationChange) (string, error) {
+	err := config.Update(m.cfg, func() error {
+		return m.undoOnError(func() error {
+			var err error
+			name, err = m.changeLocation(name, change)
+			return err
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// CreateLocation adds a new location and makes the changes to it in one update of
+// the config file, adding nothing when a change is rejected
+func (m *Manager) CreateLocation(name string, lat, lon float64, change LocationChange) error {
+	lat, lon, err := NormalizeCoordinates(lat, lon)
+	if err != nil {
+		return err
+	}
+
+	return config.Update(m.cfg, func() error {
+		return m.undoOnError(func() error {
+			if err := m.addLocation(name, lat, lon); err != nil {
+				return err
+			}
+			_, err := m.changeLocation(name, change)
+			return err
+		})
+	})
+}
+
+// changeLocation applies a change to the loaded config, leaving it partly applied
+// when it fails
+func (m *Manager) changeLocation(name string, change LocationChange) (string, error) {
+	if _, err := m.editLocation(name, change.LocationEdit); err != nil {
+		return "", err
+	}
+	i := findLocation(m.cfg.Locations, name)
+	if i < 0 {
+		return "", ErrLocationNotFound
+	}
+	name = m.cfg.Locations[i].Name
+
+	if change.Tags != nil {
+		if err := m.setTags(i, *change.Tags); err != nil {
+			return "", err
+		}
+	}
+	if change.Name != nil {
+		if _, err := m.renameLocation(name, *change.Name); err != nil {
+			return "", err
+		}
+		name = m.cfg.Locations[i].Name
+	}
+	return name, nil
+}
+
+// undoOnError runs fn on the loaded config and restores the locations and the
+// default location when it fails, so that a rejected change leaves nothing behind
+func (m *Manager) undoOnError(fn func() error) error {
+	var locations []config.Location
+	for _, loc := range m.cfg.Locations {
+		loc.Aliases = slices.Clone(loc.Aliases)
+		loc.Tags = slices.Clone(loc.Tags)
+		locations = append(locations, loc)
+	}
+	defaultLocation := m.cfg.DefaultLocation
+
+	if err := fn(); err != nil {
+		m.cfg.Locations, m.cfg.DefaultLocation = locations, defaultLocation
+		return err
+	}
+	return nil
+}
+
+// formatCoordinate formats a coordinate the way locations are listed
+func formatCoordinate(v float64) string {
+	return fmt.Sprintf("%.4f", v)
+}
+
+// formatValue shows empty values explicitly in a change report
+func formatValue(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// formatList formats a list of names for a change report
+func formatList(values []string) string {
+	return formatValue(strings.Join(values, ", "))
+}
+
+// checkTimezone verifies that tz is an IANA time zone name
+func checkTimezone(tz string) error {
+	if _, err := time.LoadLocation(tz); err != nil || tz == "Local" {
+		return fmt.Errorf("invalid timezone '%s'. Use an IANA name such as Asia/Tokyo", tz)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package location
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"weather-cli/internal/config"
+)
+
+func TestRenameLocation(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "tokyo", Aliases: []string{"hq", "Tokyo Office"}, Tags: []string{"offices"}},
+			{Name: "Berlin"},
+		},
+		DefaultLocation: "tokyo",
+	}
+	manager := NewManager(cfg)
+
+	changes, err := manager.RenameLocation("TOKYO", "Tokyo Office")
+	if err != nil {
+		t.Fatalf("RenameLocation() failed: %v", err)
+	}
+	want := []Change{
+		{Field: "name", Old: "tokyo", New: "Tokyo Office"},
+		{Field: "aliases", Old: "hq, Tokyo Office", New: "hq"},
+		{Field: "default_location", Old: "tokyo", New: "Tokyo Office"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("RenameLocation() changes = %v, want %v", changes, want)
+	}
+	if cfg.DefaultLocation != "Tokyo Office" {
+		t.Errorf("Default location should follow the rename, got %s", cfg.DefaultLocation)
+	}
+	if len(manager.LocationsByTag("offices")) != 1 {
+		t.Errorf("Renamed location should stay in its groups")
+	}
+
+	if _, err := manager.RenameLocation("Berlin", "HQ"); err == nil {
+		t.Errorf("RenameLocation() should reject a name used as another location's alias")
+	}
+	if _, err := manager.RenameLocation("Berlin", " "); err == nil {
+		t.Errorf("RenameLocation() should reject an empty name")
+	}
+	if _, err := manager.RenameLocation("Paris", "Lyon"); err == nil {
+		t.Errorf("RenameLocation() should fail for an unknown location")
+	}
+}
+
+func TestEditLocation(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
+			{Name: "Berlin", Latitude: 52.52, Longitude: 13.405, Aliases: []string{"ber"}},
+		},
+	}
+	manager := NewManager(cfg)
+
+	lat := 35.6812
+	tz := "Asia/Tokyo"
+	aliases := []string{"hq"}
+	changes, err := manager.EditLocation("tokyo", LocationEdit{Latitude: &lat, Timezone: &tz, Aliases: &aliases})
+	if err != nil {
+		t.Fatalf("EditLocation() failed: %v", err)
+	}
+	want := []Change{
+		{Field: "latitude", Old: "35.6895", New: "35.6812"},
+		{Field: "timezone", Old: "(none)", New: "Asia/Tokyo"},
+		{Field: "aliases", Old: "(none)", New: "hq"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("EditLocation() changes = %v, want %v", changes, want)
+	}
+	if got := cfg.Locations[0]; got.Latitude != lat || got.Longitude != 139.6917 || got.Timezone != tz {
+		t.Errorf("EditLocation() did not update the location: %+v", got)
+	}
+
+	changes, err = manager.EditLocation("Tokyo", LocationEdit{Latitude: &lat})
+	if err != nil || len(changes) != 0 {
+		t.Errorf("EditLocation() with identical values should report no changes, got %v, %v", changes, err)
+	}
+
+	badLat := 200.0
+	nan := math.NaN()
+	badTZ := "Mars/Olympus"
+	taken := []string{"ber"}
+	invalid := []LocationEdit{
+		{Latitude: &badLat},
+		{Longitude: &nan},
+		{Timezone: &badTZ},
+		{Aliases: &taken},
+	}
+	for _, edit := range invalid {
+		if _, err := manager.EditLocation("Tokyo", edit); err == nil {
+			t.Errorf("EditLocation(%+v) should fail", edit)
+		}
+	}
+	if cfg.Locations[0].Latitude != lat || cfg.Locations[0].Timezone != tz {
+		t.Errorf("A failed edit should not change the location: %+v", cfg.Locations[0])
+	}
+}
+
+func TestChangeLocation(t *testing.T) {
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917, Tags: []string{"asia", "offices"}},
+			{Name: "Berlin", Latitude: 52.52, Longitude: 13.405},
+		},
+		DefaultLocation: "Tokyo",
+	}
+	manager := NewManager(cfg)
+
+	lat := 35.6812
+	name := "HQ"
+	tags := []string{"Offices", "Japan"}
+	got, err := manager.ChangeLocation("tokyo", LocationChange{LocationEdit: LocationEdit{Latitude: &lat}, Name: &name, Tags: &tags})
+	if err != nil || got != "HQ" {
+		t.Fatalf("ChangeLocation() = %q, %v", got, err)
+	}
+	want := config.Location{Name: "HQ", Latitude: lat, Longitude: 139.6917, Tags: []string{"offices", "japan"}}
+	if !reflect.DeepEqual(cfg.Locations[0], want) || cfg.DefaultLocation != "HQ" {
+		t.Errorf("ChangeLocation() location = %+v, default %q, want %+v", cfg.Locations[0], cfg.DefaultLocation, want)
+	}
+
+	// A rejected rename undoes the edit and the new tags
+	other := 1.0
+	taken := "berlin"
+	if _, err := manager.ChangeLocation("HQ", LocationChange{LocationEdit: LocationEdit{Latitude: &other}, Name: &taken, Tags: &[]string{"moved"}}); err == nil {
+		t.Fatal("ChangeLocation() to a taken name error = nil")
+	}
+	if !reflect.DeepEqual(cfg.Locations[0], want) || cfg.DefaultLocation != "HQ" {
+		t.Errorf("A failed change should leave the location alone: %+v", cfg.Locations[0])
+	}
+
+	if err := manager.CreateLocation("Osaka", 34.69, 135.5, LocationChange{Tags: &[]string{""}}); err == nil {
+		t.Error("CreateLocation() with an empty tag error = nil")
+	}
+	if len(cfg.Locations) != 2 {
+		t.Errorf("A failed create should add nothing: %+v", cfg.Locations)
+	}
+}
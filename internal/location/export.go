@@ -0,0 +1,222 @@
+package location
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"weather-cli/internal/config"
+)
+
+// Supported import and export formats
+const (
+	FormatGeoJSON = "geojson"
+	FormatGPX     = "gpx"
+	FormatKML     = "kml"
+	FormatCSV     = "csv"
+)
+
+// csvHeader is the column layout used for CSV export; aliases and tags are ';'-separated
+var csvHeader = []string{"name", "latitude", "longitude", "aliases", "tags", "timezone"}
+
+// geoJSONFeatureCollection is the GeoJSON document for a list of locations
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string            `json:"type"`
+	Geometry   *geoJSONGeometry  `json:"geometry"`
+	Properties geoJSONProperties `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONProperties struct {
+	Name     string   `json:"name"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Timezone string   `json:"timezone,omitempty"`
+}
+
+// gpxDocument is a GPX 1.1 file holding one waypoint per location
+type gpxDocument struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Xmlns     string        `xml:"xmlns,attr"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+}
+
+type gpxWaypoint struct {
+	Lat  string `xml:"lat,attr"`
+	Lon  string `xml:"lon,attr"`
+	Name string `xml:"name"`
+	Cmt  string `xml:"cmt,omitempty"`
+	Desc string `xml:"desc,omitempty"`
+	Type string `xml:"type,omitempty"`
+}
+
+// kmlDocument is a KML 2.2 file holding one placemark per location
+type kmlDocument struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Document struct {
+		Name       string         `xml:"name"`
+		Placemarks []kmlPlacemark `xml:"Placemark"`
+	} `xml:"Document"`
+}
+
+type kmlPlacemark struct {
+	Name         string    `xml:"name"`
+	ExtendedData *kmlData  `xml:"ExtendedData,omitempty"`
+	Point        *kmlPoint `xml:"Point"`
+}
+
+type kmlData struct {
+	Data []kmlDataValue `xml:"Data"`
+}
+
+type kmlDataValue struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+// ExportLocations writes locations to w in the given format
+func ExportLocations(w io.Writer, locations []config.Location, format string) error {
+	switch format {
+	case FormatGeoJSON:
+		return exportGeoJSON(w, locations)
+	case FormatGPX:
+		return exportGPX(w, locations)
+	case FormatKML:
+		return exportKML(w, locations)
+	case FormatCSV:
+		return exportCSV(w, locations)
+	default:
+		return fmt.Errorf("unsupported format '%s'. Use geojson, gpx, kml or csv", format)
+	}
+}
+
+func exportGeoJSON(w io.Writer, locations []config.Location) error {
+	doc := geoJSONFeatureCollection{Type: "FeatureCollection", Features: []geoJSONFeature{}}
+	for _, loc := range locations {
+		// GeoJSON positions are [longitude, latitude]
+		coordinates, err := json.Marshal([]float64{loc.Longitude, loc.Latitude})
+		if err != nil {
+			return fmt.Errorf("error marshaling coordinates: %w", err)
+		}
+		doc.Features = append(doc.Features, geoJSONFeature{
+			Type:     "Feature",
+			Geometry: &geoJSONGeometry{Type: "Point", Coordinates: coordinates},
+			Properties: geoJSONProperties{
+				Name:     loc.Name,
+				Aliases:  loc.Aliases,
+				Tags:     loc.Tags,
+				Timezone: loc.Timezone,
+			},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+func exportGPX(w io.Writer, locations []config.Location) error {
+	doc := gpxDocument{Version: "1.1", Creator: "weather-cli", Xmlns: "http://www.topografix.com/GPX/1/1"}
+	for _, loc := range locations {
+		wpt := gpxWaypoint{
+			Lat:  formatFloat(loc.Latitude),
+			Lon:  formatFloat(loc.Longitude),
+			Name: loc.Name,
+			Cmt:  loc.Timezone,
+			Type: strings.Join(loc.Tags, ";"),
+		}
+		if len(loc.Aliases) > 0 {
+			wpt.Desc = "aliases: " + strings.Join(loc.Aliases, ";")
+		}
+		doc.Waypoints = append(doc.Waypoints, wpt)
+	}
+	return writeXML(w, doc)
+}
+
+func exportKML(w io.Writer, locations []config.Location) error {
+	doc := kmlDocument{Xmlns: "http://www.opengis.net/kml/2.2"}
+	doc.Document.Name = "weather-cli locations"
+	for _, loc := range locations {
+		placemark := kmlPlacemark{
+			Name: loc.Name,
+			// KML coordinates are longitude,latitude
+			Point: &kmlPoint{Coordinates: formatFloat(loc.Longitude) + "," + formatFloat(loc.Latitude)},
+		}
+		var data []kmlDataValue
+		if len(loc.Aliases) > 0 {
+			data = append(data, kmlDataValue{Name: "aliases", Value: strings.Join(loc.Aliases, ";")})
+		}
+		if len(loc.Tags) > 0 {
+			data = append(data, kmlDataValue{Name: "tags", Value: strings.Join(loc.Tags, ";")})
+		}
+		if loc.Timezone != "" {
+			data = append(data, kmlDataValue{Name: "timezone", Value: loc.Timezone})
+		}
+		if len(data) > 0 {
+			placemark.ExtendedData = &kmlData{Data: data}
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, placemark)
+	}
+	return writeXML(w, doc)
+}
+
+func exportCSV(w io.Writer, locations []config.Location) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("error writing CSV: %w", err)
+	}
+	for _, loc := range locations {
+		record := []string{
+			loc.Name,
+			formatFloat(loc.Latitude),
+			formatFloat(loc.Longitude),
+			strings.Join(loc.Aliases, ";"),
+			strings.Join(loc.Tags, ";"),
+			loc.Timezone,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeXML writes an indented XML document with its declaration
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error encoding XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// formatFloat formats a coordinate without losing precision
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,49 @@
+package location
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"weather-cli/internal/config"
+)
+
+// exportFixture returns locations exercising every exported field
+func exportFixture() []config.Location {
+	return []config.Location{
+		{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917, Aliases: []string{"tyo", "home"}, Tags: []string{"japan"}, Timezone: "Asia/Tokyo"},
+		{Name: "São Paulo", Latitude: -23.5505, Longitude: -46.6333},
+	}
+}
+
+func TestExportLocations(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{FormatGeoJSON, []string{`"type": "FeatureCollection"`, `139.6917`, `"aliases": [`, `"timezone": "Asia/Tokyo"`}},
+		{FormatGPX, []string{`<wpt lat="35.6895" lon="139.6917">`, `<name>São Paulo</name>`, `<desc>aliases: tyo;home</desc>`, `<type>japan</type>`}},
+		{FormatKML, []string{`<coordinates>139.6917,35.6895</coordinates>`, `<Data name="timezone">`}},
+		{FormatCSV, []string{"name,latitude,longitude,aliases,tags,timezone\n", "Tokyo,35.6895,139.6917,tyo;home,japan,Asia/Tokyo\n", "São Paulo,-23.5505,-46.6333,,,\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportLocations(&buf, exportFixture(), tt.format); err != nil {
+				t.Fatalf("ExportLocations() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("ExportLocations() output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExportLocationsUnknownFormat(t *testing.T) {
+	if err := ExportLocations(&bytes.Buffer{}, exportFixture(), "shapefile"); err == nil {
+		t.Error("ExportLocations() should fail for an unknown format")
+	}
+}
@@ -0,0 +1,66 @@
+package location
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather"
+)
+
+// GeocodeURL is the OpenWeather geocoding endpoint used to find places by name
+var GeocodeURL = "https://api.openweathermap.org/geo/1.0/direct"
+
+// Place is a geocoding search result
+type Place struct {
+	Name      string  `json:"name"`
+	State     string  `json:"state"`
+	Country   string  `json:"country"`
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+// String describes a place with its state and country, e.g. "Portland, Oregon, US"
+func (p Place) String() string {
+	parts := []string{p.Name}
+	for _, part := range []string{p.State, p.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Location converts a place into a location to save under its name
+func (p Place) Location() config.Location {
+	return config.Location{Name: p.Name, Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+// Search finds up to limit places matching a name such as "Paris" or "Paris,FR"
+func Search(query, apiKey string, limit int) ([]Place, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("limit", fmt.Sprint(limit))
+	params.Set("appid", apiKey)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(GeocodeURL + "?" + params.Encode())
+	if err != nil {
+		return nil, weather.RequestError("geocoding API", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, weather.ResponseError("geocoding API", resp)
+	}
+
+	var places []Place
+	if err := json.NewDecoder(resp.Body).Decode(&places); err != nil {
+		return nil, fmt.Errorf("error unmarshaling geocoding response: %w", err)
+	}
+	return places, nil
+}
@@ -0,0 +1,50 @@
+package location
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if q := r.URL.Query().Get("q"); q != "Portland" || r.URL.Query().Get("limit") != "5" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[
+			{"name": "Portland", "state": "Oregon", "country": "US", "lat": 45.5152, "lon": -122.6784},
+			{"name": "Portland", "country": "AU", "lat": -38.3446, "lon": 141.6042}
+		]`)
+	}))
+	defer server.Close()
+
+	old := GeocodeURL
+	GeocodeURL = server.URL
+	defer func() { GeocodeURL = old }()
+
+	places, err := Search("Portland", "test-key", 5)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(places) != 2 {
+		t.Fatalf("Search() returned %d places, want 2", len(places))
+	}
+	if got := places[0].String(); got != "Portland, Oregon, US" {
+		t.Errorf("places[0].String() = %q", got)
+	}
+	if got := places[1].String(); got != "Portland, AU" {
+		t.Errorf("places[1].String() = %q", got)
+	}
+	if loc := places[0].Location(); loc.Name != "Portland" || loc.Latitude != 45.5152 || loc.Longitude != -122.6784 {
+		t.Errorf("places[0].Location() = %+v", loc)
+	}
+
+	if _, err := Search("Portland", "wrong-key", 5); err == nil {
+		t.Error("Search() with a rejected key should fail")
+	}
+}
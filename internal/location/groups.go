@@ -0,0 +1,162 @@
+package location
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"weather-cli/internal/config"
+)
+
+// AddAliases adds alternative names to a saved location.
+// An alias must not clash with the name or alias of any location.
+func (m *Manager) AddAliases(name string, aliases ...string) error {
+	return config.Update(m.cfg, func() error {
+		i := findLocation(m.cfg.Locations, name)
+		if i < 0 {
+			return ErrLocationNotFound
+		}
+
+		for k, alias := range aliases {
+			if config.NormalizeName(alias) == "" {
+				return errors.New("alias must not be empty")
+			}
+			if j := findLocation(m.cfg.Locations, alias); j >= 0 {
+				return nameTaken("'%s' is already used by location '%s'", alias, m.cfg.Locations[j].Name)
+			}
+			if indexFold(aliases[:k], alias) >= 0 {
+				return fmt.Errorf("alias '%s' is given more than once", alias)
+			}
+		}
+
+		m.cfg.Locations[i].Aliases = append(m.cfg.Locations[i].Aliases, aliases...)
+		return nil
+	})
+}
+
+// RemoveAliases removes alternative names from a saved location
+func (m *Manager) RemoveAliases(name string, aliases ...string) error {
+	return config.Update(m.cfg, func() error {
+		i := findLocation(m.cfg.Locations, name)
+		if i < 0 {
+			return ErrLocationNotFound
+		}
+
+		loc := &m.cfg.Locations[i]
+		remaining := slices.Clone(loc.Aliases)
+		for _, alias := range aliases {
+			j := indexFold(remaining, alias)
+			if j < 0 {
+				return fmt.Errorf("location '%s' has no alias '%s'", loc.Name, alias)
+			}
+			remaining = slices.Delete(remaining, j, j+1)
+		}
+
+		loc.Aliases = remaining
+		return nil
+	})
+}
+
+// AddTags adds a saved location to one or more groups
+func (m *Manager) AddTags(name string, tags ...string) error {
+	return config.Update(m.cfg, func() error {
+		i := findLocation(m.cfg.Locations, name)
+		if i < 0 {
+			return ErrLocationNotFound
+		}
+
+		loc := &m.cfg.Locations[i]
+		updated := slices.Clone(loc.Tags)
+		for _, tag := range tags {
+			tag = config.NormalizeName(tag)
+			if tag == "" {
+				return errors.New("tag must not be empty")
+			}
+			if indexFold(updated, tag) < 0 {
+				updated = append(updated, tag)
+			}
+		}
+
+		loc.Tags = updated
+		return nil
+	})
+}
+
+// normalizeTags normalizes tags like AddTags does, dropping empty and repeated ones
+func normalizeTags(tags []string) []string {
+	var normalized []string
+	for _, tag := range tags {
+		if tag = config.NormalizeName(tag); tag != "" && indexFold(normalized, tag) < 0 {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// RemoveTags removes a saved location from one or more groups
+func (m *Manager) RemoveTags(name string, tags ...string) error {
+	return config.Update(m.cfg, func() error {
+		i := findLocation(m.cfg.Locations, name)
+		if i < 0 {
+			return ErrLocationNotFound
+		}
+
+		loc := &m.cfg.Locations[i]
+		remaining := slices.Clone(loc.Tags)
+		for _, tag := range tags {
+			j := indexFold(remaining, tag)
+			if j < 0 {
+				return fmt.Errorf("location '%s' has no tag '%s'", loc.Name, tag)
+			}
+			remaining = slices.Delete(remaining, j, j+1)
+		}
+
+		loc.Tags = remaining
+		return nil
+	})
+}
+
+// setTags replaces the tags of the saved location at i, keeping the order of the
+// tags it already has
+func (m *Manager) setTags(i int, tags []string) error {
+	loc := &m.cfg.Locations[i]
+	var updated []string
+	for _, tag := range loc.Tags {
+		if indexFold(tags, tag) >= 0 {
+			updated = append(updated, tag)
+		}
+	}
+	for _, tag := range tags {
+		tag = config.NormalizeName(tag)
+		if tag == "" {
+			return errors.New("tag must not be empty")
+		}
+		if indexFold(updated, tag) < 0 {
+			updated = append(updated, tag)
+		}
+	}
+
+	loc.Tags = updated
+	return nil
+}
+
+// LocationsByTag returns the saved locations in a group, in saved order
+func (m *Manager) LocationsByTag(tag string) []config.Location {
+	var locations []config.Location
+	for _, loc := range m.cfg.Locations {
+		if indexFold(loc.Tags, tag) >= 0 {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// indexFold returns the index of the value equal to s after name normalization, or -1
+func indexFold(values []string, s string) int {
+	key := config.NormalizeName(s)
+	for i, v := range values {
+		if config.NormalizeName(v) == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,97 @@
+package location
+
+import (
+	"reflect"
+	"testing"
+	"weather-cli/internal/config"
+)
+
+func groupsConfig() *config.Config {
+	return &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
+			{Name: "Berlin", Latitude: 52.52, Longitude: 13.405},
+			{Name: "Home", Latitude: 34.6937, Longitude: 135.5023},
+		},
+	}
+}
+
+func TestAliases(t *testing.T) {
+	cfg := groupsConfig()
+	manager := NewManager(cfg)
+
+	if err := manager.AddAliases("tokyo", "HQ", "tyo"); err != nil {
+		t.Fatalf("AddAliases() failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Locations[0].Aliases, []string{"HQ", "tyo"}) {
+		t.Errorf("AddAliases() did not add aliases: %v", cfg.Locations[0].Aliases)
+	}
+
+	loc, err := manager.GetLocation("hq")
+	if err != nil || loc.Name != "Tokyo" {
+		t.Errorf("GetLocation() should resolve aliases, got %v, %v", loc, err)
+	}
+
+	// Aliases share the namespace of names and other aliases
+	if err := manager.AddAliases("Berlin", "home"); err == nil {
+		t.Errorf("AddAliases() should reject an alias equal to another location's name")
+	}
+	if err := manager.AddAliases("Berlin", "TYO"); err == nil {
+		t.Errorf("AddAliases() should reject an alias used by another location")
+	}
+	if err := manager.AddAliases("Berlin", "ber", "BER"); err == nil {
+		t.Errorf("AddAliases() should reject duplicate aliases")
+	}
+	if len(cfg.Locations[1].Aliases) != 0 {
+		t.Errorf("A failed AddAliases() should not change the location: %v", cfg.Locations[1].Aliases)
+	}
+	if err := manager.AddLocation("hq", 0, 0); err == nil {
+		t.Errorf("AddLocation() should reject a name used as an alias")
+	}
+
+	if err := manager.RemoveAliases("Tokyo", "hq"); err != nil {
+		t.Fatalf("RemoveAliases() failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Locations[0].Aliases, []string{"tyo"}) {
+		t.Errorf("RemoveAliases() did not remove the alias: %v", cfg.Locations[0].Aliases)
+	}
+	if err := manager.RemoveAliases("Tokyo", "osaka"); err == nil {
+		t.Errorf("RemoveAliases() should fail for an unknown alias")
+	}
+}
+
+func TestTags(t *testing.T) {
+	cfg := groupsConfig()
+	manager := NewManager(cfg)
+
+	if err := manager.AddTags("Tokyo", "Offices", "travel"); err != nil {
+		t.Fatalf("AddTags() failed: %v", err)
+	}
+	if err := manager.AddTags("Berlin", "offices", "offices"); err != nil {
+		t.Fatalf("AddTags() failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Locations[1].Tags, []string{"offices"}) {
+		t.Errorf("AddTags() should store each tag once in lowercase: %v", cfg.Locations[1].Tags)
+	}
+
+	offices := manager.LocationsByTag("OFFICES")
+	if len(offices) != 2 || offices[0].Name != "Tokyo" || offices[1].Name != "Berlin" {
+		t.Errorf("LocationsByTag() = %v, want Tokyo and Berlin", offices)
+	}
+	if len(manager.LocationsByTag("family")) != 0 {
+		t.Errorf("LocationsByTag() should return nothing for an unused tag")
+	}
+
+	if err := manager.RemoveTags("Tokyo", "offices"); err != nil {
+		t.Fatalf("RemoveTags() failed: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Locations[0].Tags, []string{"travel"}) {
+		t.Errorf("RemoveTags() did not remove the tag: %v", cfg.Locations[0].Tags)
+	}
+	if err := manager.RemoveTags("Tokyo", "offices"); err == nil {
+		t.Errorf("RemoveTags() should fail for a tag the location does not have")
+	}
+	if err := manager.AddTags("Paris", "travel"); err == nil {
+		t.Errorf("AddTags() should fail for an unknown location")
+	}
+}
@@ -0,0 +1,119 @@
+package location
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather"
+)
+
+// HereEnvVar names the environment variable holding the current position as "lat,lon"
+const HereEnvVar = "WEATHER_CLI_HERE"
+
+// GeoIPURLEnvVar names the environment variable that overrides the IP-geolocation endpoint
+const GeoIPURLEnvVar = "WEATHER_CLI_GEOIP_URL"
+
+// GeoIPURL is the IP-geolocation endpoint used when no position is set in the environment.
+// It must return a JSON object with "lat"/"lon" or "latitude"/"longitude" fields.
+var GeoIPURL = "https://ipapi.co/json/"
+
+// geoIPResponse covers the field names used by common IP-geolocation providers
+type geoIPResponse struct {
+	Status    string   `json:"status"`
+	Message   string   `json:"message"`
+	Error     bool     `json:"error"`
+	Reason    string   `json:"reason"`
+	City      string   `json:"city"`
+	Lat       *float64 `json:"lat"`
+	Lon       *float64 `json:"lon"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// Here returns the current position, read from the environment or looked up by IP address
+func Here() (*config.Location, error) {
+	if value := os.Getenv(HereEnvVar); value != "" {
+		lat, lon, err := parseLatLon(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value '%s': %w", HereEnvVar, value, err)
+		}
+		lat, lon, err = NormalizeCoordinates(lat, lon)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value '%s': %w", HereEnvVar, value, err)
+		}
+		return &config.Location{Name: "here", Latitude: lat, Longitude: lon}, nil
+	}
+
+	url := GeoIPURL
+	if override := os.Getenv(GeoIPURLEnvVar); override != "" {
+		url = override
+	}
+	return lookupGeoIP(url)
+}
+
+// lookupGeoIP asks an IP-geolocation provider for the position of this machine
+func lookupGeoIP(url string) (*config.Location, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, weather.RequestError("IP-geolocation provider", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, weather.ResponseError("IP-geolocation provider", resp)
+	}
+
+	var geo geoIPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geo); err != nil {
+		return nil, fmt.Errorf("error unmarshaling IP-geolocation response: %w", err)
+	}
+	if geo.Status == "fail" {
+		return nil, fmt.Errorf("IP-geolocation failed: %s", geo.Message)
+	}
+	if geo.Error {
+		return nil, fmt.Errorf("IP-geolocation failed: %s", geo.Reason)
+	}
+
+	lat, lon := geo.Lat, geo.Lon
+	if lat == nil || lon == nil {
+		lat, lon = geo.Latitude, geo.Longitude
+	}
+	if lat == nil || lon == nil {
+		return nil, fmt.Errorf("IP-geolocation response has no coordinates")
+	}
+	latitude, longitude, err := NormalizeCoordinates(*lat, *lon)
+	if err != nil {
+		return nil, fmt.Errorf("IP-geolocation response has %w", err)
+	}
+
+	name := "here"
+	if geo.City != "" {
+		name = fmt.Sprintf("here (%s)", geo.City)
+	}
+	return &config.Location{Name: name, Latitude: latitude, Longitude: longitude}, nil
+}
+
+// parseLatLon parses a "lat,lon" pair
+func parseLatLon(value string) (float64, float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected <latitude>,<longitude>")
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid latitude")
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid longitude")
+	}
+	return lat, lon, nil
+}
@@ -0,0 +1,117 @@
+package location
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHereFromEnvironment(t *testing.T) {
+	t.Setenv(HereEnvVar, "35.6895, 139.6917")
+
+	loc, err := Here()
+	if err != nil {
+		t.Fatalf("Here() failed: %v", err)
+	}
+	if loc.Latitude != 35.6895 || loc.Longitude != 139.6917 {
+		t.Errorf("Here() = %+v, want 35.6895,139.6917", loc)
+	}
+
+	for _, value := range []string{"tokyo", "NaN,999", "91,0", "0,Inf"} {
+		t.Setenv(HereEnvVar, value)
+		if _, err := Here(); err == nil {
+			t.Errorf("Here() should fail for the invalid %s value %q", HereEnvVar, value)
+		}
+	}
+}
+
+func TestHereFromGeoIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		status   int
+		wantName string
+		wantLat  float64
+		wantLon  float64
+		wantErr  bool
+	}{
+		{
+			name:     "lat/lon fields",
+			response: `{"status": "success", "city": "Tokyo", "lat": 35.6895, "lon": 139.6917}`,
+			status:   http.StatusOK,
+			wantName: "here (Tokyo)",
+			wantLat:  35.6895,
+			wantLon:  139.6917,
+		},
+		{
+			name:     "latitude/longitude fields",
+			response: `{"latitude": 52.52, "longitude": 13.405}`,
+			status:   http.StatusOK,
+			wantName: "here",
+			wantLat:  52.52,
+			wantLon:  13.405,
+		},
+		{
+			name:     "Provider failure",
+			response: `{"status": "fail", "message": "private range"}`,
+			status:   http.StatusOK,
+			wantErr:  true,
+		},
+		{
+			name:     "Provider error",
+			response: `{"error": true, "reason": "RateLimited"}`,
+			status:   http.StatusOK,
+			wantErr:  true,
+		},
+		{
+			name:     "Coordinates out of range",
+			response: `{"lat": 999, "lon": 0}`,
+			status:   http.StatusOK,
+			wantErr:  true,
+		},
+		{
+			name:     "Longitude wrapped",
+			response: `{"lat": 35.6895, "lon": -220}`,
+			status:   http.StatusOK,
+			wantName: "here",
+			wantLat:  35.6895,
+			wantLon:  140,
+		},
+		{
+			name:     "Missing coordinates",
+			response: `{"city": "Nowhere"}`,
+			status:   http.StatusOK,
+			wantErr:  true,
+		},
+		{
+			name:     "Server error",
+			response: `{}`,
+			status:   http.StatusInternalServerError,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			t.Setenv(HereEnvVar, "")
+			t.Setenv(GeoIPURLEnvVar, server.URL)
+
+			loc, err := Here()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Here() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if loc.Name != tt.wantName || loc.Latitude != tt.wantLat || loc.Longitude != tt.wantLon {
+				t.Errorf("Here() = %+v, want %s at %v,%v", loc, tt.wantName, tt.wantLat, tt.wantLon)
+			}
+		})
+	}
+}
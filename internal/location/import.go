@@ -0,0 +1,412 @@
+package location
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"weather-cli/internal/config"
+)
+
+// Conflict policies for imported locations whose name is already saved
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// ImportOptions controls how imported locations are combined with the saved ones
+type ImportOptions struct {
+	// Replace discards the saved locations instead of merging into them
+	Replace bool
+	// OnConflict is one of ConflictSkip, ConflictOverwrite or ConflictRename
+	OnConflict string
+	// DryRun reports what would change without saving
+	DryRun bool
+}
+
+// ImportReport describes the outcome of an import
+type ImportReport struct {
+	Added       []string
+	Overwritten []string
+	Renamed     []Change
+	Conflicts   []string
+	Invalid     []string
+}
+
+// FormatFromPath guesses the import format from a file extension
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".geojson", ".json":
+		return FormatGeoJSON, nil
+	case ".gpx":
+		return FormatGPX, nil
+	case ".kml":
+		return FormatKML, nil
+	case ".csv":
+		return FormatCSV, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of '%s'. Use --format geojson|gpx|kml|csv", path)
+}
+
+// ParseLocations reads locations in the given format. Entries that cannot be used are
+// skipped and described in the returned list of invalid rows.
+func ParseLocations(r io.Reader, format string) ([]config.Location, []string, error) {
+	switch format {
+	case FormatGeoJSON:
+		return parseGeoJSON(r)
+	case FormatGPX:
+		return parseGPX(r)
+	case FormatKML:
+		return parseKML(r)
+	case FormatCSV:
+		return parseCSV(r)
+	default:
+		return nil, nil, fmt.Errorf("unsupported format '%s'. Use geojson, gpx, kml or csv", format)
+	}
+}
+
+// locationParser collects valid locations and descriptions of invalid rows
+type locationParser struct {
+	locations []config.Location
+	invalid   []string
+}
+
+// add validates a parsed entry and records it as a location or an invalid row
+func (p *locationParser) add(row string, loc config.Location, err error) {
+	loc.Name = strings.TrimSpace(loc.Name)
+	if err == nil && loc.Name == "" {
+		err = errors.New("missing name")
+	}
+	if err == nil {
+		loc.Latitude, loc.Longitude, err = NormalizeCoordinates(loc.Latitude, loc.Longitude)
+	}
+	if err != nil {
+		p.invalid = append(p.invalid, fmt.Sprintf("%s: %v", row, err))
+		return
+	}
+	p.locations = append(p.locations, loc)
+}
+
+func parseGeoJSON(r io.Reader) ([]config.Location, []string, error) {
+	var doc geoJSONFeatureCollection
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("error unmarshaling GeoJSON: %w", err)
+	}
+	if doc.Type != "FeatureCollection" {
+		return nil, nil, errors.New("GeoJSON document must be a FeatureCollection")
+	}
+
+	p := &locationParser{}
+	for i, feature := range doc.Features {
+		row := fmt.Sprintf("feature %d", i+1)
+		loc := config.Location{
+			Name:     feature.Properties.Name,
+			Aliases:  feature.Properties.Aliases,
+			Tags:     feature.Properties.Tags,
+			Timezone: feature.Properties.Timezone,
+		}
+
+		var err error
+		var position []float64
+		switch {
+		case feature.Geometry == nil || feature.Geometry.Type != "Point":
+			err = errors.New("only Point geometries are supported")
+		case json.Unmarshal(feature.Geometry.Coordinates, &position) != nil || len(position) < 2:
+			err = errors.New("invalid Point coordinates")
+		default:
+			loc.Longitude, loc.Latitude = position[0], position[1]
+		}
+		p.add(row, loc, err)
+	}
+	return p.locations, p.invalid, nil
+}
+
+func parseGPX(r io.Reader) ([]config.Location, []string, error) {
+	p := &locationParser{}
+	count := 0
+	err := decodeElements(r, "wpt", func(decoder *xml.Decoder, start xml.StartElement) error {
+		var wpt gpxWaypoint
+		if err := decoder.DecodeElement(&wpt, &start); err != nil {
+			return err
+		}
+		count++
+
+		loc := config.Location{Name: wpt.Name, Tags: splitList(wpt.Type), Timezone: strings.TrimSpace(wpt.Cmt)}
+		if aliases, ok := strings.CutPrefix(wpt.Desc, "aliases: "); ok {
+			loc.Aliases = splitList(aliases)
+		}
+		var err error
+		if loc.Latitude, err = strconv.ParseFloat(wpt.Lat, 64); err != nil {
+			err = errors.New("invalid latitude")
+		} else if loc.Longitude, err = strconv.ParseFloat(wpt.Lon, 64); err != nil {
+			err = errors.New("invalid longitude")
+		}
+		p.add(fmt.Sprintf("waypoint %d", count), loc, err)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading GPX: %w", err)
+	}
+	return p.locations, p.invalid, nil
+}
+
+func parseKML(r io.Reader) ([]config.Location, []string, error) {
+	p := &locationParser{}
+	count := 0
+	err := decodeElements(r, "Placemark", func(decoder *xml.Decoder, start xml.StartElement) error {
+		var placemark kmlPlacemark
+		if err := decoder.DecodeElement(&placemark, &start); err != nil {
+			return err
+		}
+		count++
+
+		loc := config.Location{Name: placemark.Name}
+		if placemark.ExtendedData != nil {
+			for _, data := range placemark.ExtendedData.Data {
+				switch data.Name {
+				case "aliases":
+					loc.Aliases = splitList(data.Value)
+				case "tags":
+					loc.Tags = splitList(data.Value)
+				case "timezone":
+					loc.Timezone = strings.TrimSpace(data.Value)
+				}
+			}
+		}
+
+		var err error
+		if placemark.Point == nil {
+			err = errors.New("only Point placemarks are supported")
+		} else {
+			// Coordinates are longitude,latitude[,altitude]
+			parts := strings.Split(strings.TrimSpace(placemark.Point.Coordinates), ",")
+			if len(parts) < 2 {
+				err = errors.New("invalid Point coordinates")
+			} else if loc.Longitude, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64); err != nil {
+				err = errors.New("invalid longitude")
+			} else if loc.Latitude, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64); err != nil {
+				err = errors.New("invalid latitude")
+			}
+		}
+		p.add(fmt.Sprintf("placemark %d", count), loc, err)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading KML: %w", err)
+	}
+	return p.locations, p.invalid, nil
+}
+
+func parseCSV(r io.Reader) ([]config.Location, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "name":
+			columns["name"] = i
+		case "latitude", "lat":
+			columns["latitude"] = i
+		case "longitude", "lon", "lng":
+			columns["longitude"] = i
+		case "aliases":
+			columns["aliases"] = i
+		case "tags":
+			columns["tags"] = i
+		case "timezone", "tz":
+			columns["timezone"] = i
+		}
+	}
+	for _, required := range []string{"name", "latitude", "longitude"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV header is missing the '%s' column", required)
+		}
+	}
+
+	p := &locationParser{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		row := fmt.Sprintf("row %d", line)
+		if err != nil {
+			p.invalid = append(p.invalid, fmt.Sprintf("%s: %v", row, err))
+			continue
+		}
+
+		field := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		loc := config.Location{
+			Name:     field("name"),
+			Aliases:  splitList(field("aliases")),
+			Tags:     splitList(field("tags")),
+			Timezone: field("timezone"),
+		}
+		if loc.Latitude, err = ParseLatitude(field("latitude")); err == nil {
+			loc.Longitude, err = ParseLongitude(field("longitude"))
+		}
+		p.add(row, loc, err)
+	}
+	return p.locations, p.invalid, nil
+}
+
+// decodeElements calls fn for every element with the given local name, at any depth
+func decodeElements(r io.Reader, name string, fn func(*xml.Decoder, xml.StartElement) error) error {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == name {
+			if err := fn(decoder, start); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// splitList splits a ';'-separated list, dropping empty entries
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// ImportLocations merges or replaces the saved locations with imported ones.
+// Locations whose name is already saved are handled according to opts.OnConflict.
+func (m *Manager) ImportLocations(locations []config.Location, opts ImportOptions) (*ImportReport, error) {
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = ConflictSkip
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("invalid conflict policy '%s'. Use skip, overwrite or rename", opts.OnConflict)
+	}
+
+	if opts.DryRun {
+		_, report := mergeLocations(m.cfg.Locations, locations, opts)
+		return report, nil
+	}
+
+	var report *ImportReport
+	err := config.Update(m.cfg, func() error {
+		var result []config.Location
+		result, report = mergeLocations(m.cfg.Locations, locations, opts)
+		m.cfg.Locations = result
+		if m.cfg.DefaultLocation != "" && findLocation(result, m.cfg.DefaultLocation) < 0 {
+			m.cfg.SetDefaultLocation("")
+		}
+		return nil
+	})
+	return report, err
+}
+
+// mergeLocations combines saved and imported locations according to opts
+func mergeLocations(saved, locations []config.Location, opts ImportOptions) ([]config.Location, *ImportReport) {
+	var result []config.Location
+	if !opts.Replace {
+		result = slices.Clone(saved)
+	}
+	imported := make(map[int]bool)
+	report := &ImportReport{}
+
+	for _, loc := range locations {
+		if loc.Timezone != "" {
+			if err := checkTimezone(loc.Timezone); err != nil {
+				report.Invalid = append(report.Invalid, fmt.Sprintf("%s: %v", loc.Name, err))
+				continue
+			}
+		}
+		loc.Tags = normalizeTags(loc.Tags)
+
+		i := findLocation(result, loc.Name)
+		// Locations from the same file always conflict as duplicates
+		if i >= 0 && imported[i] {
+			report.Conflicts = append(report.Conflicts, fmt.Sprintf("%s: duplicate name in import", loc.Name))
+			continue
+		}
+
+		if i >= 0 {
+			switch opts.OnConflict {
+			case ConflictSkip:
+				report.Conflicts = append(report.Conflicts, fmt.Sprintf("%s: already saved as '%s'", loc.Name, result[i].Name))
+				continue
+			case ConflictRename:
+				newName := uniqueName(result, loc.Name)
+				report.Renamed = append(report.Renamed, Change{Field: "name", Old: loc.Name, New: newName})
+				loc.Name = newName
+				i = -1
+			}
+		}
+
+		if err := checkAliases(result, loc, i); err != nil {
+			report.Invalid = append(report.Invalid, fmt.Sprintf("%s: %v", loc.Name, err))
+			continue
+		}
+
+		if i >= 0 {
+			report.Overwritten = append(report.Overwritten, loc.Name)
+			result[i] = loc
+			imported[i] = true
+		} else {
+			report.Added = append(report.Added, loc.Name)
+			result = append(result, loc)
+			imported[len(result)-1] = true
+		}
+	}
+
+	return result, report
+}
+
+// checkAliases verifies that none of a location's aliases are used by another location;
+// self is the index of the location being replaced, or -1
+func checkAliases(locations []config.Location, loc config.Location, self int) error {
+	for k, alias := range loc.Aliases {
+		if j := findLocation(locations, alias); j >= 0 && j != self {
+			return fmt.Errorf("alias '%s' is already used by location '%s'", alias, locations[j].Name)
+		}
+		if config.NormalizeName(alias) == config.NormalizeName(loc.Name) || indexFold(loc.Aliases[:k], alias) >= 0 {
+			return fmt.Errorf("alias '%s' is given more than once", alias)
+		}
+	}
+	return nil
+}
+
+// uniqueName appends a counter to name until it matches no saved location
+func uniqueName(locations []config.Location, name string) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		if findLocation(locations, candidate) < 0 {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package location
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"weather-cli/internal/config"
+)
+
+func TestParseLocationsRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatGeoJSON, FormatGPX, FormatKML, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportLocations(&buf, exportFixture(), format); err != nil {
+				t.Fatalf("ExportLocations() error = %v", err)
+			}
+
+			locations, invalid, err := ParseLocations(&buf, format)
+			if err != nil {
+				t.Fatalf("ParseLocations() error = %v", err)
+			}
+			if len(invalid) != 0 {
+				t.Errorf("ParseLocations() invalid = %v", invalid)
+			}
+			if !reflect.DeepEqual(locations, exportFixture()) {
+				t.Errorf("ParseLocations() = %+v, want %+v", locations, exportFixture())
+			}
+		})
+	}
+}
+
+func TestParseLocationsInvalidRows(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		valid   int
+		invalid []string
+	}{
+		{
+			name:   "csv",
+			format: FormatCSV,
+			input: "Name,Lat,Lng\n" +
+				"Tokyo,35.6895,139.6917\n" +
+				",10,10\n" +
+				"Nowhere,95,0\n" +
+				"Oslo,north,10\n",
+			valid:   1,
+			invalid: []string{"row 3: missing name", "row 4: invalid latitude 95", "row 5: invalid latitude"},
+		},
+		{
+			name:   "geojson",
+			format: FormatGeoJSON,
+			input: `{"type":"FeatureCollection","features":[
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[139.6917,35.6895]},"properties":{"name":"Tokyo"}},
+				{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]},"properties":{"name":"Route"}},
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[0]},"properties":{"name":"Short"}}]}`,
+			valid:   1,
+			invalid: []string{"feature 2: only Point", "feature 3: invalid Point coordinates"},
+		},
+		{
+			name:   "gpx",
+			format: FormatGPX,
+			input: `<gpx version="1.1"><wpt lat="35.6895" lon="139.6917"><name>Tokyo</name></wpt>` +
+				`<wpt lat="abc" lon="0"><name>Broken</name></wpt></gpx>`,
+			valid:   1,
+			invalid: []string{"waypoint 2: invalid latitude"},
+		},
+		{
+			name:   "kml",
+			format: FormatKML,
+			input: `<kml><Document><Folder><Placemark><name>Tokyo</name><Point><coordinates> 139.6917,35.6895,40 </coordinates></Point></Placemark></Folder>` +
+				`<Placemark><name>Area</name><Polygon/></Placemark></Document></kml>`,
+			valid:   1,
+			invalid: []string{"placemark 2: only Point"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			locations, invalid, err := ParseLocations(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("ParseLocations() error = %v", err)
+			}
+			if len(locations) != tt.valid {
+				t.Errorf("ParseLocations() returned %d locations, want %d", len(locations), tt.valid)
+			}
+			if len(invalid) != len(tt.invalid) {
+				t.Fatalf("ParseLocations() invalid = %v, want %v", invalid, tt.invalid)
+			}
+			for i, want := range tt.invalid {
+				if !strings.Contains(invalid[i], want) {
+					t.Errorf("invalid[%d] = %q, want it to contain %q", i, invalid[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLocationsErrors(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+	}{
+		{FormatCSV, "name,latitude\nTokyo,35\n"},
+		{FormatGeoJSON, `{"type":"Feature"}`},
+		{FormatGeoJSON, `not json`},
+		{FormatGPX, `<gpx><wpt`},
+		{"shapefile", ""},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := ParseLocations(strings.NewReader(tt.input), tt.format); err == nil {
+			t.Errorf("ParseLocations(%q, %s) should fail", tt.input, tt.format)
+		}
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"places.geojson": FormatGeoJSON,
+		"places.JSON":    FormatGeoJSON,
+		"track.gpx":      FormatGPX,
+		"map.kml":        FormatKML,
+		"list.csv":       FormatCSV,
+	}
+	for path, want := range tests {
+		if got, err := FormatFromPath(path); err != nil || got != want {
+			t.Errorf("FormatFromPath(%q) = %q, %v, want %q", path, got, err, want)
+		}
+	}
+	if _, err := FormatFromPath("places.txt"); err == nil {
+		t.Error("FormatFromPath() should fail for an unknown extension")
+	}
+}
+
+func TestImportLocations(t *testing.T) {
+	imported := []config.Location{
+		{Name: "tokyo", Latitude: 35.7, Longitude: 139.7},
+		{Name: "Osaka", Latitude: 34.6937, Longitude: 135.5023},
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		manager := NewManager(mockConfig())
+		report, err := manager.ImportLocations(imported, ImportOptions{})
+		if err != nil {
+			t.Fatalf("ImportLocations() error = %v", err)
+		}
+		if !reflect.DeepEqual(report.Added, []string{"Osaka"}) || len(report.Conflicts) != 1 {
+			t.Errorf("ImportLocations() report = %+v", report)
+		}
+		if manager.cfg.Locations[0].Latitude != 35.6895 {
+			t.Error("ImportLocations() should keep the saved location on conflict")
+		}
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		manager := NewManager(mockConfig())
+		report, err := manager.ImportLocations(imported, ImportOptions{OnConflict: ConflictOverwrite})
+		if err != nil {
+			t.Fatalf("ImportLocations() error = %v", err)
+		}
+		if !reflect.DeepEqual(report.Overwritten, []string{"tokyo"}) || len(manager.cfg.Locations) != 2 {
+			t.Errorf("ImportLocations() report = %+v, locations = %+v", report, manager.cfg.Locations)
+		}
+		if manager.cfg.Locations[0].Latitude != 35.7 {
+			t.Error("ImportLocations() should overwrite the saved location")
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		manager := NewManager(mockConfig())
+		report, err := manager.ImportLocations(imported, ImportOptions{OnConflict: ConflictRename})
+		if err != nil {
+			t.Fatalf("ImportLocations() error = %v", err)
+		}
+		if len(report.Renamed) != 1 || report.Renamed[0].New != "tokyo (2)" {
+			t.Errorf("ImportLocations() renamed = %+v", report.Renamed)
+		}
+		if len(manager.cfg.Locations) != 3 {
+			t.Errorf("ImportLocations() locations = %+v", manager.cfg.Locations)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		cfg := mockConfig()
+		cfg.DefaultLocation = "Tokyo"
+		manager := NewManager(cfg)
+		if _, err := manager.ImportLocations(imported[1:], ImportOptions{Replace: true}); err != nil {
+			t.Fatalf("ImportLocations() error = %v", err)
+		}
+		if len(cfg.Locations) != 1 || cfg.Locations[0].Name != "Osaka" {
+			t.Errorf("ImportLocations() locations = %+v", cfg.Locations)
+		}
+		if cfg.DefaultLocation != "" {
+			t.Error("ImportLocations() should clear a default location that was replaced")
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		manager := NewManager(mockConfig())
+		report, err := manager.ImportLocations(imported, ImportOptions{DryRun: true, OnConflict: ConflictOverwrite})
+		if err != nil {
+			t.Fatalf("ImportLocations() error = %v", err)
+		}
+		if len(report.Added) != 1 || len(report.Overwritten) != 1 {
+			t.Errorf("ImportLocations() report = %+v", report)
+		}
+		if len(manager.cfg.Locations) != 1 || manager.cfg.Locations[0].Latitude != 35.6895 {
+			t.Error("ImportLocations() dry run should not change the locations")
+		}
+	})
+
+	t.Run("alias clash and duplicates", func(t *testing.T) {
+		manager := NewManager(mockConfig())
+		report, err := manager.ImportLocations([]config.Location{
+			{Name: "Kyoto", Latitude: 35.0116, Longitude: 135.7681, Aliases: []string{"TOKYO"}},
+			{Name: "Nara", Latitude: 34.6851, Longitude: 135.8048},
+			{Name: "nara", Latitude: 34.6851, Longitude: 135.8048},
+		}, ImportOptions{})
+		if err != nil {
+			t.Fatalf("ImportLocations() error = %v", err)
+		}
+		if len(report.Invalid) != 1 || len(report.Conflicts) != 1 || len(report.Added) != 1 {
+			t.Errorf("ImportLocations() report = %+v", report)
+		}
+	})
+
+	t.Run("timezones and tags", func(t *testing.T) {
+		manager := NewManager(mockConfig())
+		report, err := manager.ImportLocations([]config.Location{
+			{Name: "Kyoto", Latitude: 35.0116, Longitude: 135.7681, Timezone: "Asia/Kyoto"},
+			{Name: "Nara", Latitude: 34.6851, Longitude: 135.8048, Timezone: "Asia/Tokyo", Tags: []string{" Kansai ", "kansai", "Café"}},
+		}, ImportOptions{})
+		if err != nil {
+			t.Fatalf("ImportLocations() error = %v", err)
+		}
+		if len(report.Invalid) != 1 || !strings.Contains(report.Invalid[0], "Kyoto: invalid timezone 'Asia/Kyoto'") {
+			t.Errorf("ImportLocations() invalid = %v", report.Invalid)
+		}
+		if !reflect.DeepEqual(report.Added, []string{"Nara"}) {
+			t.Errorf("ImportLocations() added = %v", report.Added)
+		}
+		if tags := manager.cfg.Locations[1].Tags; !reflect.DeepEqual(tags, []string{"kansai", "cafe"}) {
+			t.Errorf("Imported tags = %v, want them normalized like AddTags", tags)
+		}
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		if _, err := NewManager(mockConfig()).ImportLocations(imported, ImportOptions{OnConflict: "merge"}); err == nil {
+			t.Error("ImportLocations() should fail for an unknown conflict policy")
+		}
+	})
+}
@@ -6,6 +6,28 @@ import (
 	"weather-cli/internal/config"
 )
 
+// ErrLocationNotFound is returned when a name matches no saved location
+var ErrLocationNotFound = errors.New("location not found")
+
+// ErrNameTaken is matched by errors about a name or alias that is already used
+// by a saved location
+var ErrNameTaken = errors.New("name already used by a saved location")
+
+// nameTakenError reports a name or alias that is already used
+type nameTakenError struct {
+	msg string
+}
+
+func (e *nameTakenError) Error() string { return e.msg }
+
+// Is makes every nameTakenError match ErrNameTaken
+func (e *nameTakenError) Is(target error) bool { return target == ErrNameTaken }
+
+// nameTaken formats a nameTakenError
+func nameTaken(format string, args ...any) error {
+	return &nameTakenError{msg: fmt.Sprintf(format, args...)}
+}
+
 // Manager handles location-related operations
 type Manager struct {
 	cfg *config.Config
@@ -16,35 +38,70 @@ func NewManager(cfg *config.Config) *Manager {
 	return &Manager{cfg: cfg}
 }
 
-// AddLocation adds a new location to the configuration
+// AddLocation adds a new location to the configuration.
+// Coordinates are validated and the longitude is wrapped into [-180, 180].
 func (m *Manager) AddLocation(name string, lat, lon float64) error {
-	for _, loc := range m.cfg.Locations {
-		if loc.Name == name {
-			return fmt.Errorf("location with name '%s' already exists", name)
-		}
+	lat, lon, err := NormalizeCoordinates(lat, lon)
+	if err != nil {
+		return err
+	}
+
+	return config.Update(m.cfg, func() error {
+		return m.addLocation(name, lat, lon)
+	})
+}
+
+// addLocation adds a location with normalized coordinates to the loaded config
+func (m *Manager) addLocation(name string, lat, lon float64) error {
+	// Names that only differ in case or accents would be ambiguous to look up
+	if i := findLocation(m.cfg.Locations, name); i >= 0 {
+		return nameTaken("location with name '%s' already exists", m.cfg.Locations[i].Name)
 	}
 
 	m.cfg.AddLocation(name, lat, lon)
-	return config.SaveConfig(m.cfg)
+	return nil
 }
 
 // RemoveLocation removes a location from the configuration
 func (m *Manager) RemoveLocation(name string) error {
-	err := m.cfg.RemoveLocation(name)
+	return config.Update(m.cfg, func() error {
+		i := findLocation(m.cfg.Locations, name)
+		if i < 0 {
+			return ErrLocationNotFound
+		}
+		return m.cfg.RemoveLocation(m.cfg.Locations[i].Name)
+	})
+}
+
+// GetLocation retrieves a location by name, ignoring case and accents.
+// A unique prefix of a saved name is also accepted.
+func (m *Manager) GetLocation(name string) (*config.Location, error) {
+	i, err := matchLocation(m.cfg.Locations, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return config.SaveConfig(m.cfg)
+	loc := m.cfg.Locations[i]
+	return &loc, nil
 }
 
-// GetLocation retrieves a location by name
-func (m *Manager) GetLocation(name string) (*config.Location, error) {
-	for _, loc := range m.cfg.Locations {
-		if loc.Name == name {
-			return &loc, nil
+// SetDefaultLocation makes a saved location the default for plain "weather"
+func (m *Manager) SetDefaultLocation(name string) error {
+	return config.Update(m.cfg, func() error {
+		loc, err := m.GetLocation(name)
+		if err != nil {
+			return err
 		}
+		m.cfg.SetDefaultLocation(loc.Name)
+		return nil
+	})
+}
+
+// GetDefaultLocation retrieves the default location
+func (m *Manager) GetDefaultLocation() (*config.Location, error) {
+	if m.cfg.DefaultLocation == "" {
+		return nil, errors.New("no default location set")
 	}
-	return nil, errors.New("location not found")
+	return m.GetLocation(m.cfg.DefaultLocation)
 }
 
 // ListLocations returns all saved locations
@@ -54,12 +111,13 @@ func (m *Manager) ListLocations() []config.Location {
 
 // UpdateLocation updates an existing location
 func (m *Manager) UpdateLocation(name string, lat, lon float64) error {
-	for i, loc := range m.cfg.Locations {
-		if loc.Name == name {
-			m.cfg.Locations[i].Latitude = lat
-			m.cfg.Locations[i].Longitude = lon
-			return config.SaveConfig(m.cfg)
+	return config.Update(m.cfg, func() error {
+		i := findLocation(m.cfg.Locations, name)
+		if i < 0 {
+			return ErrLocationNotFound
 		}
-	}
-	return errors.New("location not found")
+		m.cfg.Locations[i].Latitude = lat
+		m.cfg.Locations[i].Longitude = lon
+		return nil
+	})
 }
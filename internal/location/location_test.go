@@ -1,7 +1,9 @@
 package location
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 	"weather-cli/internal/config"
 )
@@ -38,8 +40,26 @@ func TestAddLocation(t *testing.T) {
 
 	// Test adding a duplicate location
 	err = manager.AddLocation("Tokyo", 35.6895, 139.6917)
+	if !errors.Is(err, ErrNameTaken) {
+		t.Errorf("AddLocation() of a duplicate location error = %v, want ErrNameTaken", err)
+	}
+
+	// Test adding a location with invalid coordinates
+	err = manager.AddLocation("Nowhere", 200, 0)
+	if err == nil {
+		t.Errorf("AddLocation() should fail for an out-of-range latitude")
+	}
+
+	// Test that the longitude is wrapped
+	err = manager.AddLocation("Wrapped", 0, 190)
+	if err != nil || manager.cfg.Locations[len(manager.cfg.Locations)-1].Longitude != -170 {
+		t.Errorf("AddLocation() should wrap the longitude into [-180, 180]")
+	}
+
+	// Test adding a duplicate that only differs in case and accents
+	err = manager.AddLocation("TÖKYO", 35.6895, 139.6917)
 	if err == nil {
-		t.Errorf("AddLocation() should fail when adding a duplicate location")
+		t.Errorf("AddLocation() should fail when adding a name that differs only in case or accents")
 	}
 }
 
@@ -81,6 +101,18 @@ func TestGetLocation(t *testing.T) {
 	if err == nil {
 		t.Errorf("GetLocation() should fail when getting a non-existent location")
 	}
+
+	// Test getting a location with different case
+	loc, err = manager.GetLocation("tokyo")
+	if err != nil || loc.Name != "Tokyo" {
+		t.Errorf("GetLocation() should match names case-insensitively")
+	}
+
+	// Test that a typo suggests the closest name
+	_, err = manager.GetLocation("Tokio")
+	if err == nil || !strings.Contains(err.Error(), "did you mean 'Tokyo'?") {
+		t.Errorf("GetLocation() should suggest the closest name, got %v", err)
+	}
 }
 
 func TestListLocations(t *testing.T) {
@@ -113,3 +145,34 @@ func TestUpdateLocation(t *testing.T) {
 		t.Errorf("UpdateLocation() should fail when updating a non-existent location")
 	}
 }
+
+func TestDefaultLocation(t *testing.T) {
+	cfg := mockConfig()
+	manager := NewManager(cfg)
+
+	if _, err := manager.GetDefaultLocation(); err == nil {
+		t.Errorf("GetDefaultLocation() should fail when no default is set")
+	}
+
+	if err := manager.SetDefaultLocation("Non-existent"); err == nil {
+		t.Errorf("SetDefaultLocation() should fail for a non-existent location")
+	}
+
+	if err := manager.SetDefaultLocation("Tokyo"); err != nil {
+		t.Fatalf("SetDefaultLocation() failed: %v", err)
+	}
+	loc, err := manager.GetDefaultLocation()
+	if err != nil {
+		t.Fatalf("GetDefaultLocation() failed: %v", err)
+	}
+	if loc.Name != "Tokyo" {
+		t.Errorf("GetDefaultLocation() returned %s, want Tokyo", loc.Name)
+	}
+
+	if err := manager.RemoveLocation("Tokyo"); err != nil {
+		t.Fatalf("RemoveLocation() failed: %v", err)
+	}
+	if cfg.DefaultLocation != "" {
+		t.Errorf("Removing the default location should clear it")
+	}
+}
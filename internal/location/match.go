@@ -0,0 +1,116 @@
+package location
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"weather-cli/internal/config"
+)
+
+// maxSuggestions is the number of saved names suggested when a lookup fails
+const maxSuggestions = 3
+
+// locationNames returns the name and aliases a location can be looked up by
+func locationNames(loc config.Location) []string {
+	return append([]string{loc.Name}, loc.Aliases...)
+}
+
+// findLocation returns the index of the saved location whose name or alias matches
+// name exactly after normalization, or -1
+func findLocation(locations []config.Location, name string) int {
+	key := config.NormalizeName(name)
+	for i, loc := range locations {
+		for _, candidate := range locationNames(loc) {
+			if config.NormalizeName(candidate) == key {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// matchLocation resolves a name or alias to a saved location. An exact match after
+// normalization wins; otherwise a unique prefix is accepted. When nothing matches, the error suggests
+// the closest saved names.
+func matchLocation(locations []config.Location, name string) (int, error) {
+	if i := findLocation(locations, name); i >= 0 {
+		return i, nil
+	}
+
+	key := config.NormalizeName(name)
+	var prefixed []int
+	for i, loc := range locations {
+		for _, candidate := range locationNames(loc) {
+			if key != "" && strings.HasPrefix(config.NormalizeName(candidate), key) {
+				prefixed = append(prefixed, i)
+				break
+			}
+		}
+	}
+	switch len(prefixed) {
+	case 1:
+		return prefixed[0], nil
+	case 0:
+	default:
+		names := make([]string, len(prefixed))
+		for j, i := range prefixed {
+			names[j] = "'" + locations[i].Name + "'"
+		}
+		return -1, fmt.Errorf("location '%s' is ambiguous: matches %s", name, strings.Join(names, ", "))
+	}
+
+	if suggestions := suggestNames(locations, key); len(suggestions) > 0 {
+		return -1, fmt.Errorf("%w: did you mean %s?", ErrLocationNotFound, strings.Join(suggestions, " or "))
+	}
+	return -1, ErrLocationNotFound
+}
+
+// suggestNames returns the quoted saved names closest to key by edit distance
+func suggestNames(locations []config.Location, key string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	// Allow roughly one typo per three characters, and at least two
+	threshold := max(2, len([]rune(key))/3)
+	var candidates []candidate
+	for _, loc := range locations {
+		for _, name := range locationNames(loc) {
+			if d := levenshtein(key, config.NormalizeName(name)); d <= threshold {
+				candidates = append(candidates, candidate{name, d})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+	var names []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		names = append(names, "'"+candidates[i].name+"'")
+	}
+	return names
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
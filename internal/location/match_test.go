@@ -0,0 +1,73 @@
+package location
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"weather-cli/internal/config"
+)
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"tokyo", "tokyo", 0},
+		{"tokyo", "tokio", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchLocation(t *testing.T) {
+	locations := []config.Location{
+		{Name: "tokyo"},
+		{Name: "Toronto"},
+		{Name: "Zürich"},
+		{Name: "New York"},
+		{Name: "Newark"},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		want      string
+		errSubstr string
+	}{
+		{"Case-insensitive", "Tokyo", "tokyo", ""},
+		{"Diacritic-insensitive", "zurich", "Zürich", ""},
+		{"Unique prefix", "tok", "tokyo", ""},
+		{"Exact match wins over prefix", "new york", "New York", ""},
+		{"Ambiguous prefix", "to", "", "ambiguous: matches 'tokyo', 'Toronto'"},
+		{"Typo suggestion", "tokio", "", "did you mean 'tokyo'?"},
+		{"No suggestion", "london", "", "location not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, err := matchLocation(locations, tt.query)
+			if tt.errSubstr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+					t.Errorf("matchLocation(%q) error = %v, want error containing %q", tt.query, err, tt.errSubstr)
+				}
+				if wantNotFound := !strings.Contains(tt.errSubstr, "ambiguous"); errors.Is(err, ErrLocationNotFound) != wantNotFound {
+					t.Errorf("errors.Is(%v, ErrLocationNotFound) = %v, want %v", err, !wantNotFound, wantNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("matchLocation(%q) failed: %v", tt.query, err)
+			}
+			if locations[i].Name != tt.want {
+				t.Errorf("matchLocation(%q) = %s, want %s", tt.query, locations[i].Name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpsPort is the port of SMTP over implicit TLS; other ports upgrade the
+// connection with STARTTLS when the server offers it
+const smtpsPort = 465
+
+// Email sends messages as plain-text mail through an SMTP server
+type Email struct {
+	Host string
+	Port int
+	// Username and Password log in with PLAIN authentication when Username is set
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Notify mails msg to every recipient, retrying when the server is unavailable
+// or replies with a temporary failure
+func (e *Email) Notify(ctx context.Context, msg Message) error {
+	return retry(ctx, func() error { return e.send(ctx, msg) })
+}
+
+// send delivers msg in one SMTP session
+func (e *Email) send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+	var conn net.Conn
+	var err error
+	if e.Port == smtpsPort {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: e.Host}}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return &DeliveryError{Target: "SMTP server " + addr, Err: err}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP server %s: %w", addr, err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: e.Host}); err != nil {
+			return fmt.Errorf("STARTTLS with %s failed: %w", addr, err)
+		}
+	}
+	if e.Username != "" {
+		// PLAIN authentication refuses to send the password unencrypted, except to localhost
+		if err := client.Auth(smtp.PlainAuth("", e.Username, e.Password, e.Host)); err != nil {
+			return fmt.Errorf("SMTP login to %s failed: %w", addr, err)
+		}
+	}
+
+	if err := client.Mail(e.From); err != nil {
+		return fmt.Errorf("SMTP server %s refused the sender: %w", addr, err)
+	}
+	for _, to := range e.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP server %s refused recipient %s: %w", addr, to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP server %s: %w", addr, err)
+	}
+	if _, err := w.Write(e.message(msg, time.Now())); err != nil {
+		return fmt.Errorf("failed to send the message to %s: %w", addr, err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server %s did not accept the message: %w", addr, err)
+	}
+	return client.Quit()
+}
+
+// message formats msg as a MIME mail with the title as the subject
+func (e *Email) message(msg Message, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,172 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is an SMTP server that records the sessions it receives. The first
+// failFirst MAIL commands are refused with a temporary failure.
+type fakeSMTP struct {
+	addr      string
+	failFirst int
+
+	mu       sync.Mutex
+	auth     []string
+	commands []string
+	messages []string
+}
+
+func startFakeSMTP(t *testing.T, failFirst int) *fakeSMTP {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeSMTP{addr: listener.Addr().String(), failFirst: failFirst}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 localhost ESMTP fake")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		switch verb {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			s.mu.Lock()
+			s.auth = append(s.auth, strings.TrimPrefix(line, "AUTH PLAIN "))
+			s.mu.Unlock()
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.mu.Lock()
+			fail := s.failFirst > 0
+			s.failFirst--
+			s.mu.Unlock()
+			if fail {
+				reply("451 4.3.0 Try again later")
+				continue
+			}
+			reply("250 OK")
+		case "RCPT", "RSET", "NOOP":
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestEmail(t *testing.T) {
+	server := startFakeSMTP(t, 0)
+	host, port, _ := net.SplitHostPort(server.addr)
+	var portNumber int
+	fmt.Sscan(port, &portNumber)
+
+	email := &Email{Host: host, Port: portNumber, Username: "bot", Password: "secret", From: "weather@example.com", To: []string{"team@example.com", "ops@example.com"}}
+	if err := email.Notify(context.Background(), Message{Title: "Weather for Zürich", Body: "Light rain\n.Dot line"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.auth) != 1 {
+		t.Fatalf("Expected one login, got %v", server.auth)
+	}
+	if credentials, _ := base64.StdEncoding.DecodeString(server.auth[0]); string(credentials) != "\x00bot\x00secret" {
+		t.Errorf("Login used credentials %q", credentials)
+	}
+	commands := strings.Join(server.commands, "\n")
+	for _, expected := range []string{"MAIL FROM:<weather@example.com>", "RCPT TO:<team@example.com>", "RCPT TO:<ops@example.com>"} {
+		if !strings.Contains(commands, expected) {
+			t.Errorf("Session didn't contain %q:\n%s", expected, commands)
+		}
+	}
+	if len(server.messages) != 1 {
+		t.Fatalf("Expected one message, got %d", len(server.messages))
+	}
+	for _, expected := range []string{
+		"To: team@example.com, ops@example.com\r\n",
+		"Subject: =?utf-8?q?Weather_for_Z=C3=BCrich?=\r\n",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"\r\n\r\nLight rain\r\n..Dot line\r\n",
+	} {
+		if !strings.Contains(server.messages[0], expected) {
+			t.Errorf("Message didn't contain %q:\n%s", expected, server.messages[0])
+		}
+	}
+}
+
+func TestEmailRetriesTemporaryFailure(t *testing.T) {
+	oldDelay := RetryDelay
+	RetryDelay = time.Millisecond
+	defer func() { RetryDelay = oldDelay }()
+
+	server := startFakeSMTP(t, 1)
+	host, port, _ := net.SplitHostPort(server.addr)
+	var portNumber int
+	fmt.Sscan(port, &portNumber)
+
+	email := &Email{Host: host, Port: portNumber, From: "weather@example.com", To: []string{"team@example.com"}}
+	if err := email.Notify(context.Background(), Message{Title: "Title", Body: "Body"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.messages) != 1 {
+		t.Errorf("Expected the message to be delivered on the second attempt, got %d messages", len(server.messages))
+	}
+}
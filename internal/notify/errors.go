@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DeliveryError is a message a target did not take: either it could not be
+// reached, with Err holding why, or it replied with a StatusCode outside 2xx
+type DeliveryError struct {
+	Target     string
+	StatusCode int
+	// RetryAfter is the wait the target asked for, zero when it did not ask
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *DeliveryError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("could not reach %s: %v", e.Target, e.Err)
+	}
+	msg := fmt.Sprintf("%s returned %d %s", e.Target, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.RetryAfter > 0 {
+		msg += fmt.Sprintf("; retry in %s", e.RetryAfter)
+	}
+	return msg
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether sending again may succeed: the target could not be
+// reached, asked to slow down or failed with a server error
+func (e *DeliveryError) Temporary() bool {
+	return e.StatusCode == 0 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// responseError describes a response from target with a status outside 2xx
+func responseError(target string, resp *http.Response) *DeliveryError {
+	return &DeliveryError{Target: target, StatusCode: resp.StatusCode, RetryAfter: retryAfter(resp.Header)}
+}
+
+// retryAfter parses the Retry-After header, either a number of seconds or an HTTP
+// date, returning zero when it is missing or already past
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && time.Until(at) > 0 {
+		return time.Until(at).Round(time.Second)
+	}
+	return 0
+}
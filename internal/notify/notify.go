@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"weather-cli/internal/config"
+	"weather-cli/internal/secret"
+)
+
+// Message is a notification with a one-line title and a longer body
+type Message struct {
+	Title string
+	Body  string
+}
+
+// Notifier delivers messages to one target
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Timeout bounds how long delivering one message may take, retries included
+var Timeout = 30 * time.Second
+
+// New returns the notifier for a target checked by config.CheckNotifyTarget
+func New(cfg *config.Config, target string) (Notifier, error) {
+	if err := config.CheckNotifyTarget(cfg, target); err != nil {
+		return nil, err
+	}
+	switch target {
+	case config.NotifyStdout:
+		return &Writer{W: os.Stdout}, nil
+	case config.NotifyDesktop:
+		return &Desktop{Command: "notify-send"}, nil
+	}
+
+	n, ok := cfg.Notifiers[target]
+	if !ok {
+		return &Webhook{URL: target}, nil
+	}
+	switch n.Type {
+	case "slack":
+		return &Webhook{URL: n.URL, Payload: SlackPayload}, nil
+	case "discord":
+		return &Webhook{URL: n.URL, Payload: DiscordPayload}, nil
+	case "email":
+		password := n.Password
+		if n.PasswordCmd != "" {
+			var err error
+			if password, err = secret.RunCommand(n.PasswordCmd); err != nil {
+				return nil, fmt.Errorf("failed to read the SMTP password from password_cmd: %w", err)
+			}
+		}
+		port := n.SMTPPort
+		if port == 0 {
+			port = config.DefaultSMTPPort
+		}
+		return &Email{Host: n.SMTPHost, Port: port, Username: n.Username, Password: password, From: n.From, To: n.To}, nil
+	}
+	return &Webhook{URL: n.URL}, nil
+}
+
+// Send delivers msg to every target, continuing past failures, and returns the
+// failures joined together
+func Send(ctx context.Context, cfg *config.Config, targets []string, msg Message) error {
+	var errs []error
+	for _, target := range targets {
+		notifier, err := New(cfg, target)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(ctx, Timeout)
+			err = notifier.Notify(ctx, msg)
+			cancel()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to notify %s: %w", RedactTarget(target), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Writer prints messages, one line for the title followed by the body
+type Writer struct {
+	W io.Writer
+}
+
+// Notify prints msg
+func (w *Writer) Notify(ctx context.Context, msg Message) error {
+	_, err := fmt.Fprintf(w.W, "%s\n%s\n", msg.Title, msg.Body)
+	return err
+}
+
+// Desktop shows messages as desktop notifications through notify-send from libnotify
+type Desktop struct {
+	Command string
+}
+
+// Notify shows msg as a desktop notification
+func (d *Desktop) Notify(ctx context.Context, msg Message) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, d.Command, "--app-name=weather-cli", msg.Title, msg.Body)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if stderr := strings.TrimSpace(stderr.String()); stderr != "" {
+			return fmt.Errorf("%s failed: %w: %s", d.Command, err, stderr)
+		}
+		return fmt.Errorf("%s failed: %w", d.Command, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"time"
+)
+
+// Attempts is how many times a message is sent before giving up
+var Attempts = 3
+
+// RetryDelay is the wait before the first retry, doubled before each one after it
+var RetryDelay = 2 * time.Second
+
+// maxRetryDelay caps a wait asked for by the receiver with Retry-After
+const maxRetryDelay = time.Minute
+
+// retry calls send until it succeeds, fails with an error that a retry cannot
+// fix, runs out of attempts or ctx is done
+func retry(ctx context.Context, send func() error) error {
+	delay := RetryDelay
+	for attempt := 1; ; attempt++ {
+		err := send()
+		if err == nil || attempt >= Attempts || !retryable(err) {
+			return err
+		}
+
+		wait := delay
+		var deliveryErr *DeliveryError
+		if errors.As(err, &deliveryErr) && deliveryErr.RetryAfter > 0 {
+			wait = min(deliveryErr.RetryAfter, maxRetryDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// retryable reports whether sending again may succeed: the receiver could not be
+// reached, asked to slow down, failed with a server error or, for SMTP, replied
+// with a temporary 4xx failure
+func retryable(err error) bool {
+	var deliveryErr *DeliveryError
+	if errors.As(err, &deliveryErr) {
+		return deliveryErr.Temporary()
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+	return false
+}
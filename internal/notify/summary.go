@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"weather-cli/internal/weather"
+)
+
+// DailySummary renders the rest of today's forecast for a location, with an
+// outlook for tomorrow, as a message. Temperatures are shown in unit.
+func DailySummary(name string, data *weather.WeatherData, unit string) (Message, error) {
+	days := weather.SummarizeDays(data)
+	if len(days) == 0 {
+		return Message{}, errors.New("the forecast has no entries to summarize")
+	}
+
+	today := days[0]
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s, %s\n", capitalize(today.Description), temperatureRange(today, unit))
+	fmt.Fprintf(&body, "Chance of precipitation: %.0f%%\n", today.MaxPop*100)
+	if today.Rain > 0 {
+		fmt.Fprintf(&body, "Rain: %.1f mm\n", today.Rain)
+	}
+	if today.Snow > 0 {
+		fmt.Fprintf(&body, "Snow: %.1f mm\n", today.Snow)
+	}
+	if len(days) > 1 {
+		tomorrow := days[1]
+		fmt.Fprintf(&body, "Tomorrow: %s, %s\n", tomorrow.Description, temperatureRange(tomorrow, unit))
+	}
+
+	return Message{
+		Title: fmt.Sprintf("Weather for %s on %s", name, today.Date.Format("Mon 2 Jan")),
+		Body:  strings.TrimSuffix(body.String(), "\n"),
+	}, nil
+}
+
+// temperatureRange formats the lowest and highest temperature of a day
+func temperatureRange(day weather.DailySummary, unit string) string {
+	low := weather.ConvertTemperature(day.TempMin, "C", unit)
+	high := weather.ConvertTemperature(day.TempMax, "C", unit)
+	return fmt.Sprintf("%.1f°%s to %.1f°%s", low, unit, high, unit)
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
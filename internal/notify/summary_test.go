@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"encoding/json"
+	"testing"
+	"weather-cli/internal/weather"
+)
+
+func TestDailySummary(t *testing.T) {
+	// Timestamps are 2024-07-01 00:00, 12:00, 21:00 and 2024-07-02 00:00 in UTC+9
+	var data weather.WeatherData
+	err := json.Unmarshal([]byte(`{
+		"city": {"name": "Tokyo", "country": "JP", "timezone": 32400},
+		"list": [
+			{"dt": 1719759600, "main": {"temp": 20.0}, "pop": 0.1, "weather": [{"id": 800, "description": "clear sky"}]},
+			{"dt": 1719802800, "main": {"temp": 28.5}, "pop": 0.6, "rain": {"3h": 1.5}, "weather": [{"id": 500, "description": "light rain"}]},
+			{"dt": 1719835200, "main": {"temp": 22.0}, "pop": 0.2, "rain": {"3h": 0.5}, "weather": [{"id": 500, "description": "light rain"}]},
+			{"dt": 1719846000, "main": {"temp": 19.0}, "pop": 0.0, "weather": [{"id": 801, "description": "few clouds"}]}
+		]
+	}`), &data)
+	if err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	msg, err := DailySummary("office", &data, "C")
+	if err != nil {
+		t.Fatalf("DailySummary() error = %v", err)
+	}
+	if msg.Title != "Weather for office on Mon 1 Jul" {
+		t.Errorf("Title = %q", msg.Title)
+	}
+	want := "Light rain, 20.0°C to 28.5°C\nChance of precipitation: 60%\nRain: 2.0 mm\nTomorrow: few clouds, 19.0°C to 19.0°C"
+	if msg.Body != want {
+		t.Errorf("Body = %q\nwant %q", msg.Body, want)
+	}
+
+	if _, err := DailySummary("office", &weather.WeatherData{}, "C"); err == nil {
+		t.Error("DailySummary() of an empty forecast should fail")
+	}
+}
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Webhook posts messages as JSON to a URL
+type Webhook struct {
+	URL string
+	// Payload builds the JSON body for a message; JSONPayload when nil
+	Payload func(msg Message) any
+}
+
+// JSONPayload is the generic webhook body, {"title": ..., "text": ...}
+func JSONPayload(msg Message) any {
+	return map[string]string{"title": msg.Title, "text": msg.Body}
+}
+
+// slackEscaper escapes the characters Slack treats as markup in message text
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackPayload is the body for a Slack incoming webhook, or a compatible one
+// such as Mattermost's, with the title in bold
+func SlackPayload(msg Message) any {
+	return map[string]string{"text": "*" + slackEscaper.Replace(msg.Title) + "*\n" + slackEscaper.Replace(msg.Body)}
+}
+
+// discordLimit is the most characters a Discord message may hold
+const discordLimit = 2000
+
+// DiscordPayload is the body for a Discord webhook, with the title in bold. Long
+// messages are cut to fit Discord's limit.
+func DiscordPayload(msg Message) any {
+	content := []rune("**" + msg.Title + "**\n" + msg.Body)
+	if len(content) > discordLimit {
+		content = append(content[:discordLimit-1], '…')
+	}
+	return map[string]string{"content": string(content)}
+}
+
+// Notify posts msg to the webhook, retrying when the receiver is unavailable
+func (h *Webhook) Notify(ctx context.Context, msg Message) error {
+	payload := h.Payload
+	if payload == nil {
+		payload = JSONPayload
+	}
+	body, err := json.Marshal(payload(msg))
+	if err != nil {
+		return err
+	}
+
+	return retry(ctx, func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+		if err != nil {
+			return redactURLError(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return &DeliveryError{Target: "webhook", Err: redactURLError(err)}
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return responseError("webhook", resp)
+		}
+		return nil
+	})
+}
+
+// RedactTarget returns target fit for output and logs. A webhook URL keeps only
+// its scheme and host, as Slack and Discord webhook URLs carry their secret in
+// the path.
+func RedactTarget(target string) string {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return target
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// redactURLError redacts the URL of a *url.Error, which net/http returns with the
+// full request URL
+func redactURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &url.Error{Op: urlErr.Op, URL: RedactTarget(urlErr.URL), Err: urlErr.Err}
+	}
+	return err
+}
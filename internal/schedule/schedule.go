@@ -0,0 +1,201 @@
+// Package schedule parses cron expressions and computes when they next fire.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule reports the next time a job is due after a given time
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// Every is a schedule that fires at a fixed interval, e.g. "@every 30m"
+type Every time.Duration
+
+// Next returns t plus the interval, truncated to the second
+func (e Every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(time.Duration(e))
+}
+
+// Cron is a five-field cron schedule: minute, hour, day of month, month and
+// day of week, evaluated in the time zone of the time passed to Next
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// When both day fields are restricted, a day matching either one is due,
+	// as in the standard cron
+	domStar, dowStar bool
+}
+
+// field describes the values allowed in one cron field
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Sunday is both 0 and 7
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// macros are the shorthands for common schedules
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression such as "30 7 * * mon-fri", one of the
+// macros @yearly, @monthly, @weekly, @daily and @hourly, or "@every <duration>"
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: @every needs a duration of at least 1s, such as 30m", spec)
+		}
+		return Every(d), nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := macros[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("invalid schedule %q: unknown macro", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields (minute hour day-of-month month day-of-week), got %d", spec, len(fields))
+	}
+	var c Cron
+	var err error
+	parsers := []struct {
+		dst   *uint64
+		field field
+	}{
+		{&c.minute, minuteField},
+		{&c.hour, hourField},
+		{&c.dom, domField},
+		{&c.month, monthField},
+		{&c.dow, dowField},
+	}
+	for i, p := range parsers {
+		if *p.dst, err = p.field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+		}
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domStar = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	c.dowStar = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+	return &c, nil
+}
+
+// parse turns a field such as "1-5", "*/15" or "mon,wed,fri" into a bit set
+func (f field) parse(s string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(s, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s step must be a positive number, got %q", f.name, stepPart)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		if rangePart == "*" {
+			lo, hi = f.min, f.max
+		} else {
+			first, last, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = f.value(first); err != nil {
+				return 0, err
+			}
+			hi = lo
+			switch {
+			case isRange:
+				if hi, err = f.value(last); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("%s range %q ends before it starts", f.name, rangePart)
+				}
+			case hasStep:
+				// "5/15" means every 15 starting at 5
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses one number or name of a field
+func (f field) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("%s must be between %d and %d, got %q", f.name, f.min, f.max, s)
+	}
+	return v, nil
+}
+
+// Next returns the first minute after t that matches the schedule, or the zero
+// time if none does within five years (e.g. "0 0 30 2 *")
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the day of t is due
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domStar || c.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
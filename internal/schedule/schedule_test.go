@@ -0,0 +1,81 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	// 2024-07-01 is a Monday
+	from := time.Date(2024, 7, 1, 8, 15, 30, 0, time.UTC)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"30 7 * * mon-fri", time.Date(2024, 7, 2, 7, 30, 0, 0, time.UTC)},
+		{"30 7 * * 1-5", time.Date(2024, 7, 2, 7, 30, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2024, 7, 1, 8, 20, 0, 0, time.UTC)},
+		{"5/20 9 * * *", time.Date(2024, 7, 1, 9, 5, 0, 0, time.UTC)},
+		{"0 7,18 * * *", time.Date(2024, 7, 1, 18, 0, 0, 0, time.UTC)},
+		{"0 0 * * sat,sun", time.Date(2024, 7, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2024, 7, 7, 12, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Restricting both day fields fires on either: the 15th or a Friday
+		{"0 6 15 * fri", time.Date(2024, 7, 5, 6, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 7, 7, 0, 0, 0, 0, time.UTC)},
+		{"@every 30m", time.Date(2024, 7, 1, 8, 45, 30, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.spec, err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextInLocalTime(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	schedule, err := Parse("30 7 * * *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got := schedule.Next(time.Date(2024, 7, 1, 8, 0, 0, 0, tokyo))
+	if want := time.Date(2024, 7, 2, 7, 30, 0, 0, tokyo); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{"30 7 * *", "expected 5 fields"},
+		{"60 * * * *", "minute must be between 0 and 59"},
+		{"0 24 * * *", "hour must be between 0 and 23"},
+		{"0 0 0 * *", "day of month must be between 1 and 31"},
+		{"0 0 * foo *", `month must be between 1 and 12, got "foo"`},
+		{"0 0 * * 5-1", `day of week range "5-1" ends before it starts`},
+		{"*/0 * * * *", "minute step must be a positive number"},
+		{"@sometimes", "unknown macro"},
+		{"@every soon", "@every needs a duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			_, err := Parse(tt.spec)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+			}
+		})
+	}
+}
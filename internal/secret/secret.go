@@ -0,0 +1,149 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Service is the keyring service attribute under which secrets are stored
+const Service = "weather-cli"
+
+// CommandTimeout bounds how long a secret command may run
+var CommandTimeout = 30 * time.Second
+
+// ErrNotFound is returned when the keyring holds no secret for an account
+var ErrNotFound = errors.New("secret not found in keyring")
+
+// Keyring stores secrets by account name
+type Keyring interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// DefaultKeyring is the keyring used by the application
+var DefaultKeyring Keyring = &SecretTool{Command: "secret-tool"}
+
+// SecretTool is a Keyring backed by the freedesktop Secret Service (GNOME Keyring,
+// KWallet and others) through the secret-tool command from libsecret
+type SecretTool struct {
+	Command string
+}
+
+// Get looks up the secret stored for account
+func (s *SecretTool) Get(account string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(s.Command, "lookup", "service", Service, "account", account)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// secret-tool exits with status 1 and no output when nothing matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() == 0 {
+			return "", ErrNotFound
+		}
+		return "", commandError("secret-tool lookup", err, stderr.String())
+	}
+
+	value := strings.TrimRight(stdout.String(), "\r\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Set stores secret for account, replacing any previous value
+func (s *SecretTool) Set(account, secret string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(s.Command, "store", "--label", Service+" "+account, "service", Service, "account", account)
+	// The secret is passed on standard input so it never appears in the process list
+	cmd.Stdin = strings.NewReader(secret)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return commandError("secret-tool store", err, stderr.String())
+	}
+	return nil
+}
+
+// Delete removes the secret stored for account
+func (s *SecretTool) Delete(account string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(s.Command, "clear", "service", Service, "account", account)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return commandError("secret-tool clear", err, stderr.String())
+	}
+	return nil
+}
+
+// RunCommand runs a shell command such as "pass show owm" and returns the first line
+// of its output
+func RunCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), CommandTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(ctx, command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Let password managers prompt on the terminal
+	cmd.Stdin = os.Stdin
+	// Do not wait for children of the shell that still hold the output open after a timeout
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("command timed out after %s", CommandTimeout)
+		}
+		return "", commandError(command, err, stderr.String())
+	}
+
+	value := firstLine(stdout.String())
+	if value == "" {
+		return "", fmt.Errorf("command '%s' printed nothing", command)
+	}
+	return value, nil
+}
+
+// ReadFile returns the first line of a file holding a secret. A leading ~ is expanded
+// to the home directory.
+func ReadFile(path string) (string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("error getting user home directory: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading secret file: %w", err)
+	}
+
+	value := firstLine(string(data))
+	if value == "" {
+		return "", fmt.Errorf("secret file '%s' is empty", path)
+	}
+	return value, nil
+}
+
+// firstLine returns the first line of s without surrounding whitespace
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return strings.TrimSpace(line)
+}
+
+// commandError wraps a failed command with its error output
+func commandError(name string, err error, stderr string) error {
+	if stderr = strings.TrimSpace(stderr); stderr != "" {
+		return fmt.Errorf("%s failed: %w: %s", name, err, stderr)
+	}
+	return fmt.Errorf("%s failed: %w", name, err)
+}
@@ -0,0 +1,32 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, "owm-key")
+	if err := os.WriteFile(path, []byte("  abc123  \nsecond line\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{path, "~/owm-key"} {
+		if got, err := ReadFile(p); err != nil || got != "abc123" {
+			t.Errorf("ReadFile(%q) = %q, %v, want abc123", p, got, err)
+		}
+	}
+
+	empty := filepath.Join(home, "empty")
+	os.WriteFile(empty, []byte("\n"), 0600)
+	if _, err := ReadFile(empty); err == nil {
+		t.Error("ReadFile() should fail for an empty file")
+	}
+	if _, err := ReadFile(filepath.Join(home, "missing")); err == nil {
+		t.Error("ReadFile() should fail for a missing file")
+	}
+}
@@ -0,0 +1,97 @@
+//go:build unix
+
+package secret
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+		wantErr string
+	}{
+		{"First line of output", "printf 'abc123\\nmore\\n'", "abc123", ""},
+		{"Failing command", "echo 'no such entry' >&2; exit 1", "", "no such entry"},
+		{"No output", "true", "", "printed nothing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RunCommand(tt.command)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("RunCommand() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("RunCommand() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCommandTimeout(t *testing.T) {
+	old := CommandTimeout
+	CommandTimeout = 50 * time.Millisecond
+	defer func() { CommandTimeout = old }()
+
+	if _, err := RunCommand("sleep 5"); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("RunCommand() error = %v, want a timeout", err)
+	}
+}
+
+// fakeSecretTool writes a secret-tool stand-in that keeps secrets in files under dir
+func fakeSecretTool(t *testing.T) *SecretTool {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+# Usage mirrors secret-tool: <action> [--label L] service S account A
+action=$1; shift
+[ "$1" = "--label" ] && shift 2
+file="` + dir + `/$2.$4"
+case $action in
+lookup) [ -f "$file" ] || exit 1; cat "$file" ;;
+store) cat > "$file" ;;
+clear) rm -f "$file" ;;
+*) echo "unknown action $action" >&2; exit 2 ;;
+esac
+`
+	path := filepath.Join(dir, "secret-tool")
+	if err := os.WriteFile(path, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return &SecretTool{Command: path}
+}
+
+func TestSecretTool(t *testing.T) {
+	keyring := fakeSecretTool(t)
+
+	if _, err := keyring.Get("api_key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() before Set() error = %v, want ErrNotFound", err)
+	}
+	if err := keyring.Set("api_key", "s3cret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := keyring.Get("api_key"); err != nil || got != "s3cret" {
+		t.Errorf("Get() = %q, %v, want s3cret", got, err)
+	}
+	if err := keyring.Delete("api_key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := keyring.Get("api_key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+
+	missing := &SecretTool{Command: filepath.Join(t.TempDir(), "secret-tool")}
+	if _, err := missing.Get("api_key"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() without secret-tool error = %v, want a command error", err)
+	}
+}
@@ -0,0 +1,45 @@
+// Package secrettest provides an in-memory keyring for tests of the packages
+// that store secrets
+package secrettest
+
+import (
+	"sync"
+
+	"weather-cli/internal/secret"
+)
+
+// Keyring is a secret.Keyring that keeps secrets in memory
+type Keyring struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// Get returns the secret stored for account
+func (m *Keyring) Get(account string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.secrets[account]
+	if !ok {
+		return "", secret.ErrNotFound
+	}
+	return value, nil
+}
+
+// Set stores secret for account
+func (m *Keyring) Set(account, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.secrets == nil {
+		m.secrets = make(map[string]string)
+	}
+	m.secrets[account] = value
+	return nil
+}
+
+// Delete removes the secret stored for account
+func (m *Keyring) Delete(account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.secrets, account)
+	return nil
+}
@@ -0,0 +1,28 @@
+package secrettest
+
+import (
+	"errors"
+	"testing"
+
+	"weather-cli/internal/secret"
+)
+
+func TestKeyring(t *testing.T) {
+	keyring := &Keyring{}
+
+	if _, err := keyring.Get("api_key"); !errors.Is(err, secret.ErrNotFound) {
+		t.Errorf("Get() on an empty keyring error = %v, want secret.ErrNotFound", err)
+	}
+	if err := keyring.Set("api_key", "s3cret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := keyring.Get("api_key"); err != nil || got != "s3cret" {
+		t.Errorf("Get() = %q, %v, want s3cret", got, err)
+	}
+	if err := keyring.Delete("api_key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := keyring.Get("api_key"); !errors.Is(err, secret.ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want secret.ErrNotFound", err)
+	}
+}
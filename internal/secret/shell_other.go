@@ -0,0 +1,13 @@
+//go:build !unix
+
+package secret
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand runs a command line through cmd.exe
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
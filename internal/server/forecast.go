@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/weather"
+)
+
+// forecastStep is the length of the period each forecast entry covers
+const forecastStep = 3 * time.Hour
+
+// handleForecast answers with the forecast for a location in the provider's schema
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	data, err := s.forecast(r.URL.Query())
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// handleCurrent answers with the forecast cut down to the entry covering the
+// present, or the next one
+func (s *Server) handleCurrent(w http.ResponseWriter, r *http.Request) {
+	data, err := s.forecast(r.URL.Query())
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	current, err := currentEntry(data, time.Now())
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, current)
+}
+
+// forecast fetches the forecast for the location named by query: a saved
+// location with location=<name>, coordinates with lat=<lat>&lon=<lon>, or the
+// default location when neither is given
+func (s *Server) forecast(query url.Values) (*weather.WeatherData, error) {
+	s.mu.RLock()
+	loc, err := s.queryLocation(query)
+	// The fetch works on a copy, so location changes don't wait for the provider
+	cfg := *s.cfg
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := weather.GetWeatherForecast(&cfg, *loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch weather data for %s: %w", loc.Name, err)
+	}
+	return data, nil
+}
+
+// queryLocation resolves the location a forecast request asks for
+func (s *Server) queryLocation(query url.Values) (*config.Location, error) {
+	name := query.Get("location")
+	lat, lon := query.Get("lat"), query.Get("lon")
+	switch {
+	case name != "" && (lat != "" || lon != ""):
+		return nil, &badRequest{errors.New("use either location or lat and lon, not both")}
+	case lat != "" || lon != "":
+		latitude, err1 := strconv.ParseFloat(lat, 64)
+		longitude, err2 := strconv.ParseFloat(lon, 64)
+		if err1 != nil || err2 != nil {
+			return nil, &badRequest{fmt.Errorf("lat and lon must both be numbers, got %q and %q", lat, lon)}
+		}
+		latitude, longitude, err := location.NormalizeCoordinates(latitude, longitude)
+		if err != nil {
+			return nil, &badRequest{err}
+		}
+		return &config.Location{Name: fmt.Sprintf("%.4f, %.4f", latitude, longitude), Latitude: latitude, Longitude: longitude}, nil
+	case name != "":
+		loc, err := location.NewManager(s.cfg).GetLocation(name)
+		if err != nil {
+			return nil, &badRequest{err}
+		}
+		return loc, nil
+	}
+
+	loc, err := location.NewManager(s.cfg).GetDefaultLocation()
+	if err != nil {
+		return nil, &badRequest{errors.New("location or lat and lon is required when no default location is set")}
+	}
+	return loc, nil
+}
+
+// currentEntry returns a copy of data holding only the entry that covers now,
+// or the first one if the forecast starts later
+func currentEntry(data *weather.WeatherData, now time.Time) (*weather.WeatherData, error) {
+	if len(data.List) == 0 {
+		return nil, errors.New("the forecast has no entries")
+	}
+	i := 0
+	for i < len(data.List)-1 && time.Unix(data.List[i].Dt, 0).Add(forecastStep).Before(now) {
+		i++
+	}
+	current := *data
+	current.List = data.List[i : i+1]
+	current.Cnt = 1
+	return &current, nil
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather"
+	"weather-cli/internal/weather/weathertest"
+)
+
+// fakeProvider serves a forecast whose last entry started an hour ago, through a
+// cached forecast service. Requests for Sydney are rejected.
+func fakeProvider(t *testing.T, delay time.Duration) *weathertest.Provider {
+	provider := &weathertest.Provider{
+		Entries: []weathertest.Entry{
+			{At: -4 * time.Hour, Temp: 12.0, ID: 800, Description: "clear sky"},
+			{At: -time.Hour, Temp: 15.0, ID: 500, Description: "light rain"},
+		},
+		Delay:          delay,
+		RejectLatitude: "-33.868800",
+	}
+	provider.Start(t, weather.NewCachedWeatherService(&weather.RealWeatherService{}, time.Minute, ""))
+	return provider
+}
+
+func forecastConfig() *config.Config {
+	return &config.Config{
+		APIKey:          "test_api_key",
+		TemperatureUnit: "C",
+		Locations:       []config.Location{{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}, {Name: "Toyama", Latitude: 36.6953, Longitude: 137.2113}},
+		DefaultLocation: "Tokyo",
+	}
+}
+
+func TestForecast(t *testing.T) {
+	provider := fakeProvider(t, 0)
+	server, _ := testServer(t, forecastConfig(), Options{})
+
+	for _, query := range []string{"?location=tokyo", "", "?lat=35.6895&lon=139.6917"} {
+		var data weather.WeatherData
+		resp := request(t, http.MethodGet, server.URL+"/v1/forecast"+query, "", "", &data)
+		if resp.StatusCode != http.StatusOK || data.City.Name != "Tokyo" || len(data.List) != 2 {
+			t.Errorf("GET /v1/forecast%s = %d %+v", query, resp.StatusCode, data)
+		}
+	}
+	// The saved location, the default location and the same coordinates share one cache entry
+	if n := provider.Calls(); n != 1 {
+		t.Errorf("Provider was called %d times, want 1", n)
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	fakeProvider(t, 0)
+	server, _ := testServer(t, forecastConfig(), Options{})
+
+	var data weather.WeatherData
+	resp := request(t, http.MethodGet, server.URL+"/v1/current?location=Tokyo", "", "", &data)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /v1/current = %d", resp.StatusCode)
+	}
+	if data.Cnt != 1 || len(data.List) != 1 || data.List[0].Main.Temp != 15.0 {
+		t.Errorf("GET /v1/current returned %+v, want the entry covering the present", data)
+	}
+}
+
+func TestForecastErrors(t *testing.T) {
+	fakeProvider(t, 0)
+	cfg := forecastConfig()
+	cfg.Locations = append(cfg.Locations, config.Location{Name: "Sydney", Latitude: -33.8688, Longitude: 151.2093})
+	server, _ := testServer(t, cfg, Options{})
+
+	tests := []struct {
+		query  string
+		status int
+		want   string
+	}{
+		{"?location=Osaka", http.StatusNotFound, "location not found"},
+		{"?location=to", http.StatusBadRequest, "location 'to' is ambiguous: matches 'Tokyo', 'Toyama'"},
+		{"?lat=north&lon=1", http.StatusBadRequest, `lat and lon must both be numbers, got "north" and "1"`},
+		{"?lat=95&lon=1", http.StatusBadRequest, "latitude"},
+		{"?location=Tokyo&lat=1", http.StatusBadRequest, "use either location or lat and lon, not both"},
+		{"?location=Sydney", http.StatusBadGateway, "OpenWeather API rejected the API key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var body map[string]string
+			resp := request(t, http.MethodGet, server.URL+"/v1/forecast"+tt.query, "", "", &body)
+			if resp.StatusCode != tt.status || !strings.Contains(body["error"], tt.want) {
+				t.Errorf("GET /v1/forecast%s = %d %q, want %d %q", tt.query, resp.StatusCode, body["error"], tt.status, tt.want)
+			}
+		})
+	}
+}
+
+func TestForecastWithoutDefaultLocation(t *testing.T) {
+	fakeProvider(t, 0)
+	cfg := forecastConfig()
+	cfg.DefaultLocation = ""
+	server, _ := testServer(t, cfg, Options{})
+
+	if resp := request(t, http.MethodGet, server.URL+"/v1/forecast", "", "", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("GET /v1/forecast = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	fakeProvider(t, 300*time.Millisecond)
+	server, _ := testServer(t, forecastConfig(), Options{Timeout: 50 * time.Millisecond})
+
+	var body map[string]string
+	resp := request(t, http.MethodGet, server.URL+"/v1/forecast", "", "", &body)
+	if resp.StatusCode != http.StatusServiceUnavailable || body["error"] != "the request timed out" {
+		t.Errorf("GET /v1/forecast = %d %v, want a timeout", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+)
+
+// newLocation is the body of a request to save a location
+type newLocation struct {
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Aliases   []string `json:"aliases"`
+	Tags      []string `json:"tags"`
+	Timezone  string   `json:"timezone"`
+}
+
+// locationPatch is the body of a request to change a saved location; fields that
+// are left out stay as they are
+type locationPatch struct {
+	Name      *string   `json:"name"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	Aliases   *[]string `json:"aliases"`
+	Tags      *[]string `json:"tags"`
+	Timezone  *string   `json:"timezone"`
+}
+
+// handleListLocations answers with the saved locations, or those with ?tag=<tag>
+func (s *Server) handleListLocations(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	manager := location.NewManager(s.cfg)
+	locations := manager.ListLocations()
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		locations = manager.LocationsByTag(tag)
+	}
+	if locations == nil {
+		locations = []config.Location{}
+	}
+	writeJSON(w, http.StatusOK, locations)
+}
+
+// handleGetLocation answers with one saved location, found like on the command line
+func (s *Server) handleGetLocation(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	loc, err := location.NewManager(s.cfg).GetLocation(r.PathValue("name"))
+	if err != nil {
+		// An ambiguous name is the client's to fix
+		err = &badRequest{err}
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, loc)
+}
+
+// handleCreateLocation saves a new location
+func (s *Server) handleCreateLocation(w http.ResponseWriter, r *http.Request) {
+	var body newLocation
+	if err := decodeBody(w, r, &body); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	loc, err := s.createLocation(body)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.Header().Set("Location", "/v1/locations/"+url.PathEscape(loc.Name))
+	writeJSON(w, http.StatusCreated, loc)
+}
+
+// handleUpdateLocation changes the fields of a saved location given in the body
+func (s *Server) handleUpdateLocation(w http.ResponseWriter, r *http.Request) {
+	var patch locationPatch
+	if err := decodeBody(w, r, &patch); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manager := location.NewManager(s.cfg)
+	name, err := s.updateLocation(manager, r.PathValue("name"), patch)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	loc, err := manager.GetLocation(name)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, loc)
+}
+
+// handleDeleteLocation removes a saved location
+func (s *Server) handleDeleteLocation(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := location.NewManager(s.cfg).RemoveLocation(r.PathValue("name")); err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// createLocation saves body as a new location, or nothing if its aliases, tags or
+// timezone are rejected
+func (s *Server) createLocation(body newLocation) (*config.Location, error) {
+	if strings.TrimSpace(body.Name) == "" {
+		return nil, &badRequest{errors.New("name must not be empty")}
+	}
+	if body.Latitude == nil || body.Longitude == nil {
+		return nil, &badRequest{errors.New("latitude and longitude are required")}
+	}
+
+	manager := location.NewManager(s.cfg)
+	change := location.LocationChange{}
+	if body.Aliases != nil {
+		change.Aliases = &body.Aliases
+	}
+	if body.Tags != nil {
+		change.Tags = &body.Tags
+	}
+	if body.Timezone != "" {
+		change.Timezone = &body.Timezone
+	}
+	if err := manager.CreateLocation(body.Name, *body.Latitude, *body.Longitude, change); err != nil {
+		return nil, &badRequest{err}
+	}
+	return manager.GetLocation(body.Name)
+}
+
+// updateLocation applies all of patch to the saved location name, or none of it,
+// and returns the name it ends up with. Errors from the location manager are
+// reported as bad requests, except for a location that is not found or a name
+// that is taken.
+func (s *Server) updateLocation(manager *location.Manager, name string, patch locationPatch) (string, error) {
+	// Unlike lookups, changes need the full name or an alias, as on the command line
+	change := location.LocationChange{
+		LocationEdit: location.LocationEdit{Latitude: patch.Latitude, Longitude: patch.Longitude, Timezone: patch.Timezone, Aliases: patch.Aliases},
+		Name:         patch.Name,
+		Tags:         patch.Tags,
+	}
+	name, err := manager.ChangeLocation(name, change)
+	if err != nil {
+		return "", &badRequest{err}
+	}
+	return name, nil
+}
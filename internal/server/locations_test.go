@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"weather-cli/internal/config"
+)
+
+func TestLocationsCRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv(config.ConfigEnvVar, path)
+	cfg := &config.Config{
+		TemperatureUnit: "C",
+		Locations:       []config.Location{{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917, Tags: []string{"asia"}}},
+	}
+	server, _ := testServer(t, cfg, Options{})
+	url := server.URL + "/v1/locations"
+
+	var created config.Location
+	resp := request(t, http.MethodPost, url, "", `{"name": "Office", "latitude": 51.5074, "longitude": -0.1278, "aliases": ["work"], "tags": ["europe"], "timezone": "Europe/London"}`, &created)
+	want := config.Location{Name: "Office", Latitude: 51.5074, Longitude: -0.1278, Aliases: []string{"work"}, Tags: []string{"europe"}, Timezone: "Europe/London"}
+	if resp.StatusCode != http.StatusCreated || !reflect.DeepEqual(created, want) {
+		t.Errorf("POST = %d %+v, want %+v", resp.StatusCode, created, want)
+	}
+	if resp.Header.Get("Location") != "/v1/locations/Office" {
+		t.Errorf("Location header = %q", resp.Header.Get("Location"))
+	}
+
+	var got config.Location
+	if resp := request(t, http.MethodGet, url+"/work", "", "", &got); resp.StatusCode != http.StatusOK || got.Name != "Office" {
+		t.Errorf("GET by alias = %d %+v", resp.StatusCode, got)
+	}
+
+	var list []config.Location
+	if request(t, http.MethodGet, url+"?tag=europe", "", "", &list); len(list) != 1 || list[0].Name != "Office" {
+		t.Errorf("GET ?tag=europe = %+v", list)
+	}
+
+	var updated config.Location
+	resp = request(t, http.MethodPatch, url+"/Office", "", `{"name": "HQ", "latitude": 51.5, "tags": ["uk", "EUROPE"]}`, &updated)
+	want = config.Location{Name: "HQ", Latitude: 51.5, Longitude: -0.1278, Aliases: []string{"work"}, Tags: []string{"europe", "uk"}, Timezone: "Europe/London"}
+	if resp.StatusCode != http.StatusOK || !reflect.DeepEqual(updated, want) {
+		t.Errorf("PATCH = %d %+v, want %+v", resp.StatusCode, updated, want)
+	}
+
+	if resp := request(t, http.MethodDelete, url+"/Tokyo", "", "", nil); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE = %d, want 204", resp.StatusCode)
+	}
+	if request(t, http.MethodGet, url, "", "", &list); len(list) != 1 || list[0].Name != "HQ" {
+		t.Errorf("GET after DELETE = %+v", list)
+	}
+
+	// The changes were saved to the config file
+	saved, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if len(saved.Locations) != 1 || saved.Locations[0].Name != "HQ" {
+		t.Errorf("Saved locations = %+v", saved.Locations)
+	}
+}
+
+func TestLocationErrors(t *testing.T) {
+	t.Setenv(config.ConfigEnvVar, filepath.Join(t.TempDir(), "config.json"))
+	cfg := &config.Config{
+		TemperatureUnit: "C",
+		Locations:       []config.Location{{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}},
+	}
+	server, _ := testServer(t, cfg, Options{})
+	url := server.URL + "/v1/locations"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"Duplicate name", http.MethodPost, "", `{"name": "tokyo", "latitude": 1, "longitude": 2}`, http.StatusConflict, "location with name 'Tokyo' already exists"},
+		{"Missing coordinates", http.MethodPost, "", `{"name": "Osaka"}`, http.StatusBadRequest, "latitude and longitude are required"},
+		{"Invalid latitude", http.MethodPost, "", `{"name": "Osaka", "latitude": 95, "longitude": 135}`, http.StatusBadRequest, "latitude"},
+		{"Unknown field", http.MethodPost, "", `{"name": "Osaka", "lat": 34.7}`, http.StatusBadRequest, `invalid request body: unknown field "lat"`},
+		{"Invalid timezone", http.MethodPost, "", `{"name": "Osaka", "latitude": 34.7, "longitude": 135.5, "timezone": "Mars/Olympus"}`, http.StatusBadRequest, "invalid timezone 'Mars/Olympus'"},
+		{"Unknown location", http.MethodGet, "/Osaka", "", http.StatusNotFound, "location not found"},
+		{"Update by prefix", http.MethodPatch, "/Tok", `{"latitude": 1}`, http.StatusNotFound, "location not found"},
+		{"Invalid rename", http.MethodPatch, "/Tokyo", `{"latitude": 1, "tags": ["japan"], "name": " "}`, http.StatusBadRequest, "new name must not be empty"},
+		{"Empty tag", http.MethodPost, "", `{"name": "Osaka", "latitude": 34.7, "longitude": 135.5, "tags": [""]}`, http.StatusBadRequest, "tag must not be empty"},
+		{"Delete unknown", http.MethodDelete, "/Osaka", "", http.StatusNotFound, "location not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]string
+			resp := request(t, tt.method, url+tt.path, "", tt.body, &body)
+			if resp.StatusCode != tt.status || !strings.Contains(body["error"], tt.want) {
+				t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, resp.StatusCode, body["error"], tt.status, tt.want)
+			}
+		})
+	}
+
+	// Rejected requests leave no location behind and no part of a change applied
+	want := []config.Location{{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}}
+	var list []config.Location
+	if request(t, http.MethodGet, url, "", "", &list); !reflect.DeepEqual(list, want) {
+		t.Errorf("Locations after failed requests = %+v, want %+v", list, want)
+	}
+}
@@ -0,0 +1,214 @@
+// Package server serves forecasts and the saved locations over HTTP, so several
+// clients can share one API key and the forecast cache.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/location"
+	"weather-cli/internal/weather"
+)
+
+// DefaultTimeout is how long a request may take when Options sets no timeout
+const DefaultTimeout = 30 * time.Second
+
+// ShutdownTimeout is how long requests in progress are given to finish on shutdown
+const ShutdownTimeout = 10 * time.Second
+
+// maxBodySize limits the size of request bodies
+const maxBodySize = 1 << 20
+
+// Options configure a Server
+type Options struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>" with every
+	// request except /healthz
+	Token string
+	// Timeout limits how long a request may take; DefaultTimeout when zero
+	Timeout time.Duration
+	// Logger receives a line for every request
+	Logger *slog.Logger
+}
+
+// Server answers API requests from the forecast service and the saved locations of cfg
+type Server struct {
+	// mu guards cfg, which location changes may replace with a fresh copy of the file
+	mu      sync.RWMutex
+	cfg     *config.Config
+	token   string
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// New creates a server for cfg
+func New(cfg *config.Config, opts Options) *Server {
+	s := &Server{cfg: cfg, token: opts.Token, timeout: opts.Timeout, logger: opts.Logger}
+	if s.timeout <= 0 {
+		s.timeout = DefaultTimeout
+	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	return s
+}
+
+// Handler returns the routes of the API wrapped in logging, authentication and
+// the request timeout
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /v1/forecast", s.handleForecast)
+	mux.HandleFunc("GET /v1/current", s.handleCurrent)
+	mux.HandleFunc("GET /v1/locations", s.handleListLocations)
+	mux.HandleFunc("POST /v1/locations", s.handleCreateLocation)
+	mux.HandleFunc("GET /v1/locations/{name}", s.handleGetLocation)
+	mux.HandleFunc("PATCH /v1/locations/{name}", s.handleUpdateLocation)
+	mux.HandleFunc("DELETE /v1/locations/{name}", s.handleDeleteLocation)
+
+	timeoutBody := `{"error":"the request timed out"}`
+	return s.logRequests(s.authenticate(http.TimeoutHandler(mux, s.timeout, timeoutBody)))
+}
+
+// Serve answers requests on listener until ctx is cancelled, then gives the
+// requests in progress ShutdownTimeout to finish
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       s.timeout,
+		WriteTimeout:      s.timeout + 5*time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ErrorLog:          slog.NewLogLogger(s.logger.Handler(), slog.LevelWarn),
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(listener) }()
+	s.logger.Info("server started", "addr", listener.Addr().String(), "auth", s.token != "")
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	s.logger.Info("server stopping, waiting for requests in progress")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down the server: %w", err)
+	}
+	s.logger.Info("server stopped")
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// authenticate rejects requests without the bearer token when one is required
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	if s.token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if r.URL.Path != "/healthz" && subtle.ConstantTimeCompare(got, want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="weather"`)
+			writeError(w, http.StatusUnauthorized, errors.New("a valid bearer token is required"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs the method, path, status and duration of every request
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		s.logger.Log(r.Context(), level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"query", r.URL.RawQuery,
+			"status", recorder.status,
+			"duration", time.Since(start).Round(time.Microsecond),
+			"remote", r.RemoteAddr,
+		)
+	})
+}
+
+// writeJSON writes v as the JSON body of a response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON body {"error": "..."}
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// badRequest marks an error caused by the request itself
+type badRequest struct {
+	err error
+}
+
+func (e *badRequest) Error() string { return e.err.Error() }
+func (e *badRequest) Unwrap() error { return e.err }
+
+// statusOf is the status of a response reporting err
+func statusOf(err error) int {
+	var bad *badRequest
+	switch {
+	case errors.Is(err, location.ErrLocationNotFound), errors.Is(err, weather.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, location.ErrNameTaken):
+		return http.StatusConflict
+	case errors.As(err, &bad):
+		return http.StatusBadRequest
+	case errors.Is(err, weather.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, weather.ErrUnauthorized), errors.Is(err, weather.ErrNetwork):
+		// The provider refused the server's API key or could not be reached
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
+
+// decodeBody reads the JSON body of r into v, rejecting unknown fields
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return &badRequest{fmt.Errorf("invalid request body: %s", strings.TrimPrefix(err.Error(), "json: "))}
+	}
+	return nil
+}
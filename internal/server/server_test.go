@@ -0,0 +1,137 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+// testServer starts a server for cfg and returns it with its log output
+func testServer(t *testing.T, cfg *config.Config, opts Options) (*httptest.Server, *bytes.Buffer) {
+	t.Helper()
+	var logs bytes.Buffer
+	opts.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+	server := httptest.NewServer(New(cfg, opts).Handler())
+	t.Cleanup(server.Close)
+	return server, &logs
+}
+
+// request sends a request with an optional JSON body and decodes the JSON answer into out
+func request(t *testing.T, method, url, token, body string, out any) *http.Response {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s returned invalid JSON: %v", method, url, err)
+		}
+	}
+	return resp
+}
+
+func TestHealth(t *testing.T) {
+	server, logs := testServer(t, &config.Config{}, Options{})
+	var body map[string]string
+	resp := request(t, http.MethodGet, server.URL+"/healthz", "", "", &body)
+	if resp.StatusCode != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("GET /healthz = %d %v", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(logs.String(), `"msg":"request","method":"GET","path":"/healthz","query":"","status":200`) {
+		t.Errorf("Request wasn't logged:\n%s", logs)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	server, _ := testServer(t, &config.Config{}, Options{Token: "s3cret"})
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{"Missing token", "/v1/locations", "", http.StatusUnauthorized},
+		{"Wrong token", "/v1/locations", "guess", http.StatusUnauthorized},
+		{"Valid token", "/v1/locations", "s3cret", http.StatusOK},
+		{"Health checks need no token", "/healthz", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := request(t, http.MethodGet, server.URL+tt.path, tt.token, "", nil)
+			if resp.StatusCode != tt.status {
+				t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.status)
+			}
+			if tt.status == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Error("401 response has no WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestUnknownRoute(t *testing.T) {
+	server, _ := testServer(t, &config.Config{}, Options{})
+	if resp := request(t, http.MethodGet, server.URL+"/v2/forecast", "", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /v2/forecast = %d, want 404", resp.StatusCode)
+	}
+	if resp := request(t, http.MethodPut, server.URL+"/v1/locations", "", "{}", nil); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("PUT /v1/locations = %d, want 405", resp.StatusCode)
+	}
+}
+
+func TestServeShutsDown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	var logs bytes.Buffer
+	s := New(&config.Config{}, Options{Logger: slog.New(slog.NewJSONHandler(&logs, nil))})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Serve(ctx, listener) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz failed: %v", err)
+	}
+	resp.Body.Close()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve() didn't return after the context was cancelled")
+	}
+	if !strings.Contains(logs.String(), `"msg":"server stopped"`) {
+		t.Errorf("Shutdown wasn't logged:\n%s", logs.String())
+	}
+}
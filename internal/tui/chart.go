@@ -0,0 +1,31 @@
+package tui
+
+import (
+	"strings"
+)
+
+// sparkTicks are the bar heights used to draw sparkline charts
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a single line of bars scaled between their min and max
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(sparkTicks)-1))
+		}
+		sb.WriteRune(sparkTicks[idx])
+	}
+	return sb.String()
+}
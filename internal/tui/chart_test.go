@@ -0,0 +1,24 @@
+package tui
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"Empty", nil, ""},
+		{"Flat", []float64{5, 5, 5}, "▁▁▁"},
+		{"Rising", []float64{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{"Negative values", []float64{-10, 0, -5}, "▁█▄"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.values); got != tt.want {
+				t.Errorf("sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
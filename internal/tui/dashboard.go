@@ -0,0 +1,299 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather"
+)
+
+// DefaultRefreshInterval is how often the dashboard refreshes the selected forecast
+var DefaultRefreshInterval = 10 * time.Minute
+
+// sidebarWidth is the width of the location list on the left of the screen
+const sidebarWidth = 20
+
+// timeRange is a selectable span of the forecast, in 3-hour forecast entries
+type timeRange struct {
+	label   string
+	entries int
+}
+
+var timeRanges = []timeRange{
+	{"24h", 8},
+	{"48h", 16},
+	{"5d", 40},
+}
+
+// Dashboard is a full-screen view of the saved locations and their forecasts
+type Dashboard struct {
+	cfg       *config.Config
+	term      Terminal
+	locations []config.Location
+	selected  int
+	unit      string
+	rangeIdx  int
+	refresh   time.Duration
+
+	data    map[string]*weather.WeatherData
+	errs    map[string]error
+	updated map[string]time.Time
+	loading map[string]bool
+
+	// results receives the forecasts fetched in the background, which stop
+	// being delivered once done is closed
+	results chan fetchResult
+	done    chan struct{}
+}
+
+// fetchResult is the outcome of fetching the forecast for one location
+type fetchResult struct {
+	name string
+	data *weather.WeatherData
+	err  error
+	at   time.Time
+}
+
+// NewDashboard creates a dashboard for the locations saved in cfg
+func NewDashboard(cfg *config.Config, term Terminal, refresh time.Duration) *Dashboard {
+	unit := cfg.TemperatureUnit
+	if unit == "" {
+		unit = "C"
+	}
+	return &Dashboard{
+		cfg:       cfg,
+		term:      term,
+		locations: cfg.Locations,
+		unit:      unit,
+		refresh:   refresh,
+		data:      make(map[string]*weather.WeatherData),
+		errs:      make(map[string]error),
+		updated:   make(map[string]time.Time),
+		loading:   make(map[string]bool),
+		results:   make(chan fetchResult),
+		done:      make(chan struct{}),
+	}
+}
+
+// Run draws the dashboard and handles key presses until the user quits or ctx is cancelled
+func (d *Dashboard) Run(ctx context.Context) error {
+	if len(d.locations) == 0 {
+		return errors.New("no saved locations. Add one with: weather -i <latitude> <longitude> <name>")
+	}
+
+	defer close(d.done)
+
+	keys := make(chan Key)
+	readErrs := make(chan error, 1)
+	go func() {
+		for {
+			key, err := d.term.ReadKey()
+			if err != nil {
+				readErrs <- err
+				return
+			}
+			// Nothing receives the keys pressed after Run returns
+			select {
+			case keys <- key:
+			case <-d.done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(d.refresh)
+	defer ticker.Stop()
+
+	d.fetch()
+	d.Render()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErrs:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("error reading keyboard input: %w", err)
+		case key := <-keys:
+			if d.HandleKey(key) {
+				return nil
+			}
+		case result := <-d.results:
+			d.apply(result)
+		case <-ticker.C:
+			d.fetch()
+		}
+		d.Render()
+	}
+}
+
+// HandleKey applies a key press and reports whether the dashboard should exit
+func (d *Dashboard) HandleKey(key Key) bool {
+	switch key {
+	case KeyUp:
+		d.selected = (d.selected + len(d.locations) - 1) % len(d.locations)
+		d.fetchIfMissing()
+	case KeyDown:
+		d.selected = (d.selected + 1) % len(d.locations)
+		d.fetchIfMissing()
+	case KeyUnit:
+		if d.unit == "C" {
+			d.unit = "F"
+		} else {
+			d.unit = "C"
+		}
+	case KeyRange:
+		d.rangeIdx = (d.rangeIdx + 1) % len(timeRanges)
+	case KeyRefresh:
+		d.fetch()
+	case KeyQuit:
+		return true
+	}
+	return false
+}
+
+// fetch starts refreshing the forecast for the selected location in the
+// background, so key presses are handled while the API is queried
+func (d *Dashboard) fetch() {
+	loc := d.locations[d.selected]
+	if d.loading[loc.Name] {
+		return
+	}
+	d.loading[loc.Name] = true
+	service := weather.DefaultWeatherService
+	go func() {
+		data, err := service.GetWeatherForecast(d.cfg, loc)
+		select {
+		case d.results <- fetchResult{name: loc.Name, data: data, err: err, at: time.Now()}:
+		case <-d.done:
+		}
+	}()
+}
+
+// apply stores a fetched forecast, keeping old data on failure
+func (d *Dashboard) apply(result fetchResult) {
+	delete(d.loading, result.name)
+	d.errs[result.name] = result.err
+	if result.err == nil {
+		d.data[result.name] = result.data
+		d.updated[result.name] = result.at
+	}
+}
+
+// fetchIfMissing fetches the selected location if it has not been loaded yet
+func (d *Dashboard) fetchIfMissing() {
+	if _, ok := d.data[d.locations[d.selected].Name]; !ok {
+		d.fetch()
+	}
+}
+
+// Render draws one full frame to the terminal
+func (d *Dashboard) Render() {
+	width, height := d.term.Size()
+	loc := d.locations[d.selected]
+
+	lines := []string{
+		fmt.Sprintf("Weather Dashboard   unit: °%s   range: %s", d.unit, timeRanges[d.rangeIdx].label),
+		strings.Repeat("─", width),
+	}
+	lines = append(lines, columns(d.sidebar(), d.forecastPane(loc), sidebarWidth)...)
+
+	footer := "↑/↓ j/k: location   u: unit   t: range   r: refresh   q: quit"
+	if updated, ok := d.updated[loc.Name]; ok {
+		footer += "   Last updated: " + updated.Format("15:04:05")
+	}
+	if d.loading[loc.Name] {
+		footer += "   Refreshing..."
+	}
+
+	// Keep the footer on the last row, cutting the body if the screen is too small
+	bodyRows := max(height-2, 0)
+	if len(lines) > bodyRows {
+		lines = lines[:bodyRows]
+	}
+	for len(lines) < bodyRows {
+		lines = append(lines, "")
+	}
+	lines = append(lines, strings.Repeat("─", width), footer)
+
+	var sb strings.Builder
+	sb.WriteString("\033[H\033[2J")
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(truncate(line, width))
+	}
+	io.WriteString(d.term, sb.String())
+}
+
+// sidebar lists the saved locations, marking the selected one
+func (d *Dashboard) sidebar() []string {
+	lines := []string{"Locations", ""}
+	for i, loc := range d.locations {
+		marker := "  "
+		if i == d.selected {
+			marker = "> "
+		}
+		lines = append(lines, marker+loc.Name)
+	}
+	return lines
+}
+
+// forecastPane renders the current conditions, art, daily summary and charts for a location
+func (d *Dashboard) forecastPane(loc config.Location) []string {
+	data, ok := d.data[loc.Name]
+	if !ok || len(data.List) == 0 {
+		if err := d.errs[loc.Name]; err != nil {
+			return []string{loc.Name, "", "Error: " + err.Error()}
+		}
+		return []string{loc.Name, "", "Loading..."}
+	}
+
+	now := data.List[0]
+	lines := []string{
+		fmt.Sprintf("%s, %s", data.City.Name, data.City.Country),
+		"",
+		fmt.Sprintf("Now: %s (feels like %s)", d.temperature(now.Main.Temp), d.temperature(now.Main.FeelsLike)),
+		fmt.Sprintf("Humidity: %d%%   Wind: %.1f m/s", now.Main.Humidity, now.Wind.Speed),
+	}
+	if len(now.Weather) > 0 {
+		lines = append(lines, "Weather: "+now.Weather[0].Description)
+		lines = append(lines, strings.Split(strings.Trim(weather.GetWeatherAscii(now.Weather[0].ID), "\n"), "\n")...)
+	}
+
+	// Daily summary
+	lines = append(lines, "", "Daily summary")
+	for _, day := range weather.SummarizeDays(data) {
+		lines = append(lines, fmt.Sprintf("%s  %s / %s  rain %3.0f%%  %s",
+			day.Date.Format("Mon 01-02"), d.temperature(day.TempMin), d.temperature(day.TempMax), day.MaxPop*100, day.Description))
+	}
+
+	// Charts over the selected time range
+	entries := data.List[:min(len(data.List), timeRanges[d.rangeIdx].entries)]
+	temps := make([]float64, len(entries))
+	pops := make([]float64, len(entries))
+	for i, item := range entries {
+		temps[i] = weather.ConvertTemperature(item.Main.Temp, "C", d.unit)
+		pops[i] = item.Pop
+	}
+	lines = append(lines, "", fmt.Sprintf("Temperature (%s)", timeRanges[d.rangeIdx].label), sparkline(temps))
+	lines = append(lines, fmt.Sprintf("Rain chance (%s)", timeRanges[d.rangeIdx].label), sparkline(pops))
+
+	if err := d.errs[loc.Name]; err != nil {
+		lines = append(lines, "", "Refresh failed: "+err.Error())
+	}
+	return lines
+}
+
+// temperature formats a Celsius temperature in the dashboard's current unit
+func (d *Dashboard) temperature(celsius float64) string {
+	return fmt.Sprintf("%.1f°%s", weather.ConvertTemperature(celsius, "C", d.unit), d.unit)
+}
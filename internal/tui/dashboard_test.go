@@ -0,0 +1,266 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+	"weather-cli/internal/weather"
+)
+
+// fakeTerminal records frames and reads key presses from keys until it is closed
+type fakeTerminal struct {
+	mu     sync.Mutex
+	out    bytes.Buffer
+	keys   chan Key
+	width  int
+	height int
+	closed bool
+}
+
+func (f *fakeTerminal) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.out.Write(p)
+}
+
+func (f *fakeTerminal) Size() (int, int) {
+	return f.width, f.height
+}
+
+func (f *fakeTerminal) ReadKey() (Key, error) {
+	key, ok := <-f.keys
+	if !ok {
+		return KeyUnknown, io.EOF
+	}
+	return key, nil
+}
+
+func (f *fakeTerminal) Close() error {
+	f.closed = true
+	return nil
+}
+
+// lastFrame returns the most recently drawn screen
+func (f *fakeTerminal) lastFrame() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	frames := strings.Split(f.out.String(), "\033[H\033[2J")
+	return frames[len(frames)-1]
+}
+
+// waitFor waits until the last frame contains want
+func (f *fakeTerminal) waitFor(t *testing.T, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(f.lastFrame(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected a frame containing '%s'.\nLast frame:\n%s", want, f.lastFrame())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// mockWeatherService serves a fixed forecast per location name
+type mockWeatherService struct {
+	mu    sync.Mutex
+	calls map[string]int
+	err   error
+}
+
+func (m *mockWeatherService) setErr(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+func (m *mockWeatherService) GetWeatherForecast(cfg *config.Config, location config.Location) (*weather.WeatherData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[location.Name]++
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	var data weather.WeatherData
+	err := json.Unmarshal([]byte(`{
+		"city": {"name": "`+location.Name+`", "country": "JP", "timezone": 32400},
+		"list": [
+			{"dt": 1719759600, "main": {"temp": 20.0, "feels_like": 19.0, "humidity": 60}, "wind": {"speed": 3.5}, "pop": 0.1, "weather": [{"id": 800, "description": "clear sky"}]},
+			{"dt": 1719770400, "main": {"temp": 25.0, "feels_like": 26.0, "humidity": 55}, "wind": {"speed": 4.0}, "pop": 0.8, "weather": [{"id": 500, "description": "light rain"}]}
+		]
+	}`), &data)
+	return &data, err
+}
+
+func setupDashboard(t *testing.T, service weather.WeatherService) (*Dashboard, *fakeTerminal) {
+	t.Helper()
+	original := weather.DefaultWeatherService
+	weather.DefaultWeatherService = service
+	t.Cleanup(func() { weather.DefaultWeatherService = original })
+
+	cfg := &config.Config{
+		Locations: []config.Location{
+			{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917},
+			{Name: "Osaka", Latitude: 34.6937, Longitude: 135.5023},
+		},
+		TemperatureUnit: "C",
+	}
+	term := &fakeTerminal{keys: make(chan Key), width: 100, height: 40}
+	return NewDashboard(cfg, term, DefaultRefreshInterval), term
+}
+
+// runDashboard runs the dashboard in the background and returns the result of Run
+func runDashboard(dashboard *Dashboard) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- dashboard.Run(context.Background()) }()
+	return done
+}
+
+func TestDashboardRender(t *testing.T) {
+	dashboard, term := setupDashboard(t, &mockWeatherService{})
+	done := runDashboard(dashboard)
+
+	term.waitFor(t, "Last updated:")
+	term.keys <- KeyQuit
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	frame := term.lastFrame()
+	expected := []string{
+		"Weather Dashboard",
+		"unit: °C",
+		"range: 24h",
+		"> Tokyo",
+		"  Osaka",
+		"Tokyo, JP",
+		"Now: 20.0°C (feels like 19.0°C)",
+		"Weather: clear sky",
+		"Daily summary",
+		"Mon 07-01  20.0°C / 25.0°C  rain  80%  clear sky",
+		"Temperature (24h)",
+		"▁█",
+		"Last updated:",
+	}
+	for _, want := range expected {
+		if !strings.Contains(frame, want) {
+			t.Errorf("Expected frame to contain '%s', but it didn't.\nActual frame:\n%s", want, frame)
+		}
+	}
+
+	if rows := strings.Count(frame, "\n") + 1; rows != 40 {
+		t.Errorf("Expected frame to fill 40 rows, got %d", rows)
+	}
+}
+
+func TestDashboardKeys(t *testing.T) {
+	service := &mockWeatherService{}
+	dashboard, term := setupDashboard(t, service)
+	done := runDashboard(dashboard)
+
+	term.waitFor(t, "Tokyo, JP")
+	term.keys <- KeyDown
+	term.waitFor(t, "Osaka, JP")
+	term.keys <- KeyUnit
+	term.keys <- KeyRange
+	term.keys <- KeyQuit
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned an error: %v", err)
+	}
+
+	frame := term.lastFrame()
+	for _, want := range []string{"> Osaka", "Osaka, JP", "unit: °F", "range: 48h", "Now: 68.0°F"} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("Expected frame to contain '%s', but it didn't.\nActual frame:\n%s", want, frame)
+		}
+	}
+
+	if service.calls["Tokyo"] != 1 || service.calls["Osaka"] != 1 {
+		t.Errorf("Expected one fetch per visited location, got %v", service.calls)
+	}
+	if dashboard.cfg.TemperatureUnit != "C" {
+		t.Errorf("Toggling the unit should not change the saved configuration")
+	}
+}
+
+func TestDashboardKeepsDataOnRefreshError(t *testing.T) {
+	service := &mockWeatherService{}
+	dashboard, term := setupDashboard(t, service)
+	done := runDashboard(dashboard)
+
+	term.waitFor(t, "Tokyo, JP")
+	service.setErr(errors.New("temporary failure"))
+	term.keys <- KeyRefresh
+	term.waitFor(t, "Refresh failed: temporary failure")
+	if !strings.Contains(term.lastFrame(), "Tokyo, JP") {
+		t.Errorf("Expected the previous forecast to stay on screen.\nActual frame:\n%s", term.lastFrame())
+	}
+	term.keys <- KeyQuit
+	<-done
+}
+
+func TestDashboardHandlesKeysWhileFetching(t *testing.T) {
+	service := &blockingService{release: make(chan struct{})}
+	dashboard, term := setupDashboard(t, service)
+	done := runDashboard(dashboard)
+
+	term.waitFor(t, "Refreshing...")
+	term.keys <- KeyUnit
+	term.waitFor(t, "unit: °F")
+	term.keys <- KeyQuit
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("The dashboard didn't quit while a forecast was being fetched")
+	}
+	close(service.release)
+}
+
+func TestDashboardStopsReadingKeys(t *testing.T) {
+	dashboard, term := setupDashboard(t, &mockWeatherService{})
+	before := runtime.NumGoroutine()
+	done := runDashboard(dashboard)
+
+	term.waitFor(t, "Last updated:")
+	term.keys <- KeyQuit
+	<-done
+	// A key pressed after Run returned must not leave the key reader blocked
+	term.keys <- KeyDown
+	for deadline := time.Now().Add(2 * time.Second); runtime.NumGoroutine() > before; {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the dashboard's goroutines to exit, %d are left", runtime.NumGoroutine()-before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// blockingService doesn't answer until release is closed
+type blockingService struct {
+	release chan struct{}
+}
+
+func (b *blockingService) GetWeatherForecast(cfg *config.Config, location config.Location) (*weather.WeatherData, error) {
+	<-b.release
+	return nil, errors.New("released")
+}
+
+func TestDashboardNoLocations(t *testing.T) {
+	term := &fakeTerminal{width: 80, height: 24}
+	dashboard := NewDashboard(&config.Config{}, term, DefaultRefreshInterval)
+
+	if err := dashboard.Run(context.Background()); err == nil {
+		t.Error("Expected error when there are no saved locations, got nil")
+	}
+}
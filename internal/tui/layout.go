@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// fit pads or truncates s to exactly width runes
+func fit(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return string([]rune(s)[:width])
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// truncate cuts s to at most width runes
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width])
+}
+
+// columns places two blocks of lines side by side, separated by a vertical bar
+func columns(left, right []string, leftWidth int) []string {
+	rows := max(len(left), len(right))
+	lines := make([]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		lines = append(lines, fit(l, leftWidth)+" │ "+r)
+	}
+	return lines
+}
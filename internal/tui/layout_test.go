@@ -0,0 +1,23 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	if got := fit("abc", 5); got != "abc  " {
+		t.Errorf("fit pad = %q", got)
+	}
+	if got := fit("°C°C°C", 4); got != "°C°C" {
+		t.Errorf("fit truncate = %q", got)
+	}
+}
+
+func TestColumns(t *testing.T) {
+	got := columns([]string{"a", "b"}, []string{"1"}, 3)
+	want := []string{"a   │ 1", "b   │ "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("columns() = %q, want %q", got, want)
+	}
+}
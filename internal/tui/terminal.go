@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"io"
+	"time"
+)
+
+// escapeTimeout is how long a lone ESC waits for the rest of an escape sequence
+// before it counts as a key press of its own
+const escapeTimeout = 50 * time.Millisecond
+
+// Key represents a decoded key press
+type Key int
+
+const (
+	KeyUnknown Key = iota
+	KeyUp
+	KeyDown
+	KeyUnit
+	KeyRange
+	KeyRefresh
+	KeyQuit
+)
+
+// Terminal is the screen and keyboard the dashboard is drawn on
+type Terminal interface {
+	io.Writer
+	// Size returns the number of columns and rows available
+	Size() (width, height int)
+	// ReadKey blocks until a key is pressed
+	ReadKey() (Key, error)
+	// Close restores the terminal to its original state
+	Close() error
+}
+
+// decodeKey maps the bytes of a single key press to a Key
+func decodeKey(b []byte) Key {
+	switch string(b) {
+	case "\033[A", "\033OA", "k", "K":
+		return KeyUp
+	case "\033[B", "\033OB", "j", "J":
+		return KeyDown
+	case "u", "U":
+		return KeyUnit
+	case "t", "T":
+		return KeyRange
+	case "r", "R":
+		return KeyRefresh
+	case "q", "Q", "\033", "\x03":
+		return KeyQuit
+	}
+	return KeyUnknown
+}
+
+// keyReader splits terminal input into key presses. The bytes of an escape
+// sequence can arrive in separate reads, so an ESC waits for the bytes that
+// follow it and only stands for the ESC key when nothing comes within the timeout.
+type keyReader struct {
+	chunks  chan []byte
+	err     error
+	timeout time.Duration
+}
+
+// newKeyReader starts reading r in the background
+func newKeyReader(r io.Reader, timeout time.Duration) *keyReader {
+	k := &keyReader{chunks: make(chan []byte), timeout: timeout}
+	go func() {
+		for {
+			buf := make([]byte, 8)
+			n, err := r.Read(buf)
+			if n > 0 {
+				k.chunks <- buf[:n]
+			}
+			if err != nil {
+				// Set before closing chunks, so ReadKey sees it once the channel is drained
+				k.err = err
+				close(k.chunks)
+				return
+			}
+		}
+	}()
+	return k
+}
+
+// ReadKey blocks until a key is pressed
+func (k *keyReader) ReadKey() (Key, error) {
+	b, ok := <-k.chunks
+	if !ok {
+		return KeyUnknown, k.err
+	}
+	for incompleteEscape(b) {
+		timer := time.NewTimer(k.timeout)
+		select {
+		case more, ok := <-k.chunks:
+			timer.Stop()
+			if !ok {
+				return decodeKey(b), nil
+			}
+			b = append(b, more...)
+		case <-timer.C:
+			return decodeKey(b), nil
+		}
+	}
+	return decodeKey(b), nil
+}
+
+// incompleteEscape reports whether b is the start of an escape sequence whose
+// remaining bytes may still arrive
+func incompleteEscape(b []byte) bool {
+	switch string(b) {
+	case "\033", "\033[", "\033O":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package tui
+
+import "errors"
+
+// NewTerminal is not supported on this platform
+func NewTerminal() (Terminal, error) {
+	return nil, errors.New("the TUI dashboard is only supported on Unix terminals")
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDecodeKey(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Key
+	}{
+		{"\033[A", KeyUp},
+		{"k", KeyUp},
+		{"\033[B", KeyDown},
+		{"j", KeyDown},
+		{"u", KeyUnit},
+		{"t", KeyRange},
+		{"r", KeyRefresh},
+		{"q", KeyQuit},
+		{"\x03", KeyQuit},
+		{"x", KeyUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := decodeKey([]byte(tt.input)); got != tt.want {
+			t.Errorf("decodeKey(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestKeyReader(t *testing.T) {
+	r, w := io.Pipe()
+	keys := newKeyReader(r, 20*time.Millisecond)
+
+	// An arrow key split across reads is still an arrow key
+	go func() {
+		w.Write([]byte("\033"))
+		w.Write([]byte("[A"))
+	}()
+	if key, err := keys.ReadKey(); key != KeyUp || err != nil {
+		t.Errorf("ReadKey() = %v, %v, want KeyUp", key, err)
+	}
+
+	// A lone ESC quits once nothing follows it
+	go w.Write([]byte("\033"))
+	if key, err := keys.ReadKey(); key != KeyQuit || err != nil {
+		t.Errorf("ReadKey() = %v, %v, want KeyQuit", key, err)
+	}
+
+	go w.Write([]byte("q"))
+	if key, err := keys.ReadKey(); key != KeyQuit || err != nil {
+		t.Errorf("ReadKey() = %v, %v, want KeyQuit", key, err)
+	}
+
+	w.Close()
+	if _, err := keys.ReadKey(); err != io.EOF {
+		t.Errorf("ReadKey() error = %v, want io.EOF", err)
+	}
+}
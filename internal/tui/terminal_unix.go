@@ -0,0 +1,77 @@
+//go:build unix
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// ttyTerminal is a Terminal backed by the process's controlling terminal
+type ttyTerminal struct {
+	keys  *keyReader
+	out   *os.File
+	state string
+}
+
+// NewTerminal switches the current terminal to unbuffered input on the alternate screen
+func NewTerminal() (Terminal, error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("stdin is not a terminal: %w", err)
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return nil, fmt.Errorf("error configuring terminal: %w", err)
+	}
+
+	t := &ttyTerminal{keys: newKeyReader(os.Stdin, escapeTimeout), out: os.Stdout, state: state}
+	// Switch to the alternate screen and hide the cursor
+	fmt.Fprint(t.out, "\033[?1049h\033[?25l")
+	return t, nil
+}
+
+func (t *ttyTerminal) Write(p []byte) (int, error) {
+	return t.out.Write(p)
+}
+
+func (t *ttyTerminal) Size() (int, int) {
+	out, err := stty("size")
+	if err == nil {
+		var rows, cols int
+		if _, err := fmt.Sscanf(out, "%d %d", &rows, &cols); err == nil && rows > 0 && cols > 0 {
+			return cols, rows
+		}
+	}
+
+	cols, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	rows, _ := strconv.Atoi(os.Getenv("LINES"))
+	if cols <= 0 {
+		cols = 80
+	}
+	if rows <= 0 {
+		rows = 24
+	}
+	return cols, rows
+}
+
+func (t *ttyTerminal) ReadKey() (Key, error) {
+	return t.keys.ReadKey()
+}
+
+func (t *ttyTerminal) Close() error {
+	// Show the cursor again and leave the alternate screen
+	fmt.Fprint(t.out, "\033[?25h\033[?1049l")
+	_, err := stty(t.state)
+	return err
+}
+
+// stty runs stty against the terminal attached to stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
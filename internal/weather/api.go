@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 	"weather-cli/internal/config"
 )
@@ -80,18 +81,24 @@ type WeatherService interface {
 type RealWeatherService struct{}
 
 func (s *RealWeatherService) GetWeatherForecast(cfg *config.Config, location config.Location) (*WeatherData, error) {
+	if cfg.APIKey == "" {
+		return nil, errNoAPIKey
+	}
     client := &http.Client{Timeout: 10 * time.Second}
 
     url := fmt.Sprintf("%s?lat=%f&lon=%f&appid=%s&units=metric", BaseURL, location.Latitude, location.Longitude, cfg.APIKey)
+	if cfg.Language != "" {
+		url += "&lang=" + cfg.Language
+	}
 	
 	resp, err := client.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("error making request to OpenWeather API: %w", err)
+		return nil, RequestError("OpenWeather API", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("OpenWeather API returned non-OK status: %s", resp.Status)
+		return nil, ResponseError("OpenWeather API", resp)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -107,6 +114,21 @@ func (s *RealWeatherService) GetWeatherForecast(cfg *config.Config, location con
 	return &weatherData, nil
 }
 
+// ValidateAPIKey checks an API key with a minimal forecast request
+func ValidateAPIKey(apiKey string) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(fmt.Sprintf("%s?lat=0&lon=0&cnt=1&appid=%s", BaseURL, url.QueryEscape(apiKey)))
+	if err != nil {
+		return RequestError("OpenWeather API", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ResponseError("OpenWeather API", resp)
+	}
+	return nil
+}
+
 // デフォルトのサービスインスタンス
 var DefaultWeatherService WeatherService = &RealWeatherService{}
 
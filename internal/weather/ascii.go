@@ -86,3 +86,22 @@ func GetWeatherAscii(conditionID int) string {
  Sorry. This ASCII art is not ready yet.
 `
 }
+
+// GetWeatherIcon returns a single-character icon for a weather condition ID
+func GetWeatherIcon(conditionID int) string {
+	switch {
+	case conditionID >= 200 && conditionID < 300:
+		return "↯"
+	case conditionID >= 300 && conditionID < 600:
+		return "☂"
+	case conditionID >= 600 && conditionID < 700:
+		return "❄"
+	case conditionID >= 700 && conditionID < 800:
+		return "≡"
+	case conditionID == 800:
+		return "☀"
+	case conditionID > 800 && conditionID < 900:
+		return "☁"
+	}
+	return "?"
+}
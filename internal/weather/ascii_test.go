@@ -152,3 +152,25 @@ func TestGetWeatherAsciiEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWeatherIcon(t *testing.T) {
+	tests := []struct {
+		conditionID int
+		want        string
+	}{
+		{211, "↯"},
+		{301, "☂"},
+		{501, "☂"},
+		{601, "❄"},
+		{741, "≡"},
+		{800, "☀"},
+		{804, "☁"},
+		{999, "?"},
+	}
+
+	for _, tt := range tests {
+		if got := GetWeatherIcon(tt.conditionID); got != tt.want {
+			t.Errorf("GetWeatherIcon(%d) = %s, want %s", tt.conditionID, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+// DefaultCacheTTL is how long a cached forecast is reused before the API is queried again.
+// OpenWeather recommends not calling the API more than once every 10 minutes per location.
+var DefaultCacheTTL = 10 * time.Minute
+
+// cacheEntry is the on-disk representation of a cached forecast
+type cacheEntry struct {
+	FetchedAt time.Time    `json:"fetched_at"`
+	Data      *WeatherData `json:"data"`
+}
+
+// CachedWeatherService wraps another WeatherService and reuses recent responses.
+// Entries are kept in memory and, when Dir is set, on disk so that separate
+// invocations (e.g. a watch session and a one-off query) share the same data.
+type CachedWeatherService struct {
+	Service WeatherService
+	TTL     time.Duration
+	Dir     string
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// pending are the fetches in progress, which callers asking for the same
+	// forecast wait for instead of querying the API again
+	pending map[string]*pendingFetch
+	now     func() time.Time
+}
+
+// pendingFetch is a fetch from the wrapped service that is in progress
+type pendingFetch struct {
+	done chan struct{}
+	data *WeatherData
+	err  error
+}
+
+// NewCachedWeatherService creates a caching wrapper around service storing entries in dir
+func NewCachedWeatherService(service WeatherService, ttl time.Duration, dir string) *CachedWeatherService {
+	return &CachedWeatherService{
+		Service: service,
+		TTL:     ttl,
+		Dir:     dir,
+		entries: make(map[string]cacheEntry),
+		pending: make(map[string]*pendingFetch),
+		now:     time.Now,
+	}
+}
+
+// GetWeatherForecast returns a cached forecast when it is fresh, otherwise fetches a new one.
+// Concurrent calls for the same forecast share a single fetch.
+func (s *CachedWeatherService) GetWeatherForecast(cfg *config.Config, location config.Location) (*WeatherData, error) {
+	key := cacheKey(location, cfg.Language)
+
+	s.mu.Lock()
+	if entry, ok := s.lookup(key); ok && s.now().Sub(entry.FetchedAt) < s.TTL {
+		s.mu.Unlock()
+		return entry.Data, nil
+	}
+	if fetch, ok := s.pending[key]; ok {
+		s.mu.Unlock()
+		<-fetch.done
+		return fetch.data, fetch.err
+	}
+	// Waiters get errFetchPanicked unless the fetch returns
+	fetch := &pendingFetch{done: make(chan struct{}), err: errFetchPanicked}
+	s.pending[key] = fetch
+	s.mu.Unlock()
+
+	// Waiters are released even when the wrapped service panics
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, key)
+		if fetch.err == nil {
+			entry := cacheEntry{FetchedAt: s.now(), Data: fetch.data}
+			s.entries[key] = entry
+			s.store(key, entry)
+		}
+		s.mu.Unlock()
+		close(fetch.done)
+	}()
+
+	// The lock is not held while fetching so different locations can be fetched concurrently
+	fetch.data, fetch.err = s.Service.GetWeatherForecast(cfg, location)
+	return fetch.data, fetch.err
+}
+
+// errFetchPanicked is returned to the callers waiting for a fetch that panicked
+var errFetchPanicked = errors.New("fetching the forecast failed unexpectedly")
+
+// FetchedAt reports when the cached forecast for location was fetched from the wrapped service
+func (s *CachedWeatherService) FetchedAt(cfg *config.Config, location config.Location) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.lookup(cacheKey(location, cfg.Language))
+	return entry.FetchedAt, ok
+}
+
+// lookup finds an entry in memory, falling back to the cache directory. An entry
+// in memory that is no longer fresh is checked against the cache file, which
+// another run may have refreshed since.
+func (s *CachedWeatherService) lookup(key string) (cacheEntry, bool) {
+	entry, ok := s.entries[key]
+	if ok && s.now().Sub(entry.FetchedAt) < s.TTL {
+		return entry, true
+	}
+	if stored, found := s.load(key); found && (!ok || stored.FetchedAt.After(entry.FetchedAt)) {
+		s.entries[key] = stored
+		return stored, true
+	}
+	return entry, ok
+}
+
+// load reads an entry from the cache directory
+func (s *CachedWeatherService) load(key string) (cacheEntry, bool) {
+	if s.Dir == "" {
+		return cacheEntry{}, false
+	}
+
+	file, err := os.ReadFile(filepath.Join(s.Dir, key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(file, &entry); err != nil || entry.Data == nil {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store writes an entry to the cache directory; failures only cost a refetch so they are ignored
+func (s *CachedWeatherService) store(key string, entry cacheEntry) {
+	if s.Dir == "" {
+		return
+	}
+	if err := os.MkdirAll(s.Dir, 0750); err != nil {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	_ = os.WriteFile(filepath.Join(s.Dir, key), data, 0600)
+}
+
+// cacheKey builds the cache file name for a location and the language of its descriptions
+func cacheKey(location config.Location, language string) string {
+	if language != "" {
+		return fmt.Sprintf("forecast_%.4f_%.4f_%s.json", location.Latitude, location.Longitude, language)
+	}
+	return fmt.Sprintf("forecast_%.4f_%.4f.json", location.Latitude, location.Longitude)
+}
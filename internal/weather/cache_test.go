@@ -0,0 +1,173 @@
+package weather
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+// countingService is a WeatherService that counts how often it is called
+type countingService struct {
+	calls int
+	err   error
+}
+
+func (s *countingService) GetWeatherForecast(cfg *config.Config, location config.Location) (*WeatherData, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	data := createMockWeatherData()
+	return data, nil
+}
+
+func TestCachedWeatherService(t *testing.T) {
+	tempDir := t.TempDir()
+	backend := &countingService{}
+	service := NewCachedWeatherService(backend, time.Minute, tempDir)
+
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	cfg := &config.Config{}
+	tokyo := config.Location{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}
+
+	for i := 0; i < 3; i++ {
+		if _, err := service.GetWeatherForecast(cfg, tokyo); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if backend.calls != 1 {
+		t.Errorf("Expected 1 backend call within TTL, got %d", backend.calls)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, cacheKey(tokyo, ""))); err != nil {
+		t.Errorf("Expected cache file to be written: %v", err)
+	}
+
+	// A second service sharing the directory reuses the entry on disk
+	other := NewCachedWeatherService(backend, time.Minute, tempDir)
+	other.now = service.now
+	data, err := other.GetWeatherForecast(cfg, tokyo)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if backend.calls != 1 {
+		t.Errorf("Expected disk cache hit, got %d backend calls", backend.calls)
+	}
+	if data.City.Name != "Tokyo" {
+		t.Errorf("Expected cached city Tokyo, got %s", data.City.Name)
+	}
+
+	// Expired entries are refetched
+	now = now.Add(2 * time.Minute)
+	if _, err := service.GetWeatherForecast(cfg, tokyo); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if backend.calls != 2 {
+		t.Errorf("Expected refetch after TTL, got %d backend calls", backend.calls)
+	}
+
+	// The expired entry the second service holds in memory gives way to the one
+	// the first service just wrote to disk
+	if _, err := other.GetWeatherForecast(cfg, tokyo); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if backend.calls != 2 {
+		t.Errorf("Expected the refreshed cache file to be used, got %d backend calls", backend.calls)
+	}
+}
+
+func TestCachedWeatherServiceError(t *testing.T) {
+	backend := &countingService{err: errors.New("network down")}
+	service := NewCachedWeatherService(backend, time.Minute, "")
+
+	_, err := service.GetWeatherForecast(&config.Config{}, config.Location{Name: "Tokyo"})
+	if err == nil {
+		t.Fatal("Expected error from backend, got nil")
+	}
+	if len(service.entries) != 0 {
+		t.Errorf("Errors should not be cached")
+	}
+}
+
+// blockingService is a WeatherService that waits for release before answering
+type blockingService struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *blockingService) GetWeatherForecast(cfg *config.Config, location config.Location) (*WeatherData, error) {
+	s.calls.Add(1)
+	<-s.release
+	return createMockWeatherData(), nil
+}
+
+func TestCachedWeatherServiceSharesFetches(t *testing.T) {
+	backend := &blockingService{release: make(chan struct{})}
+	service := NewCachedWeatherService(backend, time.Minute, "")
+	tokyo := config.Location{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if data, err := service.GetWeatherForecast(&config.Config{}, tokyo); err != nil || data.City.Name != "Tokyo" {
+				t.Errorf("GetWeatherForecast() = %v, %v", data, err)
+			}
+		}()
+	}
+	// Let the callers pile up on the first fetch before it completes
+	for deadline := time.Now().Add(time.Second); backend.calls.Load() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(backend.release)
+	wg.Wait()
+
+	if calls := backend.calls.Load(); calls != 1 {
+		t.Errorf("Expected concurrent callers to share 1 backend call, got %d", calls)
+	}
+}
+
+// panickingService panics on its first call and succeeds after that
+type panickingService struct {
+	calls int
+}
+
+func (s *panickingService) GetWeatherForecast(cfg *config.Config, location config.Location) (*WeatherData, error) {
+	s.calls++
+	if s.calls == 1 {
+		panic("backend bug")
+	}
+	return createMockWeatherData(), nil
+}
+
+func TestCachedWeatherServicePanic(t *testing.T) {
+	service := NewCachedWeatherService(&panickingService{}, time.Minute, "")
+	tokyo := config.Location{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "backend bug" {
+				t.Errorf("recover() = %v, want the backend's panic", r)
+			}
+		}()
+		service.GetWeatherForecast(&config.Config{}, tokyo)
+	}()
+
+	// The panicked fetch is not left pending for the next caller to wait on
+	if len(service.pending) != 0 {
+		t.Errorf("pending = %v, want no fetches", service.pending)
+	}
+	if data, err := service.GetWeatherForecast(&config.Config{}, tokyo); err != nil || data.City.Name != "Tokyo" {
+		t.Errorf("GetWeatherForecast() after a panic = %v, %v", data, err)
+	}
+}
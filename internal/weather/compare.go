@@ -0,0 +1,162 @@
+package weather
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"weather-cli/internal/config"
+)
+
+// defaultCompareSlots is the number of time slots compared when no forecast interval is set
+const defaultCompareSlots = 8
+
+// compareColumnWidth is the width of each location column in the comparison table
+const compareColumnWidth = 20
+
+// LocationStats holds the aggregates used to rank locations over a comparison window
+type LocationStats struct {
+	Name          string
+	AvgTemp       float64
+	Precipitation float64
+	AvgPop        float64
+	MaxWind       float64
+}
+
+// Comparison is a set of forecasts aligned on common UTC time slots
+type Comparison struct {
+	Slots     []int64
+	Names     []string
+	Cells     []map[int64]int // per location, the index into its forecast List for each slot
+	Forecasts []*WeatherData
+	Stats     []LocationStats
+}
+
+// CompareForecasts aligns the forecasts of several locations on UTC time slots.
+// Only the first slots entries of the combined timeline are kept.
+func CompareForecasts(names []string, forecasts []*WeatherData, slots int) *Comparison {
+	seen := make(map[int64]bool)
+	var timeline []int64
+	for _, data := range forecasts {
+		for _, item := range data.List {
+			if !seen[item.Dt] {
+				seen[item.Dt] = true
+				timeline = append(timeline, item.Dt)
+			}
+		}
+	}
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i] < timeline[j] })
+	if len(timeline) > slots {
+		timeline = timeline[:slots]
+	}
+
+	c := &Comparison{Slots: timeline, Names: names, Forecasts: forecasts}
+	for i, data := range forecasts {
+		cells := make(map[int64]int)
+		stats := LocationStats{Name: names[i]}
+		count := 0
+		for j, item := range data.List {
+			if len(timeline) == 0 || item.Dt > timeline[len(timeline)-1] {
+				continue
+			}
+			cells[item.Dt] = j
+			count++
+			stats.AvgTemp += item.Main.Temp
+			stats.AvgPop += item.Pop
+			stats.Precipitation += item.Rain.ThreeH + item.Snow.ThreeH
+			stats.MaxWind = max(stats.MaxWind, item.Wind.Speed, item.Wind.Gust)
+		}
+		if count > 0 {
+			stats.AvgTemp /= float64(count)
+			stats.AvgPop /= float64(count)
+		}
+		c.Cells = append(c.Cells, cells)
+		c.Stats = append(c.Stats, stats)
+	}
+	return c
+}
+
+// Warmest returns the location with the highest average temperature
+func (c *Comparison) Warmest() LocationStats {
+	return c.best(func(a, b LocationStats) bool { return a.AvgTemp > b.AvgTemp })
+}
+
+// Wettest returns the location with the most precipitation, using the average
+// precipitation probability to break ties (e.g. when no rain is expected anywhere)
+func (c *Comparison) Wettest() LocationStats {
+	return c.best(func(a, b LocationStats) bool {
+		if a.Precipitation != b.Precipitation {
+			return a.Precipitation > b.Precipitation
+		}
+		return a.AvgPop > b.AvgPop
+	})
+}
+
+// Windiest returns the location with the strongest wind or gust
+func (c *Comparison) Windiest() LocationStats {
+	return c.best(func(a, b LocationStats) bool { return a.MaxWind > b.MaxWind })
+}
+
+// best returns the stats that rank first according to better
+func (c *Comparison) best(better func(a, b LocationStats) bool) LocationStats {
+	var result LocationStats
+	for i, stats := range c.Stats {
+		if i == 0 || better(stats, result) {
+			result = stats
+		}
+	}
+	return result
+}
+
+// DisplayComparison prints forecasts for several locations side by side
+func DisplayComparison(names []string, forecasts []*WeatherData, cfg *config.Config) {
+	slots := cfg.ForecastInterval
+	if slots <= 0 {
+		slots = defaultCompareSlots
+	}
+	c := CompareForecasts(names, forecasts, slots)
+
+	header := padRight("Time (UTC)", 17)
+	for _, name := range c.Names {
+		header += " | " + padRight(name, compareColumnWidth)
+	}
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("-", utf8.RuneCountInString(header)))
+
+	for _, slot := range c.Slots {
+		row := padRight(time.Unix(slot, 0).UTC().Format("2006-01-02 15:04"), 17)
+		for i, data := range c.Forecasts {
+			cell := "-"
+			if j, ok := c.Cells[i][slot]; ok {
+				item := data.List[j]
+				icon := "?"
+				if len(item.Weather) > 0 {
+					icon = GetWeatherIcon(item.Weather[0].ID)
+				}
+				temp := ConvertTemperature(item.Main.Temp, "C", cfg.TemperatureUnit)
+				cell = fmt.Sprintf("%6.1f°%s %s %3.0f%%", temp, cfg.TemperatureUnit, icon, item.Pop*100)
+			}
+			row += " | " + padRight(cell, compareColumnWidth)
+		}
+		fmt.Println(row)
+	}
+
+	if len(c.Stats) == 0 {
+		return
+	}
+	warmest, wettest, windiest := c.Warmest(), c.Wettest(), c.Windiest()
+	fmt.Println(strings.Repeat("-", utf8.RuneCountInString(header)))
+	fmt.Printf("Warmest:  %s (avg %.1f°%s)\n", warmest.Name, ConvertTemperature(warmest.AvgTemp, "C", cfg.TemperatureUnit), cfg.TemperatureUnit)
+	fmt.Printf("Wettest:  %s (%.1f mm, avg rain chance %.0f%%)\n", wettest.Name, wettest.Precipitation, wettest.AvgPop*100)
+	fmt.Printf("Windiest: %s (max %.1f m/s)\n", windiest.Name, windiest.MaxWind)
+}
+
+// padRight pads s with spaces to width runes
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+package weather
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"weather-cli/internal/config"
+)
+
+func comparisonFixtures(t *testing.T) []*WeatherData {
+	tokyo := parseWeatherData(t, `{
+		"city": {"name": "Tokyo"},
+		"list": [
+			{"dt": 1719792000, "main": {"temp": 28.0}, "pop": 0.2, "wind": {"speed": 3.0}, "weather": [{"id": 800}]},
+			{"dt": 1719802800, "main": {"temp": 30.0}, "pop": 0.4, "wind": {"speed": 4.0}, "weather": [{"id": 801}]}
+		]
+	}`)
+	berlin := parseWeatherData(t, `{
+		"city": {"name": "Berlin"},
+		"list": [
+			{"dt": 1719792000, "main": {"temp": 18.0}, "pop": 0.9, "rain": {"3h": 2.5}, "wind": {"speed": 6.0, "gust": 11.0}, "weather": [{"id": 501}]},
+			{"dt": 1719802800, "main": {"temp": 17.0}, "pop": 0.8, "rain": {"3h": 1.0}, "wind": {"speed": 5.0}, "weather": [{"id": 500}]},
+			{"dt": 1719813600, "main": {"temp": 16.0}, "pop": 0.1, "rain": {"3h": 9.0}, "wind": {"speed": 20.0}, "weather": [{"id": 500}]}
+		]
+	}`)
+	sf := parseWeatherData(t, `{
+		"city": {"name": "San Francisco"},
+		"list": [
+			{"dt": 1719802800, "main": {"temp": 15.0}, "pop": 0.0, "wind": {"speed": 8.0}, "weather": [{"id": 701}]}
+		]
+	}`)
+	return []*WeatherData{tokyo, berlin, sf}
+}
+
+func TestCompareForecasts(t *testing.T) {
+	c := CompareForecasts([]string{"tokyo", "berlin", "sf"}, comparisonFixtures(t), 2)
+
+	if len(c.Slots) != 2 || c.Slots[0] != 1719792000 || c.Slots[1] != 1719802800 {
+		t.Fatalf("Unexpected slots: %v", c.Slots)
+	}
+	if _, ok := c.Cells[2][1719792000]; ok {
+		t.Errorf("sf should have no entry for the first slot")
+	}
+
+	if got := c.Warmest(); got.Name != "tokyo" || got.AvgTemp != 29.0 {
+		t.Errorf("Warmest = %+v, want tokyo with avg 29.0", got)
+	}
+	// The third Berlin entry is outside the window and must not count
+	if got := c.Wettest(); got.Name != "berlin" || got.Precipitation != 3.5 {
+		t.Errorf("Wettest = %+v, want berlin with 3.5 mm", got)
+	}
+	if got := c.Windiest(); got.Name != "berlin" || got.MaxWind != 11.0 {
+		t.Errorf("Windiest = %+v, want berlin with 11.0 m/s", got)
+	}
+}
+
+func TestDisplayComparison(t *testing.T) {
+	cfg := &config.Config{TemperatureUnit: "C", ForecastInterval: 2}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	DisplayComparison([]string{"tokyo", "berlin", "sf"}, comparisonFixtures(t), cfg)
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	expected := []string{
+		"Time (UTC)        | tokyo                | berlin               | sf",
+		"2024-07-01 00:00  |   28.0°C ☀  20%      |   18.0°C ☂  90%      | -",
+		"2024-07-01 03:00  |   30.0°C ☁  40%      |   17.0°C ☂  80%      |   15.0°C ≡   0%",
+		"Warmest:  tokyo (avg 29.0°C)",
+		"Wettest:  berlin (3.5 mm, avg rain chance 85%)",
+		"Windiest: berlin (max 11.0 m/s)",
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain '%s', but it didn't.\nActual output:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "2024-07-01 06:00") {
+		t.Errorf("Output should be limited to the forecast interval:\n%s", output)
+	}
+}
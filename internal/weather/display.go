@@ -46,7 +46,17 @@ func DisplayWeather(data *WeatherData, cfg *config.Config) {
 func DisplayLocationList(locations []config.Location) {
 	fmt.Println("Saved Locations:")
 	for _, loc := range locations {
-		fmt.Printf("- %s (Lat: %.4f, Lon: %.4f)\n", loc.Name, loc.Latitude, loc.Longitude)
+		fmt.Printf("- %s (Lat: %.4f, Lon: %.4f)", loc.Name, loc.Latitude, loc.Longitude)
+		if len(loc.Aliases) > 0 {
+			fmt.Printf(" aliases: %s", strings.Join(loc.Aliases, ", "))
+		}
+		if len(loc.Tags) > 0 {
+			fmt.Printf(" tags: %s", strings.Join(loc.Tags, ", "))
+		}
+		if loc.Timezone != "" {
+			fmt.Printf(" timezone: %s", loc.Timezone)
+		}
+		fmt.Println()
 	}
 }
 
@@ -54,13 +64,58 @@ func DisplayLocationList(locations []config.Location) {
 func DisplayHelp() {
 	fmt.Println("Weather CLI Application Usage:")
 	fmt.Println("  weather <location>                   Get weather for a location")
+	fmt.Println("  weather                              Get weather for the default location")
+	fmt.Println("  weather --here                       Get weather for the current position")
+	fmt.Println("  weather <latitude> <longitude>       Get weather for coordinates and the nearest saved location")
+	fmt.Println("  weather loc default <name>           Set the default location")
+	fmt.Println("  weather <location> <location>...     Get weather for several locations")
+	fmt.Println("  weather --all                        Get weather for every saved location")
+	fmt.Println("  weather <location> --watch <interval> Redraw the forecast every interval (e.g. 10m)")
+	fmt.Println("  weather compare <location> <location>... Compare locations side by side")
+	fmt.Println("  weather tui                          Open the interactive dashboard")
 	fmt.Println("  weather -i <latitude> <longitude> <name>  Add a new location")
+	fmt.Println("  weather -i geo:<latitude>,<longitude> <name>  Add a new location from a geo: URI")
 	fmt.Println("  weather -r <name>                    Remove a location")
 	fmt.Println("  weather --unit <C|F>                 Set temperature unit")
 	fmt.Println("  weather --interval <hours>           Set forecast interval")
-	fmt.Println("  weather --list                       List saved locations")
-	fmt.Println("  weather --set-api-key <api_key>      Set the OpenWeather API key")
+	fmt.Println("  weather --list [--tag <tag>]         List saved locations")
+	fmt.Println("  weather --group <tag>                Get weather for every location with a tag")
+	fmt.Println("  weather loc alias|unalias <name> <alias>... Add or remove aliases")
+	fmt.Println("  weather loc rename <old> <new>       Rename a location")
+	fmt.Println("  weather loc edit <name> [--lat <latitude>] [--lon <longitude>] [--tz <timezone>] [--alias <alias,...>]")
+	fmt.Println("                                       Edit a location")
+	fmt.Println("  weather loc tag|untag <name> <tag>... Add or remove tags")
+	fmt.Println("  weather loc nearest <latitude> <longitude> [--limit <n>] List saved locations by distance")
+	fmt.Println("  weather loc distance <location> <location> Show the distance between two locations")
+	fmt.Println("  weather loc export [--format geojson|gpx|kml|csv] [--output <file>]")
+	fmt.Println("                                       Export saved locations")
+	fmt.Println("  weather loc import <file> [--format <format>] [--dry-run] [--replace] [--on-conflict skip|overwrite|rename]")
+	fmt.Println("                                       Import locations from a file")
+	fmt.Println("  weather init [--non-interactive] [--api-key <key>] [--unit <C|F>] [--lang <code>] [--location <place>]")
+	fmt.Println("                                       Set up the API key, units, language and a first location")
+	fmt.Println("  weather --set-api-key <api_key>      Set the OpenWeather API key (stored in plaintext)")
+	fmt.Println("  weather config api-key --keyring [<api_key>] | --cmd <command> | --file <path>")
+	fmt.Println("                                       Keep the API key in the keyring, a command or a file")
+	fmt.Println("  weather config show [--effective]    Show the config file or the resolved settings")
+	fmt.Println("  weather config doctor                Check the config file and report every problem")
+	fmt.Println("  weather config convert --to <json|yaml|toml> Rewrite the config file in another format")
+	fmt.Println("  weather config profile list | use <name> | create <name> [--base <profile>] | delete <name>")
+	fmt.Println("                                       Manage named profiles of settings and locations")
+	fmt.Println("  weather alerts check | list          Check the alert rules in the config file, or list them")
+	fmt.Println("  weather notify [<location>|--here] [--to <target>,...]")
+	fmt.Println("                                       Send the daily summary to stdout, desktop, a webhook or a notifier")
+	fmt.Println("  weather daemon [--log-format text|json] [--verbose]")
+	fmt.Println("                                       Run the scheduled jobs in the config file until stopped")
+	fmt.Println("  weather serve [--addr <host:port>] [--token <token>] [--timeout <duration>] [--log-format text|json]")
+	fmt.Println("                                       Serve forecasts and saved locations over HTTP")
 	fmt.Println("  weather --help                       Show this help message")
+	fmt.Println("")
+	fmt.Println("Any command accepts --config <path> to use another config file,")
+	fmt.Println("--profile <name> to use a profile and --override <key>=<value> to")
+	fmt.Println("change a setting for one run.")
+	fmt.Println("")
+	fmt.Println("Exit codes: 0 success, 1 error, 2 invalid arguments, 3 invalid configuration,")
+	fmt.Println("4 location not found, 5 API key rejected, 6 rate limited, 7 network error.")
 }
 
 // DisplayError formats and displays error messages
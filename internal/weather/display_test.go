@@ -199,6 +199,30 @@ func TestDisplayLocationList(t *testing.T) {
 	}
 }
 
+func TestDisplayLocationListWithLabels(t *testing.T) {
+	locations := []config.Location{
+		{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917, Aliases: []string{"hq", "tyo"}, Tags: []string{"offices"}},
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	DisplayLocationList(locations)
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	expected := "- Tokyo (Lat: 35.6895, Lon: 139.6917) aliases: hq, tyo tags: offices\n"
+	if !strings.Contains(output, expected) {
+		t.Errorf("Expected output to contain '%s', but it didn't.\nActual output:\n%s", expected, output)
+	}
+}
+
 func TestDisplayHelp(t *testing.T) {
 	old := os.Stdout
 	r, w, _ := os.Pipe()
@@ -0,0 +1,202 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned when a forecast cannot be fetched, matched with errors.Is
+var (
+	// ErrUnauthorized means the API key is missing, invalid or not yet active
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrRateLimited means too many requests were made with the API key
+	ErrRateLimited = errors.New("rate limited")
+	// ErrNetwork means the provider could not be reached
+	ErrNetwork = errors.New("network error")
+	// ErrNotFound means the provider knows no place by the requested name
+	ErrNotFound = errors.New("not found")
+)
+
+// classified gives an error the meaning of a sentinel error without changing its message
+type classified struct {
+	err  error
+	kind error
+}
+
+func (e *classified) Error() string {
+	return e.err.Error()
+}
+
+func (e *classified) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// errNoAPIKey is returned instead of sending a request that is bound to be rejected
+var errNoAPIKey = &classified{err: errors.New("no API key is configured"), kind: ErrUnauthorized}
+
+// RequestError describes a request to service that failed before a response
+// arrived. It matches ErrNetwork.
+func RequestError(service string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		// The request URL carries the API key, which must not end up in logs
+		err = &url.Error{Op: urlErr.Op, URL: redactURL(urlErr.URL), Err: urlErr.Err}
+	}
+	return fmt.Errorf("error making request to %s: %w", service, &classified{err: err, kind: ErrNetwork})
+}
+
+// redactURL hides the API key in the query of rawURL
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	if !query.Has("appid") {
+		return rawURL
+	}
+	query.Set("appid", "REDACTED")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Reason is why an API request was refused, told apart by status code and message
+type Reason int
+
+// Reasons an API request was refused
+const (
+	ReasonOther Reason = iota
+	// ReasonInvalidKey is an unknown API key, or one that is not active yet
+	ReasonInvalidKey
+	// ReasonQuotaExceeded is an API key that made more requests than its plan allows
+	ReasonQuotaExceeded
+	// ReasonNotFound is a city or place the provider does not know
+	ReasonNotFound
+)
+
+// RateLimit holds the rate-limit headers of a response. Limit and Remaining are
+// -1, and Reset and RetryAfter zero, when the header was not sent.
+type RateLimit struct {
+	Limit      int
+	Remaining  int
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+// APIError is a non-OK response from a weather or geocoding API, with the error
+// payload sent in its body, e.g. {"cod":401,"message":"Invalid API key..."}
+type APIError struct {
+	Service    string
+	StatusCode int
+	// Code is the provider's own error code, the "cod" field, when it sent one
+	Code      string
+	Message   string
+	RateLimit RateLimit
+}
+
+// Reason tells apart the refusals that need different fixes
+func (e *APIError) Reason() Reason {
+	message := strings.ToLower(e.Message)
+	switch {
+	case e.StatusCode == http.StatusUnauthorized && !strings.Contains(message, "subscription"):
+		return ReasonInvalidKey
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ReasonQuotaExceeded
+	case e.StatusCode == http.StatusNotFound && strings.Contains(message, "not found"):
+		return ReasonNotFound
+	}
+	return ReasonOther
+}
+
+func (e *APIError) Error() string {
+	detail := e.Message
+	if detail == "" {
+		detail = fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	switch e.Reason() {
+	case ReasonInvalidKey:
+		return fmt.Sprintf("%s rejected the API key (%s). New keys can take a couple of hours to become active", e.Service, detail)
+	case ReasonQuotaExceeded:
+		msg := fmt.Sprintf("%s request quota of the API key exceeded (%s)", e.Service, detail)
+		if limit := e.RateLimit; limit.RetryAfter > 0 {
+			msg += fmt.Sprintf("; retry in %s", limit.RetryAfter)
+		} else if !limit.Reset.IsZero() {
+			msg += fmt.Sprintf("; the quota resets at %s", limit.Reset.Local().Format("15:04:05"))
+		}
+		return msg
+	case ReasonNotFound:
+		return fmt.Sprintf("%s could not find the place (%s)", e.Service, detail)
+	}
+	if e.Message == "" {
+		return fmt.Sprintf("%s returned non-OK status: %s", e.Service, detail)
+	}
+	return fmt.Sprintf("%s returned non-OK status: %d %s: %s", e.Service, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is matches an APIError against ErrUnauthorized, ErrRateLimited and ErrNotFound
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrNotFound:
+		return e.Reason() == ReasonNotFound
+	}
+	return false
+}
+
+// maxErrorBody caps how much of an error response is read
+const maxErrorBody = 64 << 10
+
+// ResponseError reads the error payload of a non-OK response from service into
+// an *APIError. A body that is not JSON leaves Code and Message empty.
+func ResponseError(service string, resp *http.Response) error {
+	apiErr := &APIError{Service: service, StatusCode: resp.StatusCode, RateLimit: parseRateLimit(resp.Header)}
+
+	var payload struct {
+		Cod     json.RawMessage `json:"cod"`
+		Message string          `json:"message"`
+	}
+	if body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody)); err == nil && json.Unmarshal(body, &payload) == nil {
+		// OpenWeather sends the code as a number on some endpoints and as a string on others
+		apiErr.Code = strings.Trim(string(payload.Cod), `"`)
+		apiErr.Message = strings.TrimSpace(payload.Message)
+	}
+	return apiErr
+}
+
+// parseRateLimit reads the X-RateLimit-* and Retry-After headers
+func parseRateLimit(header http.Header) RateLimit {
+	limit := RateLimit{Limit: headerInt(header, "X-RateLimit-Limit"), Remaining: headerInt(header, "X-RateLimit-Remaining")}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil && reset > 0 {
+		limit.Reset = time.Unix(reset, 0)
+	}
+
+	// Retry-After is either a number of seconds or an HTTP date
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			limit.RetryAfter = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(value); err == nil && time.Until(at) > 0 {
+			limit.RetryAfter = time.Until(at).Round(time.Second)
+		}
+	}
+	return limit
+}
+
+// headerInt parses a header holding a count, returning -1 when it is missing or invalid
+func headerInt(header http.Header, name string) int {
+	n, err := strconv.Atoi(header.Get(name))
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,156 @@
+package weather
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+func TestGetWeatherForecastErrorKinds(t *testing.T) {
+	location := config.Location{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}
+
+	tests := []struct {
+		name   string
+		apiKey string
+		status int
+		want   error
+	}{
+		{"Missing API key", "", http.StatusOK, ErrUnauthorized},
+		{"Rejected API key", "test_api_key", http.StatusUnauthorized, ErrUnauthorized},
+		{"Rate limited", "test_api_key", http.StatusTooManyRequests, ErrRateLimited},
+		{"Server error", "test_api_key", http.StatusInternalServerError, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+			oldBase := BaseURL
+			BaseURL = server.URL
+			defer func() { BaseURL = oldBase }()
+
+			_, err := (&RealWeatherService{}).GetWeatherForecast(&config.Config{APIKey: tt.apiKey}, location)
+			if err == nil {
+				t.Fatal("GetWeatherForecast() should fail")
+			}
+			for _, kind := range []error{ErrUnauthorized, ErrRateLimited, ErrNetwork} {
+				if errors.Is(err, kind) != (kind == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, kind, !(kind == tt.want))
+				}
+			}
+		})
+	}
+}
+
+func TestGetWeatherForecastNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	oldBase := BaseURL
+	BaseURL = server.URL
+	defer func() { BaseURL = oldBase }()
+
+	location := config.Location{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}
+	_, err := (&RealWeatherService{}).GetWeatherForecast(&config.Config{APIKey: "test_api_key"}, location)
+	if !errors.Is(err, ErrNetwork) {
+		t.Errorf("GetWeatherForecast() error = %v, want ErrNetwork", err)
+	}
+	if strings.Contains(err.Error(), "test_api_key") || !strings.Contains(err.Error(), "appid=REDACTED") {
+		t.Errorf("GetWeatherForecast() error should hide the API key: %v", err)
+	}
+}
+
+func TestResponseErrorPayload(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		headers    map[string]string
+		body       string
+		wantReason Reason
+		wantCode   string
+		wantError  string
+	}{
+		{
+			name:       "Key not yet activated",
+			status:     http.StatusUnauthorized,
+			body:       `{"cod":401, "message": "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info."}`,
+			wantReason: ReasonInvalidKey,
+			wantCode:   "401",
+			wantError:  "OpenWeather API rejected the API key (Invalid API key. Please see https://openweathermap.org/faq#error401 for more info.). New keys can take a couple of hours to become active",
+		},
+		{
+			name:       "Quota exceeded",
+			status:     http.StatusTooManyRequests,
+			headers:    map[string]string{"Retry-After": "60", "X-RateLimit-Limit": "60", "X-RateLimit-Remaining": "0"},
+			body:       `{"cod":429, "message": "Your account is temporary blocked due to exceeding of requests limitation of your subscription type."}`,
+			wantReason: ReasonQuotaExceeded,
+			wantCode:   "429",
+			wantError:  "OpenWeather API request quota of the API key exceeded (Your account is temporary blocked due to exceeding of requests limitation of your subscription type.); retry in 1m0s",
+		},
+		{
+			name:       "City not found",
+			status:     http.StatusNotFound,
+			body:       `{"cod":"404","message":"city not found"}`,
+			wantReason: ReasonNotFound,
+			wantCode:   "404",
+			wantError:  "OpenWeather API could not find the place (city not found)",
+		},
+		{
+			name:       "Body that is not JSON",
+			status:     http.StatusBadGateway,
+			body:       `<html>Bad Gateway</html>`,
+			wantReason: ReasonOther,
+			wantError:  "OpenWeather API returned non-OK status: 502 Bad Gateway",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			oldBase := BaseURL
+			BaseURL = server.URL
+			defer func() { BaseURL = oldBase }()
+
+			location := config.Location{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}
+			_, err := (&RealWeatherService{}).GetWeatherForecast(&config.Config{APIKey: "test_api_key"}, location)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetWeatherForecast() error = %v, want an APIError", err)
+			}
+			if apiErr.Reason() != tt.wantReason || apiErr.Code != tt.wantCode || apiErr.StatusCode != tt.status {
+				t.Errorf("APIError = %+v, reason %d, want reason %d and code %q", apiErr, apiErr.Reason(), tt.wantReason, tt.wantCode)
+			}
+			if err.Error() != tt.wantError {
+				t.Errorf("Error() = %q\nwant %q", err.Error(), tt.wantError)
+			}
+		})
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "60")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", "1700000000")
+
+	limit := parseRateLimit(header)
+	if limit.Limit != 60 || limit.Remaining != 0 || !limit.Reset.Equal(time.Unix(1700000000, 0)) || limit.RetryAfter != 0 {
+		t.Errorf("parseRateLimit() = %+v", limit)
+	}
+	if limit := parseRateLimit(http.Header{}); limit.Limit != -1 || limit.Remaining != -1 || !limit.Reset.IsZero() {
+		t.Errorf("parseRateLimit() without headers = %+v", limit)
+	}
+}
@@ -0,0 +1,48 @@
+package weather
+
+import (
+	"sync"
+
+	"weather-cli/internal/config"
+)
+
+// DefaultFetchWorkers is the maximum number of forecasts fetched at the same time
+var DefaultFetchWorkers = 4
+
+// ForecastResult is the outcome of fetching the forecast for one location
+type ForecastResult struct {
+	Location config.Location
+	Data     *WeatherData
+	Err      error
+}
+
+// FetchForecasts fetches forecasts for several locations using at most workers concurrent
+// requests. Results are returned in the same order as locations, and a failure for one
+// location is reported in its result without affecting the others.
+func FetchForecasts(cfg *config.Config, locations []config.Location, workers int) []ForecastResult {
+	results := make([]ForecastResult, len(locations))
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(locations)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				data, err := GetWeatherForecast(cfg, locations[i])
+				results[i] = ForecastResult{Location: locations[i], Data: data, Err: err}
+			}
+		}()
+	}
+
+	for i := range locations {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,81 @@
+package weather
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+// concurrencyService tracks the peak number of concurrent requests
+type concurrencyService struct {
+	mu      sync.Mutex
+	active  int
+	peak    int
+	failFor string
+}
+
+func (s *concurrencyService) GetWeatherForecast(cfg *config.Config, location config.Location) (*WeatherData, error) {
+	s.mu.Lock()
+	s.active++
+	s.peak = max(s.peak, s.active)
+	s.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	s.mu.Lock()
+	s.active--
+	s.mu.Unlock()
+
+	if location.Name == s.failFor {
+		return nil, errors.New("city not found")
+	}
+	data := &WeatherData{}
+	data.City.Name = location.Name
+	return data, nil
+}
+
+func TestFetchForecasts(t *testing.T) {
+	service := &concurrencyService{failFor: "Osaka"}
+	originalService := DefaultWeatherService
+	DefaultWeatherService = service
+	defer func() { DefaultWeatherService = originalService }()
+
+	names := []string{"Tokyo", "Osaka", "London", "Paris", "Berlin", "Sydney"}
+	locations := make([]config.Location, len(names))
+	for i, name := range names {
+		locations[i] = config.Location{Name: name}
+	}
+
+	results := FetchForecasts(&config.Config{}, locations, 2)
+
+	if len(results) != len(names) {
+		t.Fatalf("Expected %d results, got %d", len(names), len(results))
+	}
+	for i, result := range results {
+		if result.Location.Name != names[i] {
+			t.Errorf("Result %d is for %s, want %s", i, result.Location.Name, names[i])
+		}
+		if names[i] == "Osaka" {
+			if result.Err == nil {
+				t.Errorf("Expected error for Osaka")
+			}
+			continue
+		}
+		if result.Err != nil || result.Data.City.Name != names[i] {
+			t.Errorf("Unexpected result for %s: %+v", names[i], result)
+		}
+	}
+
+	if service.peak > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, got %d", service.peak)
+	}
+}
+
+func TestFetchForecastsEmpty(t *testing.T) {
+	if results := FetchForecasts(&config.Config{}, nil, 4); len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
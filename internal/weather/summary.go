@@ -0,0 +1,63 @@
+package weather
+
+import (
+	"time"
+)
+
+// DailySummary aggregates the forecast entries that fall on the same local day
+type DailySummary struct {
+	Date        time.Time
+	TempMin     float64
+	TempMax     float64
+	MaxPop      float64
+	Rain        float64
+	Snow        float64
+	ConditionID int
+	Description string
+}
+
+// LocalTime converts a forecast timestamp to the local time of the forecast's city
+func LocalTime(data *WeatherData, dt int64) time.Time {
+	zone := time.FixedZone("", data.City.Timezone)
+	return time.Unix(dt, 0).In(zone)
+}
+
+// SummarizeDays groups forecast entries by local day, in chronological order.
+// The reported condition is the one that occurs most often during the day.
+func SummarizeDays(data *WeatherData) []DailySummary {
+	var summaries []DailySummary
+	var counts []map[int]int
+
+	for _, item := range data.List {
+		local := LocalTime(data, item.Dt)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+		if len(summaries) == 0 || !summaries[len(summaries)-1].Date.Equal(day) {
+			summaries = append(summaries, DailySummary{
+				Date:    day,
+				TempMin: item.Main.Temp,
+				TempMax: item.Main.Temp,
+			})
+			counts = append(counts, make(map[int]int))
+		}
+
+		summary := &summaries[len(summaries)-1]
+		summary.TempMin = min(summary.TempMin, item.Main.Temp)
+		summary.TempMax = max(summary.TempMax, item.Main.Temp)
+		summary.MaxPop = max(summary.MaxPop, item.Pop)
+		summary.Rain += item.Rain.ThreeH
+		summary.Snow += item.Snow.ThreeH
+
+		if len(item.Weather) > 0 {
+			condition := item.Weather[0]
+			dayCounts := counts[len(counts)-1]
+			dayCounts[condition.ID]++
+			if summary.Description == "" || dayCounts[condition.ID] > dayCounts[summary.ConditionID] {
+				summary.ConditionID = condition.ID
+				summary.Description = condition.Description
+			}
+		}
+	}
+
+	return summaries
+}
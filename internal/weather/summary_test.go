@@ -0,0 +1,65 @@
+package weather
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// parseWeatherData builds a WeatherData fixture from an API-shaped JSON document
+func parseWeatherData(t *testing.T, doc string) *WeatherData {
+	t.Helper()
+	var data WeatherData
+	if err := json.Unmarshal([]byte(doc), &data); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+	return &data
+}
+
+func TestSummarizeDays(t *testing.T) {
+	// Timestamps are 2024-07-01 00:00, 12:00, 21:00 and 2024-07-02 00:00 in UTC+9
+	data := parseWeatherData(t, `{
+		"city": {"name": "Tokyo", "country": "JP", "timezone": 32400},
+		"list": [
+			{"dt": 1719759600, "main": {"temp": 20.0}, "pop": 0.1, "weather": [{"id": 800, "description": "clear sky"}]},
+			{"dt": 1719802800, "main": {"temp": 28.5}, "pop": 0.6, "rain": {"3h": 1.5}, "weather": [{"id": 500, "description": "light rain"}]},
+			{"dt": 1719835200, "main": {"temp": 22.0}, "pop": 0.2, "rain": {"3h": 0.5}, "weather": [{"id": 500, "description": "light rain"}]},
+			{"dt": 1719846000, "main": {"temp": 19.0}, "pop": 0.0, "weather": [{"id": 801, "description": "few clouds"}]}
+		]
+	}`)
+
+	days := SummarizeDays(data)
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, got %d", len(days))
+	}
+
+	first := days[0]
+	if got := first.Date.Format("2006-01-02"); got != "2024-07-01" {
+		t.Errorf("Expected first day 2024-07-01, got %s", got)
+	}
+	if first.TempMin != 20.0 || first.TempMax != 28.5 {
+		t.Errorf("Expected min/max 20.0/28.5, got %.1f/%.1f", first.TempMin, first.TempMax)
+	}
+	if first.MaxPop != 0.6 {
+		t.Errorf("Expected max pop 0.6, got %.1f", first.MaxPop)
+	}
+	if first.Rain != 2.0 {
+		t.Errorf("Expected 2.0 mm of rain, got %.1f", first.Rain)
+	}
+	if first.ConditionID != 500 || first.Description != "light rain" {
+		t.Errorf("Expected most frequent condition light rain, got %d %s", first.ConditionID, first.Description)
+	}
+
+	second := days[1]
+	if got := second.Date.Format("2006-01-02"); got != "2024-07-02" {
+		t.Errorf("Expected second day 2024-07-02, got %s", got)
+	}
+	if second.Description != "few clouds" {
+		t.Errorf("Expected few clouds, got %s", second.Description)
+	}
+}
+
+func TestSummarizeDaysEmpty(t *testing.T) {
+	if days := SummarizeDays(&WeatherData{}); len(days) != 0 {
+		t.Errorf("Expected no days for empty forecast, got %d", len(days))
+	}
+}
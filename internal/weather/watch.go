@@ -0,0 +1,62 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+// clearScreen is the ANSI sequence that moves the cursor home and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// WatchWeather redraws the forecast for a location every interval until ctx is cancelled.
+// If a refresh fails, the previously fetched data stays on screen along with the error.
+func WatchWeather(ctx context.Context, cfg *config.Config, location config.Location, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive")
+	}
+
+	var (
+		current     *WeatherData
+		lastUpdated time.Time
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		data, err := GetWeatherForecast(cfg, location)
+		if err == nil {
+			current = data
+			lastUpdated = fetchedAt(cfg, location)
+		}
+
+		fmt.Print(clearScreen)
+		if current != nil {
+			DisplayWeather(current, cfg)
+			fmt.Printf("Last updated: %s (refreshing every %s, press Ctrl-C to stop)\n", lastUpdated.Format("2006-01-02 15:04:05"), interval)
+		}
+		if err != nil {
+			fmt.Printf("Refresh failed at %s: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// fetchedAt returns when the forecast for location was fetched, which is earlier
+// than now when the cache served it
+func fetchedAt(cfg *config.Config, location config.Location) time.Time {
+	if cached, ok := DefaultWeatherService.(*CachedWeatherService); ok {
+		if t, ok := cached.FetchedAt(cfg, location); ok {
+			return t
+		}
+	}
+	return time.Now()
+}
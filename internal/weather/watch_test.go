@@ -0,0 +1,115 @@
+package weather
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"weather-cli/internal/config"
+)
+
+// sequenceService returns the configured results in order, repeating the last one
+type sequenceService struct {
+	errs  []error
+	calls int
+}
+
+func (s *sequenceService) GetWeatherForecast(cfg *config.Config, location config.Location) (*WeatherData, error) {
+	err := s.errs[min(s.calls, len(s.errs)-1)]
+	s.calls++
+	if err != nil {
+		return nil, err
+	}
+	return createMockWeatherData(), nil
+}
+
+func TestWatchWeather(t *testing.T) {
+	service := &sequenceService{errs: []error{nil, errors.New("temporary failure")}}
+
+	originalService := DefaultWeatherService
+	DefaultWeatherService = service
+	defer func() { DefaultWeatherService = originalService }()
+
+	cfg := &config.Config{TemperatureUnit: "C", ForecastInterval: 1}
+	ctx, cancel := context.WithTimeout(context.Background(), 35*time.Millisecond)
+	defer cancel()
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := WatchWeather(ctx, cfg, config.Location{Name: "Tokyo"}, 10*time.Millisecond)
+
+	w.Close()
+	os.Stdout = old
+
+	if err != nil {
+		t.Fatalf("WatchWeather returned an error: %v", err)
+	}
+	if service.calls < 2 {
+		t.Fatalf("Expected at least 2 refreshes, got %d", service.calls)
+	}
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	frames := strings.Split(output, clearScreen)
+	last := frames[len(frames)-1]
+	for _, expected := range []string{"Tokyo, JP", "Temperature: 25.5°C", "Last updated:", "Refresh failed", "temporary failure"} {
+		if !strings.Contains(last, expected) {
+			t.Errorf("Expected last frame to contain '%s', but it didn't.\nActual frame:\n%s", expected, last)
+		}
+	}
+}
+
+func TestWatchWeatherShowsCacheTime(t *testing.T) {
+	fetched := time.Date(2024, 7, 1, 11, 55, 0, 0, time.Local)
+	service := NewCachedWeatherService(&countingService{}, 10*time.Minute, "")
+	service.now = func() time.Time { return fetched }
+
+	cfg := &config.Config{TemperatureUnit: "C"}
+	tokyo := config.Location{Name: "Tokyo", Latitude: 35.6895, Longitude: 139.6917}
+	if _, err := service.GetWeatherForecast(cfg, tokyo); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	service.now = func() time.Time { return fetched.Add(5 * time.Minute) }
+
+	originalService := DefaultWeatherService
+	DefaultWeatherService = service
+	defer func() { DefaultWeatherService = originalService }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	err := WatchWeather(ctx, cfg, tokyo, time.Minute)
+
+	w.Close()
+	os.Stdout = old
+
+	if err != nil {
+		t.Fatalf("WatchWeather returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	// The forecast served from the cache is as old as the cache entry
+	if !strings.Contains(buf.String(), "Last updated: 2024-07-01 11:55:00") {
+		t.Errorf("Expected the cache entry's fetch time, got:\n%s", buf.String())
+	}
+}
+
+func TestWatchWeatherInvalidInterval(t *testing.T) {
+	err := WatchWeather(context.Background(), &config.Config{}, config.Location{}, 0)
+	if err == nil {
+		t.Error("Expected error for non-positive interval, got nil")
+	}
+}
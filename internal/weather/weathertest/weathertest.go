@@ -0,0 +1,90 @@
+// Package weathertest provides a fake OpenWeather API for tests of the packages
+// that fetch forecasts
+package weathertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"weather-cli/internal/weather"
+)
+
+// Entry is one entry of the served forecast, At from when the provider started
+type Entry struct {
+	At          time.Duration
+	Temp        float64
+	Wind, Gust  float64
+	ID          int
+	Description string
+}
+
+// Gusty is a clear hour an hour from now with strong gusts
+var Gusty = []Entry{{At: time.Hour, Temp: 12.0, Wind: 9.0, Gust: 18.0, ID: 800, Description: "clear sky"}}
+
+// Provider answers every forecast request with Entries for Tokyo
+type Provider struct {
+	Entries []Entry
+	// Delay holds back each response
+	Delay time.Duration
+	// RejectLatitude answers requests for this latitude, as sent in the query,
+	// with an invalid API key error
+	RejectLatitude string
+
+	calls atomic.Int32
+}
+
+// Serve starts a provider serving Gusty through a weather.RealWeatherService
+func Serve(t testing.TB) *Provider {
+	p := &Provider{Entries: Gusty}
+	p.Start(t, &weather.RealWeatherService{})
+	return p
+}
+
+// Start serves p until the test ends, with weather.BaseURL pointing to it and
+// service as weather.DefaultWeatherService
+func (p *Provider) Start(t testing.TB, service weather.WeatherService) {
+	now := time.Now()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.calls.Add(1)
+		time.Sleep(p.Delay)
+		if p.RejectLatitude != "" && r.URL.Query().Get("lat") == p.RejectLatitude {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"cod": 401, "message": "Invalid API key"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(p.forecast(now))
+	}))
+	t.Cleanup(server.Close)
+
+	oldBase, oldService := weather.BaseURL, weather.DefaultWeatherService
+	weather.BaseURL, weather.DefaultWeatherService = server.URL, service
+	t.Cleanup(func() { weather.BaseURL, weather.DefaultWeatherService = oldBase, oldService })
+}
+
+// Calls returns how many requests p has received
+func (p *Provider) Calls() int {
+	return int(p.calls.Load())
+}
+
+// forecast builds the response body for Entries
+func (p *Provider) forecast(now time.Time) map[string]any {
+	list := make([]map[string]any, len(p.Entries))
+	for i, e := range p.Entries {
+		list[i] = map[string]any{
+			"dt":      now.Add(e.At).Unix(),
+			"main":    map[string]float64{"temp": e.Temp},
+			"wind":    map[string]float64{"speed": e.Wind, "gust": e.Gust},
+			"weather": []map[string]any{{"id": e.ID, "description": e.Description}},
+		}
+	}
+	return map[string]any{
+		"cod":  "200",
+		"cnt":  len(list),
+		"city": map[string]any{"name": "Tokyo", "timezone": 32400},
+		"list": list,
+	}
+}